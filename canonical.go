@@ -0,0 +1,110 @@
+package g53
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//rfc4034 section 6.2 lists the types whose embedded domain names are
+//downcased in canonical form, rfc6840 section 5.1 removes nsec from it.
+//names in other types keep their case
+func canonicalRdata(rdata Rdata) Rdata {
+	switch r := rdata.(type) {
+	case *NS:
+		return &NS{downcasedName(r.Name)}
+	case *CName:
+		return &CName{downcasedName(r.Name)}
+	case *PTR:
+		return &PTR{downcasedName(r.Name)}
+	case *DName:
+		return &DName{downcasedName(r.Target)}
+	case *SOA:
+		soa := *r
+		soa.MName = downcasedName(r.MName)
+		soa.RName = downcasedName(r.RName)
+		return &soa
+	case *MX:
+		return &MX{r.Preference, downcasedName(r.Exchange)}
+	case *MInfo:
+		return &MInfo{downcasedName(r.RMailbox), downcasedName(r.EMailbox)}
+	case *RP:
+		return &RP{downcasedName(r.Mbox), downcasedName(r.Txt)}
+	case *AFSDB:
+		return &AFSDB{r.Subtype, downcasedName(r.Hostname)}
+	case *RT:
+		return &RT{r.Preference, downcasedName(r.Host)}
+	case *PX:
+		return &PX{r.Preference, downcasedName(r.Map822), downcasedName(r.MapX400)}
+	case *KX:
+		return &KX{r.Preference, downcasedName(r.Exchanger)}
+	case *SRV:
+		srv := *r
+		srv.Target = downcasedName(r.Target)
+		return &srv
+	case *NAPTR:
+		naptr := *r
+		naptr.Replacement = downcasedName(r.Replacement)
+		return &naptr
+	case *RRSig:
+		rrsig := *r
+		rrsig.Signer = downcasedName(r.Signer)
+		return &rrsig
+	default:
+		return rdata
+	}
+}
+
+func downcasedName(name *Name) *Name {
+	n := name.Clone()
+	n.Downcase()
+	return &n
+}
+
+//rdata in canonical form is its uncompressed wire format with the
+//embedded names downcased for the types listed above
+func CanonicalRdataToWire(rdata Rdata) []byte {
+	buf := util.NewOutputBuffer(128)
+	canonicalRdata(rdata).ToWire(buf)
+	return buf.Data()
+}
+
+//compare rdata as left justified unsigned octet sequence of their
+//canonical form, rfc4034 section 6.3, which may differ from the order
+//of Rdata.Compare
+func CanonicalRdataCompare(r1, r2 Rdata) int {
+	return bytes.Compare(CanonicalRdataToWire(r1), CanonicalRdataToWire(r2))
+}
+
+//rdatas in canonical form sorted as left justified unsigned octet
+//sequence, duplicate rdata is removed
+func canonicalRdatas(rdatas []Rdata) [][]byte {
+	wires := make([][]byte, 0, len(rdatas))
+	for _, rdata := range rdatas {
+		wires = append(wires, CanonicalRdataToWire(rdata))
+	}
+	sort.Slice(wires, func(i, j int) bool { return bytes.Compare(wires[i], wires[j]) < 0 })
+
+	uniq := wires[:0]
+	for i, wire := range wires {
+		if i == 0 || bytes.Equal(wire, wires[i-1]) == false {
+			uniq = append(uniq, wire)
+		}
+	}
+	return uniq
+}
+
+//owner name and ttl are passed in since dnssec uses the original ttl
+//and the owner name before wildcard expansion
+func canonicalRRsetToWire(owner *Name, typ RRType, cls RRClass, ttl RRTTL, rdatas []Rdata, buf *util.OutputBuffer) {
+	name := downcasedName(owner)
+	for _, rdata := range canonicalRdatas(rdatas) {
+		name.ToWire(buf)
+		typ.ToWire(buf)
+		cls.ToWire(buf)
+		ttl.ToWire(buf)
+		buf.WriteUint16(uint16(len(rdata)))
+		buf.WriteData(rdata)
+	}
+}
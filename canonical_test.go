@@ -0,0 +1,110 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func nameKeepCase(s string) *Name {
+	name, _ := NewName(s, false)
+	return name
+}
+
+//example in rfc4034 section 6.1
+func TestSortNames(t *testing.T) {
+	sorted := []string{
+		"example.",
+		"a.example.",
+		"yljkjljk.a.example.",
+		"Z.a.example.",
+		"zABC.a.EXAMPLE.",
+		"z.example.",
+		"\\001.z.example.",
+		"*.z.example.",
+		"\\200.z.example.",
+	}
+
+	var names []*Name
+	for i := len(sorted) - 1; i >= 0; i-- {
+		names = append(names, NameFromStringUnsafe(sorted[i]))
+	}
+	names[3], names[7] = names[7], names[3]
+	SortNames(names)
+	for i, name := range names {
+		Assert(t, name.Equals(NameFromStringUnsafe(sorted[i])), "%d name should be %s but get %s", i, sorted[i], name.String(false))
+	}
+}
+
+func TestCanonicalRdata(t *testing.T) {
+	n := nameKeepCase
+	txt, _ := RdataFromString(RR_TXT, "\"v=spf1\" \"-all\"")
+	for _, c := range []struct {
+		rdata Rdata
+		wire  string
+	}{
+		{&MX{10, n("Mail.EXAMPLE.com.")}, "000a" + "046d61696c076578616d706c6503636f6d00"},
+		{&SOA{n("NS1.Example."), n("Admin.Example."), 1, 2, 3, 4, 5}, "036e7331076578616d706c6500" + "0561646d696e076578616d706c6500" + "0000000100000002000000030000000400000005"},
+		{&CName{n("WWW.example.")}, "03777777076578616d706c6500"},
+		{&RRSig{RR_A, 13, 2, 300, 2, 1, 12345, n("EXAMPLE."), nil}, "0001" + "0d02" + "0000012c" + "00000002" + "00000001" + "3039" + "076578616d706c6500"},
+		//rfc6840 section 5.1, names in nsec aren't downcased
+		{&NSEC{n("Host.example."), []RRType{RR_A}}, "04486f7374076578616d706c6500" + "000140"},
+		{txt, "06763d73706631" + "042d616c6c"},
+	} {
+		wire, _ := util.HexStrToBytes(c.wire)
+		WireMatch(t, wire, CanonicalRdataToWire(c.rdata))
+	}
+
+	//canonical form doesn't modify the original rdata
+	mx := &MX{10, n("Mail.EXAMPLE.com.")}
+	CanonicalRdataToWire(mx)
+	Equal(t, mx.String(), "10 Mail.EXAMPLE.com.")
+
+	//shorter txt is less in canonical order since length is compared first
+	txt1, _ := RdataFromString(RR_TXT, "\"b\"")
+	txt2, _ := RdataFromString(RR_TXT, "\"aa\"")
+	Assert(t, CanonicalRdataCompare(txt1, txt2) < 0, "canonical order is octet sequence order")
+	Equal(t, CanonicalRdataCompare(txt1, txt1), 0)
+
+	Equal(t, CanonicalRdataCompare(&NS{n("NS.example.")}, &NS{n("ns.example.")}), 0)
+	Assert(t, CanonicalRdataCompare(&NSEC{n("A.example."), nil}, &NSEC{n("a.example."), nil}) < 0, "nsec name keeps case")
+}
+
+func TestRRsetCanonical(t *testing.T) {
+	aa, _ := RdataFromString(RR_TXT, "\"aa\"")
+	b, _ := RdataFromString(RR_TXT, "\"b\"")
+	rrset := &RRset{
+		Name:   *nameKeepCase("WWW.Example."),
+		Type:   RR_TXT,
+		Class:  CLASS_IN,
+		Ttl:    300,
+		Rdatas: []Rdata{aa, b, aa},
+	}
+
+	rrset.SortRdataCanonical()
+	Equal(t, rrset.Rdatas[0].String(), "\"b\"")
+	Equal(t, rrset.Rdatas[1].String(), "\"aa\"")
+	Equal(t, rrset.Rdatas[2].String(), "\"aa\"")
+
+	buf := util.NewOutputBuffer(128)
+	rrset.CanonicalToWire(buf)
+	owner := "03777777076578616d706c6500" + "0010" + "0001" + "0000012c"
+	wire, _ := util.HexStrToBytes(owner + "0002" + "0162" + owner + "0003" + "026161")
+	WireMatch(t, wire, buf.Data())
+
+	var rrsets []*RRset
+	for _, s := range []string{
+		"b.example. 300 IN A 192.0.2.1",
+		"example. 300 IN NS ns.example.",
+		"A.example. 300 IN AAAA 2001:db8::1",
+		"example. 300 IN SOA ns.example. admin.example. 1 2 3 4 5",
+		"a.example. 300 IN A 192.0.2.2",
+	} {
+		rrset, _ := RRsetFromString(s)
+		rrsets = append(rrsets, rrset)
+	}
+	SortRRsets(rrsets)
+	for i, expect := range []string{"example. NS", "example. SOA", "a.example. A", "a.example. AAAA", "b.example. A"} {
+		Equal(t, rrsets[i].Name.String(false)+" "+rrsets[i].Type.String(), expect)
+	}
+}
@@ -0,0 +1,237 @@
+package g53
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("dnssec algorithm isn't supported")
+	ErrInvalidDNSKey        = errors.New("dnskey public key isn't valid")
+	ErrRRSigRRsetMismatch   = errors.New("rrsig doesn't cover the rrset")
+	ErrRRSigKeyMismatch     = errors.New("rrsig isn't generated by the dnskey")
+	ErrRRSigNotIncepted     = errors.New("rrsig isn't valid yet")
+	ErrRRSigExpired         = errors.New("rrsig has expired")
+	ErrInvalidSignature     = errors.New("signature verification failed")
+)
+
+//verify rrsig with current time, see VerifyRRSigAt
+func VerifyRRSig(rrset *RRset, rrsig *RRSig, dnskey *DNSKey) error {
+	return VerifyRRSigAt(rrset, rrsig, dnskey, time.Now())
+}
+
+//verify rrsig as rfc4035 section 5.3, the owner name of dnskey should
+//be the signer of the rrsig which is checked by caller
+func VerifyRRSigAt(rrset *RRset, rrsig *RRSig, dnskey *DNSKey, now time.Time) error {
+	if rrsig.Covered != rrset.Type || isInZone(&rrset.Name, rrsig.Signer) == false {
+		return ErrRRSigRRsetMismatch
+	}
+
+	if dnskey.Protocol != DNSKEY_PROTOCOL || dnskey.IsZoneKey() == false ||
+		dnskey.Algorithm != rrsig.Algorithm || dnskey.KeyTag() != rrsig.Tag {
+		return ErrRRSigKeyMismatch
+	}
+
+	if err := checkRRSigValidity(rrsig, now); err != nil {
+		return err
+	}
+
+	data, err := rrsigSignedData(rrset, rrsig)
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := dnskey.CryptoPublicKey()
+	if err != nil {
+		return err
+	}
+	return verifySignature(pubKey, rrsig.Algorithm, data, rrsig.Signature)
+}
+
+//inception and expiration use serial number arithmetic in rfc1982
+func checkRRSigValidity(rrsig *RRSig, now time.Time) error {
+	t := uint32(now.Unix())
+	if CompareSerial(t, rrsig.Inception) < 0 {
+		return ErrRRSigNotIncepted
+	} else if CompareSerial(t, rrsig.SigExpire) > 0 {
+		return ErrRRSigExpired
+	}
+	return nil
+}
+
+//signed data is rrsig rdata without signature followed by the rrset in
+//canonical form and order with original ttl, rfc4034 section 3.1.8.1
+func rrsigSignedData(rrset *RRset, rrsig *RRSig) ([]byte, error) {
+	owner, err := rrsigOwner(&rrset.Name, rrsig.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	header := *rrsig
+	header.Signature = nil
+	buf := util.NewOutputBuffer(512)
+	canonicalRdata(&header).ToWire(buf)
+	canonicalRRsetToWire(owner, rrset.Type, rrset.Class, RRTTL(rrsig.OriginalTtl), rrset.Rdatas, buf)
+	return buf.Data(), nil
+}
+
+//rrset synthesized from wildcard is signed with the wildcard owner name,
+//rfc4035 section 5.3.2
+func rrsigOwner(name *Name, labels uint8) (*Name, error) {
+	count := name.LabelCount() - 1
+	if uint(labels) > count {
+		return nil, ErrRRSigRRsetMismatch
+	} else if uint(labels) == count {
+		return name, nil
+	}
+
+	suffix, err := name.StripLeft(count - uint(labels))
+	if err != nil {
+		return nil, err
+	}
+	return NameFromStringUnsafe("*").Concat(suffix)
+}
+
+//convert the public key in dnskey to rsa, ecdsa or ed25519 public key
+func (k *DNSKey) CryptoPublicKey() (crypto.PublicKey, error) {
+	switch k.Algorithm {
+	case ALGORITHM_RSASHA1, ALGORITHM_RSASHA1_NSEC3_SHA1, ALGORITHM_RSASHA256, ALGORITHM_RSASHA512:
+		return rsaPublicKeyFromWire(k.PublicKey)
+	case ALGORITHM_ECDSAP256SHA256:
+		return ecdsaPublicKeyFromWire(elliptic.P256(), k.PublicKey)
+	case ALGORITHM_ECDSAP384SHA384:
+		return ecdsaPublicKeyFromWire(elliptic.P384(), k.PublicKey)
+	case ALGORITHM_ED25519:
+		if len(k.PublicKey) != ed25519.PublicKeySize {
+			return nil, ErrInvalidDNSKey
+		}
+		return ed25519.PublicKey(append([]byte(nil), k.PublicKey...)), nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+//exponent length, exponent and modulus defined in rfc3110 section 2
+func rsaPublicKeyFromWire(data []byte) (*rsa.PublicKey, error) {
+	if len(data) < 1 {
+		return nil, ErrInvalidDNSKey
+	}
+
+	expLen := int(data[0])
+	data = data[1:]
+	if expLen == 0 {
+		if len(data) < 2 {
+			return nil, ErrInvalidDNSKey
+		}
+		expLen = int(data[0])<<8 | int(data[1])
+		data = data[2:]
+	}
+
+	//go rsa only support exponent fit in 32 bits
+	if expLen == 0 || expLen > 4 || len(data) <= expLen || data[expLen] == 0 {
+		return nil, ErrInvalidDNSKey
+	}
+
+	e := 0
+	for _, b := range data[:expLen] {
+		e = e<<8 | int(b)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(data[expLen:]),
+		E: e,
+	}, nil
+}
+
+//public key is x and y coordinates, rfc6605 section 4
+func ecdsaPublicKeyFromWire(curve elliptic.Curve, data []byte) (*ecdsa.PublicKey, error) {
+	size := (curve.Params().BitSize + 7) / 8
+	if len(data) != 2*size {
+		return nil, ErrInvalidDNSKey
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(data[:size]),
+		Y:     new(big.Int).SetBytes(data[size:]),
+	}, nil
+}
+
+func algorithmHash(algorithm uint8) (crypto.Hash, error) {
+	switch algorithm {
+	case ALGORITHM_RSASHA1, ALGORITHM_RSASHA1_NSEC3_SHA1:
+		return crypto.SHA1, nil
+	case ALGORITHM_RSASHA256, ALGORITHM_ECDSAP256SHA256:
+		return crypto.SHA256, nil
+	case ALGORITHM_RSASHA512:
+		return crypto.SHA512, nil
+	case ALGORITHM_ECDSAP384SHA384:
+		return crypto.SHA384, nil
+	case ALGORITHM_ED25519:
+		return crypto.Hash(0), nil
+	default:
+		return 0, ErrUnsupportedAlgorithm
+	}
+}
+
+func digestData(h crypto.Hash, data []byte) []byte {
+	switch h {
+	case crypto.SHA1:
+		digest := sha1.Sum(data)
+		return digest[:]
+	case crypto.SHA256:
+		digest := sha256.Sum256(data)
+		return digest[:]
+	case crypto.SHA384:
+		digest := sha512.Sum384(data)
+		return digest[:]
+	case crypto.SHA512:
+		digest := sha512.Sum512(data)
+		return digest[:]
+	default:
+		return data
+	}
+}
+
+func verifySignature(pubKey crypto.PublicKey, algorithm uint8, data, signature []byte) error {
+	h, err := algorithmHash(algorithm)
+	if err != nil {
+		return err
+	}
+
+	switch key := pubKey.(type) {
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, h, digestData(h, data), signature) != nil {
+			return ErrInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		//signature is r and s with same length as the coordinates
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrInvalidSignature
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if ecdsa.Verify(key, digestData(h, data), r, s) == false {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, data, signature) == false {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+	return nil
+}
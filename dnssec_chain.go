@@ -0,0 +1,435 @@
+package g53
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNameOutOfZone      = errors.New("name isn't in the zone")
+	ErrChainNameNotExists = errors.New("name doesn't exist in the chain")
+)
+
+//names and rr types of a zone used to build nsec or nsec3 chain
+type OwnerTypes struct {
+	Name  *Name
+	Types []RRType
+}
+
+//parameters of nsec3 chain, with opt-out unsigned delegations and the
+//empty non-terminals only lead to them are excluded from the chain
+type NSEC3Config struct {
+	Iterations uint16
+	Salt       []byte
+	OptOut     bool
+}
+
+//rrsets to delete and add after the chain is updated, each changed nsec
+//or nsec3 rrset is deleted with its old rdata and added with new one
+type ChainDiff struct {
+	Deleted []*RRset
+	Added   []*RRset
+}
+
+type chainEntry struct {
+	name  *Name
+	owner *Name
+	hash  []byte
+	types []RRType
+}
+
+//nsec chain defined in rfc4034 or nsec3 chain defined in rfc5155 of a
+//zone, owner names below zone cut or dname are occluded and excluded.
+//the chain isn't safe for concurrent update
+type DenialChain struct {
+	origin  *Name
+	class   RRClass
+	ttl     RRTTL
+	nsec3   *NSEC3Config
+	names   []*Name
+	types   map[string][]RRType
+	entries []*chainEntry
+}
+
+//ttl should be the minimum field of soa, rfc4034 section 4
+func NewNSECChain(origin *Name, cls RRClass, ttl RRTTL, owners []OwnerTypes) (*DenialChain, error) {
+	return newDenialChain(origin, cls, ttl, nil, owners)
+}
+
+func NewNSEC3Chain(origin *Name, cls RRClass, ttl RRTTL, conf NSEC3Config, owners []OwnerTypes) (*DenialChain, error) {
+	conf.Salt = append([]byte(nil), conf.Salt...)
+	return newDenialChain(origin, cls, ttl, &conf, owners)
+}
+
+func newDenialChain(origin *Name, cls RRClass, ttl RRTTL, conf *NSEC3Config, owners []OwnerTypes) (*DenialChain, error) {
+	c := &DenialChain{
+		origin: origin,
+		class:  cls,
+		ttl:    ttl,
+		nsec3:  conf,
+		types:  make(map[string][]RRType),
+	}
+
+	for _, owner := range owners {
+		if isInZone(owner.Name, origin) == false {
+			return nil, ErrNameOutOfZone
+		}
+
+		key := nameKey(owner.Name)
+		if _, ok := c.types[key]; ok == false {
+			c.names = append(c.names, owner.Name)
+		}
+		c.types[key] = mergeTypes(c.types[key], owner.Types)
+	}
+	SortNames(c.names)
+
+	candidates := make(map[string]*Name)
+	for _, name := range c.names {
+		c.addWithAncestors(candidates, name)
+	}
+	for _, name := range candidates {
+		if entry := c.entryFor(name); entry != nil {
+			c.entries = append(c.entries, entry)
+		}
+	}
+	sort.Slice(c.entries, func(i, j int) bool {
+		return c.less(c.entries[i], c.entries[j])
+	})
+	return c, nil
+}
+
+func nameKey(name *Name) string {
+	return string(downcasedName(name).raw)
+}
+
+func mergeTypes(types []RRType, added []RRType) []RRType {
+	for _, t := range added {
+		if hasType(types, t) == false {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (c *DenialChain) IsNSEC3() bool {
+	return c.nsec3 != nil
+}
+
+//all the nsec or nsec3 rrsets in the order of the chain
+func (c *DenialChain) RRsets() []*RRset {
+	rrsets := make([]*RRset, 0, len(c.entries))
+	for i := range c.entries {
+		rrsets = append(rrsets, c.rrsetAt(c.entries, i))
+	}
+	return rrsets
+}
+
+//add types to the name, the name is created if it doesn't exist
+func (c *DenialChain) Add(name *Name, types []RRType) (*ChainDiff, error) {
+	if isInZone(name, c.origin) == false {
+		return nil, ErrNameOutOfZone
+	}
+
+	key := nameKey(name)
+	return c.update(name, func() {
+		if _, ok := c.types[key]; ok == false {
+			i := c.nameIndex(name)
+			c.names = append(c.names, nil)
+			copy(c.names[i+1:], c.names[i:])
+			c.names[i] = name
+		}
+		c.types[key] = mergeTypes(c.types[key], types)
+	}), nil
+}
+
+//remove types from the name, the name is removed if no type is left or
+//types is empty
+func (c *DenialChain) Remove(name *Name, types []RRType) (*ChainDiff, error) {
+	key := nameKey(name)
+	old, ok := c.types[key]
+	if ok == false {
+		return nil, ErrChainNameNotExists
+	}
+
+	var left []RRType
+	if len(types) != 0 {
+		for _, t := range old {
+			if hasType(types, t) == false {
+				left = append(left, t)
+			}
+		}
+	}
+
+	return c.update(name, func() {
+		if len(left) != 0 {
+			c.types[key] = left
+			return
+		}
+
+		delete(c.types, key)
+		i := c.nameIndex(name)
+		c.names = append(c.names[:i], c.names[i+1:]...)
+	}), nil
+}
+
+//the change of a name may affect its ancestors which may be empty
+//non-terminals and its descendants which may be occluded by it
+func (c *DenialChain) update(name *Name, change func()) *ChainDiff {
+	affected := make(map[string]*Name)
+	c.addWithAncestors(affected, name)
+	for i := c.nameIndex(name); i < len(c.names) && isInZone(c.names[i], name); i++ {
+		c.addWithAncestors(affected, c.names[i])
+	}
+	change()
+
+	oldEntries := c.entries
+	entries := append([]*chainEntry(nil), oldEntries...)
+	var removed, added []*chainEntry
+	for key, n := range affected {
+		oldEntry := c.findEntry(entries, n, key)
+		newEntry := c.entryFor(n)
+		if sameEntry(oldEntry, newEntry) {
+			continue
+		}
+
+		if oldEntry != nil {
+			i := c.entryIndex(entries, oldEntry)
+			entries = append(entries[:i], entries[i+1:]...)
+			removed = append(removed, oldEntry)
+		}
+		if newEntry != nil {
+			i := c.entryIndex(entries, newEntry)
+			entries = append(entries, nil)
+			copy(entries[i+1:], entries[i:])
+			entries[i] = newEntry
+			added = append(added, newEntry)
+		}
+	}
+	c.entries = entries
+
+	//changed entries and their predecessors in both old and new chain
+	dirty := make(map[string]bool)
+	markDirty := func(entries []*chainEntry, entry *chainEntry) {
+		if len(entries) == 0 {
+			return
+		}
+		i := c.entryIndex(entries, entry)
+		dirty[nameKey(entries[(i+len(entries)-1)%len(entries)].name)] = true
+	}
+	for _, entry := range removed {
+		dirty[nameKey(entry.name)] = true
+		markDirty(oldEntries, entry)
+	}
+	for _, entry := range added {
+		dirty[nameKey(entry.name)] = true
+		markDirty(entries, entry)
+	}
+
+	oldRRsets := c.dirtyRRsets(oldEntries, dirty)
+	newRRsets := c.dirtyRRsets(entries, dirty)
+	diff := &ChainDiff{}
+	for key, rrset := range oldRRsets {
+		if newRRset, ok := newRRsets[key]; ok && rrset.Rdatas[0].Compare(newRRset.Rdatas[0]) == 0 {
+			delete(newRRsets, key)
+			continue
+		}
+		diff.Deleted = append(diff.Deleted, rrset)
+	}
+	for _, rrset := range newRRsets {
+		diff.Added = append(diff.Added, rrset)
+	}
+	SortRRsets(diff.Deleted)
+	SortRRsets(diff.Added)
+	return diff
+}
+
+func (c *DenialChain) dirtyRRsets(entries []*chainEntry, dirty map[string]bool) map[string]*RRset {
+	rrsets := make(map[string]*RRset)
+	for i, entry := range entries {
+		key := nameKey(entry.name)
+		if dirty[key] {
+			rrsets[key] = c.rrsetAt(entries, i)
+		}
+	}
+	return rrsets
+}
+
+func (c *DenialChain) addWithAncestors(names map[string]*Name, name *Name) {
+	count := name.LabelCount() - c.origin.LabelCount()
+	for i := uint(0); i <= count; i++ {
+		ancestor, _ := name.StripLeft(i)
+		key := nameKey(ancestor)
+		if _, ok := names[key]; ok {
+			return
+		}
+		names[key] = ancestor
+	}
+}
+
+//index of the first name not less than name
+func (c *DenialChain) nameIndex(name *Name) int {
+	return sort.Search(len(c.names), func(i int) bool {
+		return c.names[i].Compare(name, false).Order >= 0
+	})
+}
+
+//name below zone cut or dname isn't authoritative
+func (c *DenialChain) isOccluded(name *Name) bool {
+	count := name.LabelCount() - c.origin.LabelCount()
+	for i := uint(1); i < count; i++ {
+		ancestor, _ := name.StripLeft(i)
+		types := c.types[nameKey(ancestor)]
+		if hasType(types, RR_NS) || hasType(types, RR_DNAME) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DenialChain) hasEntryBelow(name *Name) bool {
+	for i := c.nameIndex(name); i < len(c.names) && isInZone(c.names[i], name); i++ {
+		if c.names[i].Equals(name) == false && c.entryFor(c.names[i]) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+//nsec only exists at names with data, while nsec3 also exists at empty
+//non-terminals. type bitmap at delegation only has the types parent zone
+//is authoritative for, rfc4035 section 2.3 and rfc5155 section 7.1
+func (c *DenialChain) entryFor(name *Name) *chainEntry {
+	if c.isOccluded(name) {
+		return nil
+	}
+
+	types, ok := c.types[nameKey(name)]
+	var bitmap []RRType
+	if ok == false {
+		if c.nsec3 == nil || c.hasEntryBelow(name) == false {
+			return nil
+		}
+	} else if name.Equals(c.origin) == false && hasType(types, RR_NS) {
+		signed := hasType(types, RR_DS)
+		if signed == false && c.nsec3 != nil && c.nsec3.OptOut {
+			return nil
+		}
+
+		bitmap = []RRType{RR_NS}
+		if signed {
+			bitmap = append(bitmap, RR_DS)
+		}
+		if signed || c.nsec3 == nil {
+			bitmap = append(bitmap, RR_RRSIG)
+		}
+	} else {
+		for _, t := range types {
+			if t != RR_NSEC && t != RR_NSEC3 && t != RR_RRSIG {
+				bitmap = append(bitmap, t)
+			}
+		}
+		bitmap = append(bitmap, RR_RRSIG)
+		if c.nsec3 != nil && name.Equals(c.origin) {
+			bitmap = mergeTypes(bitmap, []RRType{RR_NSEC3PARAM})
+		}
+	}
+
+	if c.nsec3 == nil {
+		bitmap = append(bitmap, RR_NSEC)
+	}
+	sort.Slice(bitmap, func(i, j int) bool { return bitmap[i] < bitmap[j] })
+
+	entry := &chainEntry{
+		name:  name,
+		owner: name,
+		types: bitmap,
+	}
+	if c.nsec3 != nil {
+		entry.hash, _ = NSEC3Hash(name, NSEC3_HASH_SHA1, c.nsec3.Iterations, c.nsec3.Salt)
+		entry.owner, _ = NameFromStringUnsafe(strings.ToLower(base32.HexEncoding.EncodeToString(entry.hash))).Concat(c.origin)
+	}
+	return entry
+}
+
+func sameEntry(e1, e2 *chainEntry) bool {
+	if e1 == nil || e2 == nil {
+		return e1 == e2
+	}
+
+	if len(e1.types) != len(e2.types) {
+		return false
+	}
+	for i, t := range e1.types {
+		if e2.types[i] != t {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *DenialChain) less(e1, e2 *chainEntry) bool {
+	if c.nsec3 != nil {
+		return bytes.Compare(e1.hash, e2.hash) < 0
+	}
+	return e1.name.Compare(e2.name, false).Order < 0
+}
+
+func (c *DenialChain) entryIndex(entries []*chainEntry, entry *chainEntry) int {
+	return sort.Search(len(entries), func(i int) bool {
+		return c.less(entries[i], entry) == false
+	})
+}
+
+func (c *DenialChain) findEntry(entries []*chainEntry, name *Name, key string) *chainEntry {
+	entry := &chainEntry{name: name}
+	if c.nsec3 != nil {
+		entry.hash, _ = NSEC3Hash(name, NSEC3_HASH_SHA1, c.nsec3.Iterations, c.nsec3.Salt)
+	}
+
+	i := c.entryIndex(entries, entry)
+	if i < len(entries) && nameKey(entries[i].name) == key {
+		return entries[i]
+	}
+	return nil
+}
+
+//the last entry points back to the first one
+func (c *DenialChain) rrsetAt(entries []*chainEntry, i int) *RRset {
+	entry := entries[i]
+	next := entries[(i+1)%len(entries)]
+	rrset := &RRset{
+		Name:  *entry.owner,
+		Class: c.class,
+		Ttl:   c.ttl,
+	}
+
+	if c.nsec3 == nil {
+		rrset.Type = RR_NSEC
+		rrset.Rdatas = []Rdata{&NSEC{
+			NextDomain: next.name,
+			Types:      entry.types,
+		}}
+		return rrset
+	}
+
+	var flags uint8
+	if c.nsec3.OptOut {
+		flags = NSEC3_FLAG_OPTOUT
+	}
+	rrset.Type = RR_NSEC3
+	rrset.Rdatas = []Rdata{&NSEC3{
+		Algorithm:  NSEC3_HASH_SHA1,
+		Flags:      flags,
+		Iterations: c.nsec3.Iterations,
+		SaltLength: uint8(len(c.nsec3.Salt)),
+		Salt:       hex.EncodeToString(c.nsec3.Salt),
+		HashLength: uint8(len(next.hash)),
+		NextHash:   base32.HexEncoding.EncodeToString(next.hash),
+		Types:      entry.types,
+	}}
+	return rrset
+}
@@ -0,0 +1,158 @@
+package g53
+
+import (
+	"testing"
+)
+
+func buildOwners(t *testing.T, ss map[string][]RRType) []OwnerTypes {
+	var owners []OwnerTypes
+	for name, types := range ss {
+		owners = append(owners, OwnerTypes{NameFromStringUnsafe(name), types})
+	}
+	return owners
+}
+
+func rrsetsMatch(t *testing.T, rrsets []*RRset, expect []string) {
+	Equal(t, len(rrsets), len(expect))
+	for i, s := range expect {
+		rrset := buildRRset(t, s)
+		Assert(t, rrsets[i].Name.Equals(&rrset.Name) && rrsets[i].Type == rrset.Type &&
+			rrsets[i].Ttl == rrset.Ttl && len(rrsets[i].Rdatas) == 1 &&
+			rrsets[i].Rdatas[0].Compare(rrset.Rdatas[0]) == 0,
+			"%s should be %s", rrsets[i].String(), s)
+	}
+}
+
+func TestNSECChain(t *testing.T) {
+	origin := NameFromStringUnsafe("example")
+	chain, err := NewNSECChain(origin, CLASS_IN, 3600, buildOwners(t, map[string][]RRType{
+		"example":      []RRType{RR_SOA, RR_NS, RR_DNSKEY, RR_RRSIG},
+		"a.example":    []RRType{RR_NS, RR_DS},
+		"ns.a.example": []RRType{RR_A},
+		"b.c.example":  []RRType{RR_A},
+		"*.w.example":  []RRType{RR_MX},
+	}))
+	Assert(t, err == nil, "build nsec chain failed:%v", err)
+	rrsetsMatch(t, chain.RRsets(), []string{
+		"example. 3600 IN NSEC a.example. NS SOA RRSIG NSEC DNSKEY",
+		"a.example. 3600 IN NSEC b.c.example. NS DS RRSIG NSEC",
+		"b.c.example. 3600 IN NSEC *.w.example. A RRSIG NSEC",
+		"*.w.example. 3600 IN NSEC example. MX RRSIG NSEC",
+	})
+
+	diff, err := chain.Add(NameFromStringUnsafe("d.example"), []RRType{RR_TXT})
+	Assert(t, err == nil, "add name failed:%v", err)
+	rrsetsMatch(t, diff.Deleted, []string{"b.c.example. 3600 IN NSEC *.w.example. A RRSIG NSEC"})
+	rrsetsMatch(t, diff.Added, []string{
+		"b.c.example. 3600 IN NSEC d.example. A RRSIG NSEC",
+		"d.example. 3600 IN NSEC *.w.example. TXT RRSIG NSEC",
+	})
+
+	diff, err = chain.Add(NameFromStringUnsafe("d.example"), []RRType{RR_A})
+	Assert(t, err == nil, "add type failed:%v", err)
+	rrsetsMatch(t, diff.Deleted, []string{"d.example. 3600 IN NSEC *.w.example. TXT RRSIG NSEC"})
+	rrsetsMatch(t, diff.Added, []string{"d.example. 3600 IN NSEC *.w.example. A TXT RRSIG NSEC"})
+
+	//glue isn't in the chain
+	diff, err = chain.Add(NameFromStringUnsafe("ns2.a.example"), []RRType{RR_A})
+	Assert(t, err == nil, "add glue failed:%v", err)
+	Assert(t, len(diff.Deleted) == 0 && len(diff.Added) == 0, "glue shouldn't change the chain")
+
+	//remove delegation makes the glue authoritative
+	diff, err = chain.Remove(NameFromStringUnsafe("a.example"), nil)
+	Assert(t, err == nil, "remove name failed:%v", err)
+	rrsetsMatch(t, diff.Deleted, []string{
+		"example. 3600 IN NSEC a.example. NS SOA RRSIG NSEC DNSKEY",
+		"a.example. 3600 IN NSEC b.c.example. NS DS RRSIG NSEC",
+	})
+	rrsetsMatch(t, diff.Added, []string{
+		"example. 3600 IN NSEC ns.a.example. NS SOA RRSIG NSEC DNSKEY",
+		"ns.a.example. 3600 IN NSEC ns2.a.example. A RRSIG NSEC",
+		"ns2.a.example. 3600 IN NSEC b.c.example. A RRSIG NSEC",
+	})
+
+	diff, err = chain.Remove(NameFromStringUnsafe("*.w.example"), []RRType{RR_MX})
+	Assert(t, err == nil, "remove type failed:%v", err)
+	rrsetsMatch(t, diff.Deleted, []string{
+		"d.example. 3600 IN NSEC *.w.example. A TXT RRSIG NSEC",
+		"*.w.example. 3600 IN NSEC example. MX RRSIG NSEC",
+	})
+	rrsetsMatch(t, diff.Added, []string{"d.example. 3600 IN NSEC example. A TXT RRSIG NSEC"})
+
+	_, err = chain.Remove(NameFromStringUnsafe("*.w.example"), nil)
+	Equal(t, err, ErrChainNameNotExists)
+	_, err = chain.Add(NameFromStringUnsafe("a.example.org"), []RRType{RR_A})
+	Equal(t, err, ErrNameOutOfZone)
+}
+
+//zone from rfc5155 appendix a
+var rfc5155Owners = map[string][]RRType{
+	"example":       []RRType{RR_SOA, RR_NS, RR_MX, RR_DNSKEY},
+	"a.example":     []RRType{RR_NS, RR_DS},
+	"ns1.a.example": []RRType{RR_A},
+	"ns2.a.example": []RRType{RR_A},
+	"ai.example":    []RRType{RR_A, RR_HINFO, RR_AAAA},
+	"c.example":     []RRType{RR_NS},
+	"ns1.c.example": []RRType{RR_A},
+	"ns2.c.example": []RRType{RR_A},
+	"ns1.example":   []RRType{RR_A},
+	"ns2.example":   []RRType{RR_A},
+	"*.w.example":   []RRType{RR_MX},
+	"x.w.example":   []RRType{RR_MX},
+	"x.y.w.example": []RRType{RR_MX},
+	"xx.example":    []RRType{RR_A, RR_HINFO, RR_AAAA},
+	"2t7b4g4vsa5smi47k61mv5bv1a22bojr.example": []RRType{RR_A},
+}
+
+func TestNSEC3Chain(t *testing.T) {
+	origin := NameFromStringUnsafe("example")
+	conf := NSEC3Config{
+		Iterations: 12,
+		Salt:       []byte{0xaa, 0xbb, 0xcc, 0xdd},
+		OptOut:     true,
+	}
+	chain, err := NewNSEC3Chain(origin, CLASS_IN, 3600, conf, buildOwners(t, rfc5155Owners))
+	Assert(t, err == nil, "build nsec3 chain failed:%v", err)
+	Assert(t, chain.IsNSEC3(), "chain should be nsec3")
+	rrsetsMatch(t, chain.RRsets(), rfc5155Chain)
+
+	//empty non-terminal z.example is added with the name below it
+	diff, err := chain.Add(NameFromStringUnsafe("a.z.example"), []RRType{RR_A})
+	Assert(t, err == nil, "add name failed:%v", err)
+	Equal(t, len(diff.Added), 4)
+	Equal(t, len(diff.Deleted), 2)
+	Equal(t, len(chain.RRsets()), len(rfc5155Chain)+2)
+	diff, err = chain.Remove(NameFromStringUnsafe("a.z.example"), nil)
+	Assert(t, err == nil, "remove name failed:%v", err)
+	Equal(t, len(diff.Added), 2)
+	Equal(t, len(diff.Deleted), 4)
+	rrsetsMatch(t, chain.RRsets(), rfc5155Chain)
+
+	//unsigned delegation is excluded by opt-out until it has ds
+	diff, err = chain.Add(NameFromStringUnsafe("c.example"), []RRType{RR_DS})
+	Assert(t, err == nil, "add ds failed:%v", err)
+	Equal(t, len(diff.Added), 2)
+	Equal(t, len(diff.Deleted), 1)
+	owner, _ := NSEC3HashedOwner(NameFromStringUnsafe("c.example"), origin, NSEC3_HASH_SHA1, 12, conf.Salt)
+	found := false
+	for _, rrset := range diff.Added {
+		if rrset.Name.Equals(owner) {
+			found = true
+			Equal(t, typeBitmapToString(rrset.Rdatas[0].(*NSEC3).Types), " NS DS RRSIG")
+		}
+	}
+	Assert(t, found, "c.example should be added to the chain")
+
+	//without opt-out unsigned delegation is in the chain without rrsig
+	conf.OptOut = false
+	chain, err = NewNSEC3Chain(origin, CLASS_IN, 3600, conf, buildOwners(t, rfc5155Owners))
+	Assert(t, err == nil, "build nsec3 chain failed:%v", err)
+	Equal(t, len(chain.RRsets()), len(rfc5155Chain)+1)
+	for _, rrset := range chain.RRsets() {
+		nsec3 := rrset.Rdatas[0].(*NSEC3)
+		Assert(t, nsec3.IsOptOut() == false, "nsec3 shouldn't be opt-out")
+		if rrset.Name.Equals(owner) {
+			Equal(t, typeBitmapToString(nsec3.Types), " NS")
+		}
+	}
+}
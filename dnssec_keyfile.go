@@ -0,0 +1,355 @@
+package g53
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKeyFile  = errors.New("key file isn't valid")
+	ErrKeyPairMismatch = errors.New("private key doesn't match the dnskey")
+)
+
+const (
+	bindPrivateKeyFormat = "v1.3"
+	bindTimeFormat       = "20060102150405"
+)
+
+//timing metadata of the key, zero time means the field isn't set
+type KeyTiming struct {
+	Created     time.Time
+	Publish     time.Time
+	Activate    time.Time
+	Revoke      time.Time
+	Inactive    time.Time
+	Delete      time.Time
+	SyncPublish time.Time
+	SyncDelete  time.Time
+}
+
+//key pair stored in K<zone>+<alg>+<tag>.key and .private generated by
+//dnssec-keygen
+type BindKey struct {
+	Name       *Name
+	Ttl        RRTTL
+	DNSKey     *DNSKey
+	PrivateKey crypto.Signer
+	Timing     KeyTiming
+}
+
+//the private key should match the public key of dnskey
+func NewBindKey(name *Name, dnskey *DNSKey, privKey crypto.Signer, timing KeyTiming) (*BindKey, error) {
+	key := &BindKey{
+		Name:       name,
+		DNSKey:     dnskey,
+		PrivateKey: privKey,
+		Timing:     timing,
+	}
+	if err := key.checkKeyPair(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (k *BindKey) checkKeyPair() error {
+	dnskey, err := NewDNSKey(k.DNSKey.Flags, k.DNSKey.Algorithm, k.PrivateKey.Public())
+	if err != nil {
+		return err
+	} else if bytes.Equal(dnskey.PublicKey, k.DNSKey.PublicKey) == false {
+		return ErrKeyPairMismatch
+	}
+	return nil
+}
+
+func (k *BindKey) SigningKey() *SigningKey {
+	return &SigningKey{
+		Signer:     k.Name,
+		PrivateKey: k.PrivateKey,
+		dnskey:     k.DNSKey,
+	}
+}
+
+//file name without suffix, like Kexample.com.+013+12345
+func (k *BindKey) FileName() string {
+	return fmt.Sprintf("K%s+%03d+%05d", strings.ToLower(k.Name.String(false)), k.DNSKey.Algorithm, k.DNSKey.KeyTag())
+}
+
+//load key pair from the .key and .private file, path could be either of
+//them or the file name without suffix
+func LoadBindKey(path string) (*BindKey, error) {
+	path = strings.TrimSuffix(strings.TrimSuffix(path, ".key"), ".private")
+	public, err := os.ReadFile(path + ".key")
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := os.ReadFile(path + ".private")
+	if err != nil {
+		return nil, err
+	}
+	return BindKeyFromString(string(public), string(private))
+}
+
+//save the key pair into dir, the file names are generated by FileName
+func (k *BindKey) Save(dir string) error {
+	path := filepath.Join(dir, k.FileName())
+	if err := os.WriteFile(path+".key", []byte(k.PublicKeyString()), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(path+".private", []byte(k.PrivateKeyString()), 0600)
+}
+
+//public key file has comments and one dnskey rr whose ttl is optional,
+//timing in private key file is used since it's always there
+func BindKeyFromString(public, private string) (*BindKey, error) {
+	key := &BindKey{}
+	scanner := bufio.NewScanner(strings.NewReader(public))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if key.DNSKey != nil {
+			return nil, ErrInvalidKeyFile
+		}
+
+		var err error
+		if key.Name, key.Ttl, key.DNSKey, err = dnskeyFromKeyFile(line); err != nil {
+			return nil, err
+		}
+	}
+	if key.DNSKey == nil {
+		return nil, ErrInvalidKeyFile
+	}
+
+	fields, err := parsePrivateKeyFile(private)
+	if err != nil {
+		return nil, err
+	}
+
+	if alg, ok := fields["Algorithm"]; ok == false || len(strings.Fields(alg)) == 0 {
+		return nil, ErrInvalidKeyFile
+	} else if n, err := strconv.Atoi(strings.Fields(alg)[0]); err != nil || uint8(n) != key.DNSKey.Algorithm {
+		return nil, ErrKeyPairMismatch
+	}
+
+	if key.PrivateKey, err = privateKeyFromFields(key.DNSKey.Algorithm, fields); err != nil {
+		return nil, err
+	}
+
+	for _, f := range keyTimingFields {
+		if v, ok := fields[f.name]; ok {
+			if *f.field(&key.Timing), err = time.Parse(bindTimeFormat, v); err != nil {
+				return nil, fmt.Errorf("invalid %s time %s", f.name, v)
+			}
+		}
+	}
+
+	if err := key.checkKeyPair(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func dnskeyFromKeyFile(line string) (*Name, RRTTL, *DNSKey, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return nil, 0, nil, ErrInvalidKeyFile
+	}
+
+	name, err := NameFromString(fields[0])
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	fields = fields[1:]
+
+	var ttl RRTTL
+	if t, err := TTLFromString(fields[0]); err == nil {
+		ttl = t
+		fields = fields[1:]
+	}
+	if len(fields) != 0 && strings.EqualFold(fields[0], CLASS_IN.String()) {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 || strings.EqualFold(fields[0], RR_DNSKEY.String()) == false {
+		return nil, 0, nil, ErrInvalidKeyFile
+	}
+
+	dnskey, err := DNSKeyFromString(strings.Join(fields[1:], " "))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return name, ttl, dnskey, nil
+}
+
+func parsePrivateKeyFile(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i == -1 {
+			return nil, ErrInvalidKeyFile
+		}
+		fields[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+
+	if strings.HasPrefix(fields["Private-key-format"], "v1.") == false {
+		return nil, ErrInvalidKeyFile
+	}
+	return fields, nil
+}
+
+//timing fields in the order bind writes them
+var keyTimingFields = []struct {
+	name  string
+	field func(*KeyTiming) *time.Time
+}{
+	{"Created", func(t *KeyTiming) *time.Time { return &t.Created }},
+	{"Publish", func(t *KeyTiming) *time.Time { return &t.Publish }},
+	{"Activate", func(t *KeyTiming) *time.Time { return &t.Activate }},
+	{"Revoke", func(t *KeyTiming) *time.Time { return &t.Revoke }},
+	{"Inactive", func(t *KeyTiming) *time.Time { return &t.Inactive }},
+	{"Delete", func(t *KeyTiming) *time.Time { return &t.Delete }},
+	{"SyncPublish", func(t *KeyTiming) *time.Time { return &t.SyncPublish }},
+	{"SyncDelete", func(t *KeyTiming) *time.Time { return &t.SyncDelete }},
+}
+
+func base64Field(fields map[string]string, name string) ([]byte, error) {
+	v, ok := fields[name]
+	if ok == false {
+		return nil, fmt.Errorf("missing %s in private key file", name)
+	}
+	return base64.StdEncoding.DecodeString(v)
+}
+
+func privateKeyFromFields(algorithm uint8, fields map[string]string) (crypto.Signer, error) {
+	switch algorithm {
+	case ALGORITHM_RSASHA1, ALGORITHM_RSASHA1_NSEC3_SHA1, ALGORITHM_RSASHA256, ALGORITHM_RSASHA512:
+		var ints []*big.Int
+		for _, name := range []string{"Modulus", "PublicExponent", "PrivateExponent", "Prime1", "Prime2"} {
+			data, err := base64Field(fields, name)
+			if err != nil {
+				return nil, err
+			}
+			ints = append(ints, new(big.Int).SetBytes(data))
+		}
+
+		if ints[1].IsInt64() == false || ints[1].Int64() > 1<<31-1 {
+			return nil, ErrInvalidKeyFile
+		}
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: ints[0], E: int(ints[1].Int64())},
+			D:         ints[2],
+			Primes:    []*big.Int{ints[3], ints[4]},
+		}
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		key.Precompute()
+		return key, nil
+	case ALGORITHM_ECDSAP256SHA256, ALGORITHM_ECDSAP384SHA384:
+		curve := elliptic.P256()
+		if algorithm == ALGORITHM_ECDSAP384SHA384 {
+			curve = elliptic.P384()
+		}
+
+		data, err := base64Field(fields, "PrivateKey")
+		if err != nil {
+			return nil, err
+		} else if len(data) != (curve.Params().BitSize+7)/8 {
+			return nil, ErrInvalidKeyFile
+		}
+
+		key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(data)}
+		key.Curve = curve
+		key.X, key.Y = curve.ScalarBaseMult(data)
+		return key, nil
+	case ALGORITHM_ED25519:
+		data, err := base64Field(fields, "PrivateKey")
+		if err != nil {
+			return nil, err
+		} else if len(data) != ed25519.SeedSize {
+			return nil, ErrInvalidKeyFile
+		}
+		return ed25519.NewKeyFromSeed(data), nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+//content of the .key file, timing is written as comments like bind does
+func (k *BindKey) PublicKeyString() string {
+	var buf bytes.Buffer
+	kind := "zone-signing"
+	if k.DNSKey.IsSEP() {
+		kind = "key-signing"
+	}
+	fmt.Fprintf(&buf, "; This is a %s key, keyid %d, for %s\n", kind, k.DNSKey.KeyTag(), k.Name.String(false))
+	for _, f := range keyTimingFields {
+		if t := *f.field(&k.Timing); t.IsZero() == false {
+			fmt.Fprintf(&buf, "; %s: %s (%s)\n", f.name, t.UTC().Format(bindTimeFormat), t.UTC().Format(time.ANSIC))
+		}
+	}
+
+	buf.WriteString(k.Name.String(false))
+	if k.Ttl != 0 {
+		buf.WriteString(" " + k.Ttl.String())
+	}
+	fmt.Fprintf(&buf, " %s %s %s\n", CLASS_IN.String(), RR_DNSKEY.String(), k.DNSKey.String())
+	return buf.String()
+}
+
+//content of the .private file
+func (k *BindKey) PrivateKeyString() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Private-key-format: %s\n", bindPrivateKeyFormat)
+	fmt.Fprintf(&buf, "Algorithm: %d (%s)\n", k.DNSKey.Algorithm, algorithmMnemonics[k.DNSKey.Algorithm])
+	writeField := func(name string, data []byte) {
+		fmt.Fprintf(&buf, "%s: %s\n", name, base64.StdEncoding.EncodeToString(data))
+	}
+
+	switch key := k.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		key.Precompute()
+		writeField("Modulus", key.N.Bytes())
+		writeField("PublicExponent", big.NewInt(int64(key.E)).Bytes())
+		writeField("PrivateExponent", key.D.Bytes())
+		writeField("Prime1", key.Primes[0].Bytes())
+		writeField("Prime2", key.Primes[1].Bytes())
+		writeField("Exponent1", key.Precomputed.Dp.Bytes())
+		writeField("Exponent2", key.Precomputed.Dq.Bytes())
+		writeField("Coefficient", key.Precomputed.Qinv.Bytes())
+	case *ecdsa.PrivateKey:
+		data := make([]byte, (key.Curve.Params().BitSize+7)/8)
+		writeField("PrivateKey", key.D.FillBytes(data))
+	case ed25519.PrivateKey:
+		writeField("PrivateKey", key.Seed())
+	}
+
+	for _, f := range keyTimingFields {
+		if t := *f.field(&k.Timing); t.IsZero() == false {
+			fmt.Fprintf(&buf, "%s: %s\n", f.name, t.UTC().Format(bindTimeFormat))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,106 @@
+package g53
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBindKeyFromString(t *testing.T) {
+	//private keys from rfc6605 section 6.1 and rfc8080 section 6.1
+	for _, c := range []struct {
+		public   string
+		private  string
+		fileName string
+	}{
+		{
+			"; This is a key-signing key, keyid 55648, for example.net.\n" +
+				"example.net. 3600 IN DNSKEY 257 3 13 GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA==\n",
+			"Private-key-format: v1.2\n" +
+				"Algorithm: 13 (ECDSAP256SHA256)\n" +
+				"PrivateKey: GU6SnQ/Ou+xC5RumuIUIuJZteXT2z0O/ok1s38Et6mQ=\n",
+			"Kexample.net.+013+55648",
+		},
+		{
+			"example.com. IN DNSKEY 257 3 15 l02Woi0iS8Aa25FQkUd9RMzZHJpBoRQwAQEX1SxZJA4=\n",
+			"Private-key-format: v1.2\n" +
+				"Algorithm: 15 (ED25519)\n" +
+				"PrivateKey: ODIyNjAzODQ2MjgwODAxMjI2NDUxOTAyMDQxNDIyNjI=\n",
+			"Kexample.com.+015+03613",
+		},
+	} {
+		key, err := BindKeyFromString(c.public, c.private)
+		Assert(t, err == nil, "parse key file failed:%v", err)
+		Equal(t, key.FileName(), c.fileName)
+		Assert(t, key.Timing == KeyTiming{}, "timing should be empty")
+
+		//exported files can be parsed back
+		key2, err := BindKeyFromString(key.PublicKeyString(), key.PrivateKeyString())
+		Assert(t, err == nil, "parse exported key file failed:%v", err)
+		Equal(t, key2.DNSKey.Compare(key.DNSKey), 0)
+		Equal(t, key2.PrivateKeyString(), key.PrivateKeyString())
+
+		signer := key.SigningKey()
+		rrset := buildRRset(t, "www."+key.Name.String(false)+" 300 IN A 192.0.2.1")
+		inception := time.Unix(1600000000, 0)
+		rrsig, err := signer.Sign(rrset, inception, inception.Add(time.Hour))
+		Assert(t, err == nil, "sign failed:%v", err)
+		Equal(t, VerifyRRSigAt(rrset, rrsig, key.DNSKey, inception), nil)
+	}
+
+	public := "example.net. 3600 IN DNSKEY 257 3 13 GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA==\n"
+	private := "Private-key-format: v1.2\nAlgorithm: 13 (ECDSAP256SHA256)\nPrivateKey: ODIyNjAzODQ2MjgwODAxMjI2NDUxOTAyMDQxNDIyNjI=\n"
+	_, err := BindKeyFromString(public, private)
+	Equal(t, err, ErrKeyPairMismatch)
+	_, err = BindKeyFromString(public, strings.Replace(private, "13 (", "15 (", 1))
+	Equal(t, err, ErrKeyPairMismatch)
+	_, err = BindKeyFromString("; no key\n", private)
+	Equal(t, err, ErrInvalidKeyFile)
+	_, err = BindKeyFromString(public+public, private)
+	Equal(t, err, ErrInvalidKeyFile)
+	_, err = BindKeyFromString(public, strings.Replace(private, "13 (ECDSAP256SHA256)", "", 1))
+	Equal(t, err, ErrInvalidKeyFile)
+}
+
+func TestBindKeySaveAndLoad(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	signer, err := NewSigningKey(NameFromStringUnsafe("example.org."), DNSKEY_FLAG_ZONE, ALGORITHM_RSASHA256, rsaKey)
+	Assert(t, err == nil, "create signing key failed:%v", err)
+
+	created := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	timing := KeyTiming{
+		Created:     created,
+		Publish:     created,
+		Activate:    created.Add(24 * time.Hour),
+		Revoke:      created.Add(80 * 24 * time.Hour),
+		Inactive:    created.Add(90 * 24 * time.Hour),
+		Delete:      created.Add(120 * 24 * time.Hour),
+		SyncPublish: created.Add(48 * time.Hour),
+		SyncDelete:  created.Add(100 * 24 * time.Hour),
+	}
+	key, err := NewBindKey(signer.Signer, signer.DNSKey(), rsaKey, timing)
+	Assert(t, err == nil, "create bind key failed:%v", err)
+	Assert(t, strings.Contains(key.PrivateKeyString(), "Activate: 20200914122640\n"), "activate time should be saved")
+	Assert(t, strings.Contains(key.PrivateKeyString(), "SyncPublish: 20200915122640\n"), "sync publish time should be saved")
+	Assert(t, strings.Contains(key.PublicKeyString(), "; This is a zone-signing key"), "zsk comment should be saved")
+
+	dir, err := os.MkdirTemp("", "g53keyfile")
+	Assert(t, err == nil, "create temp dir failed:%v", err)
+	defer os.RemoveAll(dir)
+
+	Equal(t, key.Save(dir), nil)
+	loaded, err := LoadBindKey(filepath.Join(dir, key.FileName()+".private"))
+	Assert(t, err == nil, "load key failed:%v", err)
+	Equal(t, loaded.DNSKey.Compare(key.DNSKey), 0)
+	Assert(t, loaded.PrivateKey.(*rsa.PrivateKey).Equal(rsaKey), "private key should be same")
+	Assert(t, loaded.Timing == timing, "timing should be same")
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = NewBindKey(signer.Signer, signer.DNSKey(), edKey, timing)
+	Assert(t, err != nil, "mismatched key pair should fail")
+}
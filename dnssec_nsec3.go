@@ -0,0 +1,423 @@
+package g53
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+//hash algorithm and flags defined in rfc5155
+const (
+	NSEC3_HASH_SHA1   uint8 = 1
+	NSEC3_FLAG_OPTOUT uint8 = 0x01
+)
+
+//nsec3 with more iterations isn't used for denial proof to avoid hashing
+//too much for a hostile zone, most resolvers use this limit, rfc9276
+//section 3.2 and appendix a
+const NSEC3_MAX_ITERATIONS uint16 = 150
+
+var (
+	ErrUnsupportedNSEC3Hash = errors.New("nsec3 hash algorithm isn't supported")
+	ErrNoNSEC3              = errors.New("no usable nsec3 in the response")
+	ErrNSEC3ParamMismatch   = errors.New("nsec3 records have different parameters")
+	ErrNoClosestEncloser    = errors.New("nsec3 records don't prove the closest encloser")
+	ErrNSEC3ProofFailed     = errors.New("nsec3 records don't prove the denial")
+	ErrNSEC3TooManyIters    = errors.New("nsec3 iterations exceed the limit")
+)
+
+type NSEC3ProofType int
+
+const (
+	NSEC3_PROOF_NXDOMAIN NSEC3ProofType = iota
+	NSEC3_PROOF_NODATA
+	NSEC3_PROOF_WILDCARD_NODATA
+	NSEC3_PROOF_WILDCARD
+	NSEC3_PROOF_INSECURE_DELEGATION
+	NSEC3_PROOF_OPTOUT
+)
+
+func (t NSEC3ProofType) String() string {
+	switch t {
+	case NSEC3_PROOF_NXDOMAIN:
+		return "nxdomain"
+	case NSEC3_PROOF_NODATA:
+		return "nodata"
+	case NSEC3_PROOF_WILDCARD_NODATA:
+		return "wildcard nodata"
+	case NSEC3_PROOF_WILDCARD:
+		return "wildcard"
+	case NSEC3_PROOF_INSECURE_DELEGATION:
+		return "insecure delegation"
+	case NSEC3_PROOF_OPTOUT:
+		return "opt-out"
+	default:
+		return "unknown"
+	}
+}
+
+//next closer is nil if the proof doesn't need it, opt-out is set when
+//the nsec3 covering next closer has opt-out flag, which means an
+//unsigned delegation may exist under the closest encloser
+type NSEC3Proof struct {
+	Type            NSEC3ProofType
+	ClosestEncloser *Name
+	NextCloser      *Name
+	OptOut          bool
+}
+
+//hash the canonical wire format of the name with salt, the result is
+//hashed again with salt for extra iterations, rfc5155 section 5
+func NSEC3Hash(name *Name, algorithm uint8, iterations uint16, salt []byte) ([]byte, error) {
+	if algorithm != NSEC3_HASH_SHA1 {
+		return nil, ErrUnsupportedNSEC3Hash
+	}
+
+	data := append(append([]byte(nil), downcasedName(name).raw...), salt...)
+	digest := sha1.Sum(data)
+	for i := uint16(0); i < iterations; i++ {
+		digest = sha1.Sum(append(digest[:], salt...))
+	}
+	return digest[:], nil
+}
+
+//hashed owner name is the base32hex of the hash in lower case, prepended
+//to the zone as a single label
+func NSEC3HashedOwner(name, zone *Name, algorithm uint8, iterations uint16, salt []byte) (*Name, error) {
+	hash, err := NSEC3Hash(name, algorithm, iterations, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := NameFromString(strings.ToLower(base32.HexEncoding.EncodeToString(hash)))
+	if err != nil {
+		return nil, err
+	}
+	return label.Concat(zone)
+}
+
+func (nsec3 *NSEC3) IsOptOut() bool {
+	return nsec3.Flags&NSEC3_FLAG_OPTOUT != 0
+}
+
+//hash the name with the parameters of the nsec3, nsec3 from other zone
+//with iterations above NSEC3_MAX_ITERATIONS is rejected
+func (nsec3 *NSEC3) HashName(name *Name) ([]byte, error) {
+	if nsec3.Iterations > NSEC3_MAX_ITERATIONS {
+		return nil, ErrNSEC3TooManyIters
+	}
+	return NSEC3Hash(name, nsec3.Algorithm, nsec3.Iterations, encodeStringToHex(nsec3.Salt))
+}
+
+type nsec3Record struct {
+	hash  []byte
+	next  []byte
+	nsec3 *NSEC3
+}
+
+//nsec3 records of one zone with same parameters, rfc5155 section 8.2
+type nsec3Chain struct {
+	zone       *Name
+	algorithm  uint8
+	iterations uint16
+	salt       []byte
+	records    []nsec3Record
+	hashes     map[string][]byte
+}
+
+//nsec3 with unknown hash algorithm or owner name which isn't a hash is
+//ignored, all the others must belong to one zone with same parameters.
+//the response is treated as insecure if iterations is too large
+func newNSEC3Chain(rrsets []*RRset) (*nsec3Chain, error) {
+	var chain *nsec3Chain
+	for _, rrset := range rrsets {
+		if rrset.Type != RR_NSEC3 || rrset.Name.LabelCount() < 2 {
+			continue
+		}
+
+		label := strings.ToUpper(string(rrset.Name.raw[1 : 1+rrset.Name.raw[0]]))
+		hash := encodeNSEC3NextHash([]byte(label))
+		if len(hash) == 0 || base32.HexEncoding.EncodeToString(hash) != label {
+			continue
+		}
+		zone, _ := rrset.Name.StripLeft(1)
+
+		for _, rdata := range rrset.Rdatas {
+			nsec3, ok := rdata.(*NSEC3)
+			if ok == false || nsec3.Algorithm != NSEC3_HASH_SHA1 {
+				continue
+			} else if nsec3.Iterations > NSEC3_MAX_ITERATIONS {
+				return nil, ErrNSEC3TooManyIters
+			}
+
+			salt := encodeStringToHex(nsec3.Salt)
+			if chain == nil {
+				chain = &nsec3Chain{
+					zone:       zone,
+					algorithm:  nsec3.Algorithm,
+					iterations: nsec3.Iterations,
+					salt:       salt,
+					hashes:     make(map[string][]byte),
+				}
+			} else if chain.zone.Equals(zone) == false || chain.iterations != nsec3.Iterations ||
+				bytes.Equal(chain.salt, salt) == false {
+				return nil, ErrNSEC3ParamMismatch
+			}
+
+			chain.records = append(chain.records, nsec3Record{
+				hash:  hash,
+				next:  encodeNSEC3NextHash([]byte(nsec3.NextHash)),
+				nsec3: nsec3,
+			})
+		}
+	}
+
+	if chain == nil {
+		return nil, ErrNoNSEC3
+	}
+	return chain, nil
+}
+
+func (c *nsec3Chain) hash(name *Name) []byte {
+	key := hex.EncodeToString(downcasedName(name).raw)
+	if hash, ok := c.hashes[key]; ok {
+		return hash
+	}
+
+	hash, _ := NSEC3Hash(name, c.algorithm, c.iterations, c.salt)
+	c.hashes[key] = hash
+	return hash
+}
+
+func (c *nsec3Chain) match(name *Name) *NSEC3 {
+	hash := c.hash(name)
+	for _, r := range c.records {
+		if bytes.Equal(r.hash, hash) {
+			return r.nsec3
+		}
+	}
+	return nil
+}
+
+//the last nsec3 in the chain covers the hashes after its owner and the
+//ones before the first owner
+func (c *nsec3Chain) cover(name *Name) *NSEC3 {
+	hash := c.hash(name)
+	for _, r := range c.records {
+		if bytes.Compare(r.hash, r.next) < 0 {
+			if bytes.Compare(r.hash, hash) < 0 && bytes.Compare(hash, r.next) < 0 {
+				return r.nsec3
+			}
+		} else if bytes.Compare(r.hash, hash) < 0 || bytes.Compare(hash, r.next) < 0 {
+			return r.nsec3
+		}
+	}
+	return nil
+}
+
+//find the longest existing ancestor of name and the nsec3 covering the
+//next closer name, rfc5155 section 8.3. the closest encloser shouldn't
+//be a delegation point or have dname, otherwise the nsec3 comes from
+//the parent zone or the name should have been redirected
+func (c *nsec3Chain) closestEncloser(name *Name) (*Name, *Name, *NSEC3, error) {
+	if isInZone(name, c.zone) == false {
+		return nil, nil, nil, ErrNoClosestEncloser
+	}
+
+	count := name.LabelCount() - c.zone.LabelCount()
+	for i := uint(1); i <= count; i++ {
+		encloser, _ := name.StripLeft(i)
+		nsec3 := c.match(encloser)
+		if nsec3 == nil {
+			continue
+		}
+
+		if hasType(nsec3.Types, RR_DNAME) ||
+			(hasType(nsec3.Types, RR_NS) && hasType(nsec3.Types, RR_SOA) == false) {
+			return nil, nil, nil, ErrNoClosestEncloser
+		}
+
+		nextCloser, _ := name.StripLeft(i - 1)
+		covering := c.cover(nextCloser)
+		if covering == nil {
+			return nil, nil, nil, ErrNoClosestEncloser
+		}
+		return encloser, nextCloser, covering, nil
+	}
+	return nil, nil, nil, ErrNoClosestEncloser
+}
+
+func hasType(types []RRType, t RRType) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+func wildcardOf(name *Name) *Name {
+	wildcard, _ := NameFromStringUnsafe("*").Concat(name)
+	return wildcard
+}
+
+//nxdomain needs the closest encloser proof and nsec3 covering the
+//wildcard at closest encloser, rfc5155 section 8.4
+func ProveNXDomain(qname *Name, rrsets []*RRset) (*NSEC3Proof, error) {
+	chain, err := newNSEC3Chain(rrsets)
+	if err != nil {
+		return nil, err
+	}
+
+	if chain.match(qname) != nil {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	encloser, nextCloser, covering, err := chain.closestEncloser(qname)
+	if err != nil {
+		return nil, err
+	}
+
+	if chain.cover(wildcardOf(encloser)) == nil {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	return &NSEC3Proof{
+		Type:            NSEC3_PROOF_NXDOMAIN,
+		ClosestEncloser: encloser,
+		NextCloser:      nextCloser,
+		OptOut:          covering.IsOptOut(),
+	}, nil
+}
+
+//nodata is proved by nsec3 matching qname without qtype and cname, or
+//matching the wildcard at closest encloser. for ds query without matching
+//nsec3, the nsec3 covering next closer with opt-out proves an unsigned
+//delegation, rfc5155 section 8.5, 8.6 and 8.7
+func ProveNoData(qname *Name, qtype RRType, rrsets []*RRset) (*NSEC3Proof, error) {
+	chain, err := newNSEC3Chain(rrsets)
+	if err != nil {
+		return nil, err
+	}
+
+	if nsec3 := chain.match(qname); nsec3 != nil {
+		if hasType(nsec3.Types, qtype) || hasType(nsec3.Types, RR_CNAME) {
+			return nil, ErrNSEC3ProofFailed
+		}
+
+		//ds nodata at delegation should come from parent zone, and the
+		//other types should come from child zone, rfc6840 section 4.1
+		if qtype == RR_DS && hasType(nsec3.Types, RR_SOA) {
+			return nil, ErrNSEC3ProofFailed
+		} else if qtype != RR_DS && hasType(nsec3.Types, RR_NS) && hasType(nsec3.Types, RR_SOA) == false {
+			return nil, ErrNSEC3ProofFailed
+		}
+
+		return &NSEC3Proof{
+			Type:            NSEC3_PROOF_NODATA,
+			ClosestEncloser: qname,
+		}, nil
+	}
+
+	encloser, nextCloser, covering, err := chain.closestEncloser(qname)
+	if err != nil {
+		return nil, err
+	}
+
+	if qtype == RR_DS {
+		if covering.IsOptOut() == false {
+			return nil, ErrNSEC3ProofFailed
+		}
+		return &NSEC3Proof{
+			Type:            NSEC3_PROOF_OPTOUT,
+			ClosestEncloser: encloser,
+			NextCloser:      nextCloser,
+			OptOut:          true,
+		}, nil
+	}
+
+	nsec3 := chain.match(wildcardOf(encloser))
+	if nsec3 == nil || hasType(nsec3.Types, qtype) || hasType(nsec3.Types, RR_CNAME) {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	return &NSEC3Proof{
+		Type:            NSEC3_PROOF_WILDCARD_NODATA,
+		ClosestEncloser: encloser,
+		NextCloser:      nextCloser,
+		OptOut:          covering.IsOptOut(),
+	}, nil
+}
+
+//answer synthesized from wildcard needs nsec3 covering the next closer
+//name, closest encloser is derived from the labels field of the rrsig
+//which covers the answer, rfc5155 section 8.8
+func ProveWildcardAnswer(qname *Name, labels uint8, rrsets []*RRset) (*NSEC3Proof, error) {
+	count := qname.LabelCount() - 1
+	if uint(labels) >= count {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	chain, err := newNSEC3Chain(rrsets)
+	if err != nil {
+		return nil, err
+	}
+
+	encloser, _ := qname.StripLeft(count - uint(labels))
+	nextCloser, _ := qname.StripLeft(count - uint(labels) - 1)
+	if isInZone(encloser, chain.zone) == false {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	covering := chain.cover(nextCloser)
+	if covering == nil {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	return &NSEC3Proof{
+		Type:            NSEC3_PROOF_WILDCARD,
+		ClosestEncloser: encloser,
+		NextCloser:      nextCloser,
+		OptOut:          covering.IsOptOut(),
+	}, nil
+}
+
+//referral without ds is insecure if nsec3 matching the delegation has ns
+//but no ds and soa, or the closest encloser proof has opt-out nsec3
+//covering the next closer name, rfc5155 section 8.9
+func ProveInsecureDelegation(delegation *Name, rrsets []*RRset) (*NSEC3Proof, error) {
+	chain, err := newNSEC3Chain(rrsets)
+	if err != nil {
+		return nil, err
+	}
+
+	if nsec3 := chain.match(delegation); nsec3 != nil {
+		if hasType(nsec3.Types, RR_NS) == false || hasType(nsec3.Types, RR_DS) ||
+			hasType(nsec3.Types, RR_SOA) {
+			return nil, ErrNSEC3ProofFailed
+		}
+		return &NSEC3Proof{
+			Type:            NSEC3_PROOF_INSECURE_DELEGATION,
+			ClosestEncloser: delegation,
+		}, nil
+	}
+
+	encloser, nextCloser, covering, err := chain.closestEncloser(delegation)
+	if err != nil {
+		return nil, err
+	}
+
+	if covering.IsOptOut() == false {
+		return nil, ErrNSEC3ProofFailed
+	}
+
+	return &NSEC3Proof{
+		Type:            NSEC3_PROOF_OPTOUT,
+		ClosestEncloser: encloser,
+		NextCloser:      nextCloser,
+		OptOut:          true,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package g53
+
+import (
+	"testing"
+)
+
+//zone and nsec3 chain from rfc5155 appendix a
+var rfc5155Chain = []string{
+	"0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.example. 3600 IN NSEC3 1 1 12 aabbccdd 2t7b4g4vsa5smi47k61mv5bv1a22bojr MX DNSKEY NS SOA NSEC3PARAM RRSIG",
+	"2t7b4g4vsa5smi47k61mv5bv1a22bojr.example. 3600 IN NSEC3 1 1 12 aabbccdd 2vptu5timamqttgl4luu9kg21e0aor3s A RRSIG",
+	"2vptu5timamqttgl4luu9kg21e0aor3s.example. 3600 IN NSEC3 1 1 12 aabbccdd 35mthgpgcu1qg68fab165klnsnk3dpvl MX RRSIG",
+	"35mthgpgcu1qg68fab165klnsnk3dpvl.example. 3600 IN NSEC3 1 1 12 aabbccdd b4um86eghhds6nea196smvmlo4ors995 NS DS RRSIG",
+	"b4um86eghhds6nea196smvmlo4ors995.example. 3600 IN NSEC3 1 1 12 aabbccdd gjeqe526plbf1g8mklp59enfd789njgi MX RRSIG",
+	"gjeqe526plbf1g8mklp59enfd789njgi.example. 3600 IN NSEC3 1 1 12 aabbccdd ji6neoaepv8b5o6k4ev33abha8ht9fgc HINFO A AAAA RRSIG",
+	"ji6neoaepv8b5o6k4ev33abha8ht9fgc.example. 3600 IN NSEC3 1 1 12 aabbccdd k8udemvp1j2f7eg6jebps17vp3n8i58h",
+	"k8udemvp1j2f7eg6jebps17vp3n8i58h.example. 3600 IN NSEC3 1 1 12 aabbccdd kohar7mbb8dc2ce8a9qvl8hon4k53uhi",
+	"kohar7mbb8dc2ce8a9qvl8hon4k53uhi.example. 3600 IN NSEC3 1 1 12 aabbccdd q04jkcevqvmu85r014c7dkba38o0ji5r A RRSIG",
+	"q04jkcevqvmu85r014c7dkba38o0ji5r.example. 3600 IN NSEC3 1 1 12 aabbccdd r53bq7cc2uvmubfu5ocmm6pers9tk9en A RRSIG",
+	"r53bq7cc2uvmubfu5ocmm6pers9tk9en.example. 3600 IN NSEC3 1 1 12 aabbccdd t644ebqk9bibcna874givr6joj62mlhv MX RRSIG",
+	"t644ebqk9bibcna874givr6joj62mlhv.example. 3600 IN NSEC3 1 1 12 aabbccdd 0p9mhaveqvm6t7vbl5lop2u3t2rp3tom A HINFO AAAA RRSIG",
+}
+
+func buildNSEC3s(t *testing.T, indexes ...int) []*RRset {
+	var rrsets []*RRset
+	for _, i := range indexes {
+		rrsets = append(rrsets, buildRRset(t, rfc5155Chain[i]))
+	}
+	return rrsets
+}
+
+func TestNSEC3Hash(t *testing.T) {
+	salt := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+	zone := NameFromStringUnsafe("example")
+	cases := []struct {
+		name  string
+		owner string
+	}{
+		{"example", "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom.example."},
+		{"a.example", "35mthgpgcu1qg68fab165klnsnk3dpvl.example."},
+		{"ai.example", "gjeqe526plbf1g8mklp59enfd789njgi.example."},
+		{"ns1.example", "2t7b4g4vsa5smi47k61mv5bv1a22bojr.example."},
+		{"ns2.example", "q04jkcevqvmu85r014c7dkba38o0ji5r.example."},
+		{"w.example", "k8udemvp1j2f7eg6jebps17vp3n8i58h.example."},
+		{"*.w.example", "r53bq7cc2uvmubfu5ocmm6pers9tk9en.example."},
+		{"x.w.example", "b4um86eghhds6nea196smvmlo4ors995.example."},
+		{"y.w.example", "ji6neoaepv8b5o6k4ev33abha8ht9fgc.example."},
+		{"x.y.w.example", "2vptu5timamqttgl4luu9kg21e0aor3s.example."},
+		{"xx.example", "t644ebqk9bibcna874givr6joj62mlhv.example."},
+		{"2t7b4g4vsa5smi47k61mv5bv1a22bojr.example", "kohar7mbb8dc2ce8a9qvl8hon4k53uhi.example."},
+		{"X.W.Example", "b4um86eghhds6nea196smvmlo4ors995.example."},
+	}
+
+	for _, c := range cases {
+		name, _ := NewName(c.name, false)
+		owner, err := NSEC3HashedOwner(name, zone, NSEC3_HASH_SHA1, 12, salt)
+		Assert(t, err == nil, "hash %s failed:%v", c.name, err)
+		Equal(t, owner.String(false), c.owner)
+	}
+
+	_, err := NSEC3Hash(zone, 2, 12, salt)
+	Equal(t, err, ErrUnsupportedNSEC3Hash)
+}
+
+func TestNSEC3Proof(t *testing.T) {
+	//rfc5155 appendix b.1, a.c.x.w.example doesn't exist
+	proof, err := ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), buildNSEC3s(t, 0, 3, 4))
+	Assert(t, err == nil, "nxdomain proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_NXDOMAIN)
+	Equal(t, proof.ClosestEncloser.String(false), "x.w.example.")
+	Equal(t, proof.NextCloser.String(false), "c.x.w.example.")
+	Assert(t, proof.OptOut, "nsec3 covering next closer is opt-out")
+
+	//without the nsec3 covering wildcard
+	_, err = ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), buildNSEC3s(t, 0, 4))
+	Equal(t, err, ErrNSEC3ProofFailed)
+	//without the nsec3 matching closest encloser
+	_, err = ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), buildNSEC3s(t, 0, 3))
+	Equal(t, err, ErrNoClosestEncloser)
+	_, err = ProveNXDomain(NameFromStringUnsafe("ns1.example"), buildNSEC3s(t, 1))
+	Equal(t, err, ErrNSEC3ProofFailed)
+
+	//rfc5155 appendix b.2 and b.2.1, nodata for ns1.example mx and
+	//empty non-terminal y.w.example
+	proof, err = ProveNoData(NameFromStringUnsafe("ns1.example"), RR_MX, buildNSEC3s(t, 1))
+	Assert(t, err == nil, "nodata proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_NODATA)
+	Equal(t, proof.ClosestEncloser.String(false), "ns1.example.")
+	_, err = ProveNoData(NameFromStringUnsafe("ns1.example"), RR_A, buildNSEC3s(t, 1))
+	Equal(t, err, ErrNSEC3ProofFailed)
+	proof, err = ProveNoData(NameFromStringUnsafe("y.w.example"), RR_A, buildNSEC3s(t, 6))
+	Assert(t, err == nil, "nodata proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_NODATA)
+
+	//rfc5155 appendix b.3, referral to unsigned c.example
+	proof, err = ProveInsecureDelegation(NameFromStringUnsafe("c.example"), buildNSEC3s(t, 0, 3))
+	Assert(t, err == nil, "opt-out proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_OPTOUT)
+	Equal(t, proof.ClosestEncloser.String(false), "example.")
+	Equal(t, proof.NextCloser.String(false), "c.example.")
+	//a.example has ds
+	_, err = ProveInsecureDelegation(NameFromStringUnsafe("a.example"), buildNSEC3s(t, 3))
+	Equal(t, err, ErrNSEC3ProofFailed)
+	//ds query for c.example gets the same proof
+	proof, err = ProveNoData(NameFromStringUnsafe("c.example"), RR_DS, buildNSEC3s(t, 0, 3))
+	Assert(t, err == nil, "opt-out proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_OPTOUT)
+
+	//rfc5155 appendix b.4, a.z.w.example mx expanded from *.w.example
+	proof, err = ProveWildcardAnswer(NameFromStringUnsafe("a.z.w.example"), 2, buildNSEC3s(t, 9))
+	Assert(t, err == nil, "wildcard proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_WILDCARD)
+	Equal(t, proof.ClosestEncloser.String(false), "w.example.")
+	Equal(t, proof.NextCloser.String(false), "z.w.example.")
+	_, err = ProveWildcardAnswer(NameFromStringUnsafe("a.z.w.example"), 2, buildNSEC3s(t, 0))
+	Equal(t, err, ErrNSEC3ProofFailed)
+	_, err = ProveWildcardAnswer(NameFromStringUnsafe("a.z.w.example"), 3, buildNSEC3s(t, 9))
+	Equal(t, err, ErrNSEC3ProofFailed)
+
+	//rfc5155 appendix b.5, a.z.w.example aaaa matches *.w.example
+	//which has no aaaa
+	proof, err = ProveNoData(NameFromStringUnsafe("a.z.w.example"), RR_AAAA, buildNSEC3s(t, 7, 9, 10))
+	Assert(t, err == nil, "wildcard nodata proof failed:%v", err)
+	Equal(t, proof.Type, NSEC3_PROOF_WILDCARD_NODATA)
+	Equal(t, proof.ClosestEncloser.String(false), "w.example.")
+	_, err = ProveNoData(NameFromStringUnsafe("a.z.w.example"), RR_MX, buildNSEC3s(t, 7, 9, 10))
+	Equal(t, err, ErrNSEC3ProofFailed)
+
+	//closest encloser can't be a delegation point
+	_, err = ProveNXDomain(NameFromStringUnsafe("b.a.example"), buildNSEC3s(t, 0, 2, 3))
+	Equal(t, err, ErrNoClosestEncloser)
+
+	mixed := buildNSEC3s(t, 0)
+	mixed = append(mixed, buildRRset(t, "35mthgpgcu1qg68fab165klnsnk3dpvl.example. 3600 IN NSEC3 1 1 10 aabbccdd b4um86eghhds6nea196smvmlo4ors995 NS DS RRSIG"))
+	_, err = ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), mixed)
+	Equal(t, err, ErrNSEC3ParamMismatch)
+	_, err = ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), nil)
+	Equal(t, err, ErrNoNSEC3)
+
+	//nsec3 at delegation doesn't prove nodata of types other than ds
+	_, err = ProveNoData(NameFromStringUnsafe("a.example"), RR_MX, buildNSEC3s(t, 3))
+	Equal(t, err, ErrNSEC3ProofFailed)
+
+	//iterations above the limit
+	tooMany := buildNSEC3s(t, 0, 3, 4)
+	tooMany[1].Rdatas[0].(*NSEC3).Iterations = NSEC3_MAX_ITERATIONS + 1
+	_, err = ProveNXDomain(NameFromStringUnsafe("a.c.x.w.example"), tooMany)
+	Equal(t, err, ErrNSEC3TooManyIters)
+	_, err = tooMany[1].Rdatas[0].(*NSEC3).HashName(NameFromStringUnsafe("a.example"))
+	Equal(t, err, ErrNSEC3TooManyIters)
+}
@@ -0,0 +1,324 @@
+package g53
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	mrand "math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var (
+	ErrKeyAlgorithmMismatch = errors.New("private key doesn't match the algorithm")
+	ErrInvalidSignPeriod    = errors.New("signature expiration should be later than inception")
+	ErrNoSigningKey         = errors.New("no key to sign the zone")
+)
+
+//key used to generate rrsig, the signer is the owner name of the dnskey
+type SigningKey struct {
+	Signer     *Name
+	PrivateKey crypto.Signer
+	dnskey     *DNSKey
+}
+
+func NewSigningKey(signer *Name, flags uint16, algorithm uint8, privKey crypto.Signer) (*SigningKey, error) {
+	dnskey, err := NewDNSKey(flags, algorithm, privKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		Signer:     signer,
+		PrivateKey: privKey,
+		dnskey:     dnskey,
+	}, nil
+}
+
+func (k *SigningKey) DNSKey() *DNSKey {
+	return k.dnskey
+}
+
+func (k *SigningKey) KeyTag() uint16 {
+	return k.dnskey.KeyTag()
+}
+
+//generate rrsig for the rrset, labels doesn't include the leading
+//wildcard label and original ttl is the ttl of the rrset
+func (k *SigningKey) Sign(rrset *RRset, inception, expiration time.Time) (*RRSig, error) {
+	if expiration.After(inception) == false {
+		return nil, ErrInvalidSignPeriod
+	}
+
+	if isInZone(&rrset.Name, k.Signer) == false {
+		return nil, ErrRRSigRRsetMismatch
+	}
+
+	labels := rrset.Name.LabelCount() - 1
+	if rrset.Name.IsWildCard() {
+		labels -= 1
+	}
+
+	rrsig := &RRSig{
+		Covered:     rrset.Type,
+		Algorithm:   k.dnskey.Algorithm,
+		Labels:      uint8(labels),
+		OriginalTtl: uint32(rrset.Ttl),
+		SigExpire:   uint32(expiration.Unix()),
+		Inception:   uint32(inception.Unix()),
+		Tag:         k.dnskey.KeyTag(),
+		Signer:      k.Signer,
+	}
+
+	data, err := rrsigSignedData(rrset, rrsig)
+	if err != nil {
+		return nil, err
+	}
+
+	rrsig.Signature, err = k.sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return rrsig, nil
+}
+
+func (k *SigningKey) sign(data []byte) ([]byte, error) {
+	h, err := algorithmHash(k.dnskey.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := k.PrivateKey.Sign(rand.Reader, digestData(h, data), h)
+	if err != nil {
+		return nil, err
+	}
+
+	//crypto.Signer generates asn1 encoded ecdsa signature, while dnssec
+	//uses r and s padded to the size of the curve
+	if pubKey, ok := k.PrivateKey.Public().(*ecdsa.PublicKey); ok {
+		var sig struct {
+			R, S *big.Int
+		}
+		if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+			return nil, err
+		}
+
+		size := (pubKey.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		sig.R.FillBytes(signature[:size])
+		sig.S.FillBytes(signature[size:])
+	}
+	return signature, nil
+}
+
+//convert rsa, ecdsa or ed25519 public key to dnskey
+func NewDNSKey(flags uint16, algorithm uint8, pubKey crypto.PublicKey) (*DNSKey, error) {
+	var data []byte
+	switch key := pubKey.(type) {
+	case *rsa.PublicKey:
+		switch algorithm {
+		case ALGORITHM_RSASHA1, ALGORITHM_RSASHA1_NSEC3_SHA1, ALGORITHM_RSASHA256, ALGORITHM_RSASHA512:
+			data = rsaPublicKeyToWire(key)
+		default:
+			return nil, ErrKeyAlgorithmMismatch
+		}
+	case *ecdsa.PublicKey:
+		if (algorithm == ALGORITHM_ECDSAP256SHA256 && key.Curve == elliptic.P256()) ||
+			(algorithm == ALGORITHM_ECDSAP384SHA384 && key.Curve == elliptic.P384()) {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			data = make([]byte, 2*size)
+			key.X.FillBytes(data[:size])
+			key.Y.FillBytes(data[size:])
+		} else {
+			return nil, ErrKeyAlgorithmMismatch
+		}
+	case ed25519.PublicKey:
+		if algorithm != ALGORITHM_ED25519 {
+			return nil, ErrKeyAlgorithmMismatch
+		}
+		data = append([]byte(nil), key...)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	return &DNSKey{
+		Flags:     flags,
+		Protocol:  DNSKEY_PROTOCOL,
+		Algorithm: algorithm,
+		PublicKey: data,
+	}, nil
+}
+
+func rsaPublicKeyToWire(key *rsa.PublicKey) []byte {
+	exp := big.NewInt(int64(key.E)).Bytes()
+	modulus := key.N.Bytes()
+	buf := util.NewOutputBuffer(uint(3 + len(exp) + len(modulus)))
+	if len(exp) < 256 {
+		buf.WriteUint8(uint8(len(exp)))
+	} else {
+		buf.WriteUint8(0)
+		buf.WriteUint16(uint16(len(exp)))
+	}
+	buf.WriteData(exp)
+	buf.WriteData(modulus)
+	return buf.Data()
+}
+
+//expiration of each rrsig is moved earlier by a random duration less than
+//jitter, so the signatures won't expire at the same time
+type ZoneSignConfig struct {
+	Inception  time.Time
+	Expiration time.Time
+	Jitter     time.Duration
+	Workers    int
+}
+
+//sign all the authoritative rrsets in the zone, rrsig is excluded and
+//delegation point only has ds and nsec signed, glue and names below dname
+//aren't signed. keys with sep flag sign dnskey rrset and the others sign
+//the rest, if only one kind of key is provided it signs everything.
+//return one rrsig rrset for each authoritative rrset, in the order they
+//appear in input with the unsigned rrsets skipped, so the rrsig should be
+//matched with rrset by its owner name and covered type
+func SignZone(origin *Name, rrsets []*RRset, keys []*SigningKey, conf ZoneSignConfig) ([]*RRset, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	} else if conf.Expiration.Add(-conf.Jitter).After(conf.Inception) == false {
+		return nil, ErrInvalidSignPeriod
+	}
+
+	var ksks, zsks []*SigningKey
+	for _, key := range keys {
+		if key.dnskey.IsSEP() {
+			ksks = append(ksks, key)
+		} else {
+			zsks = append(zsks, key)
+		}
+	}
+	if len(ksks) == 0 {
+		ksks = zsks
+	} else if len(zsks) == 0 {
+		zsks = ksks
+	}
+
+	toSign := authoritativeRRsets(origin, rrsets)
+	sigs := make([]*RRset, len(toSign))
+	workers := conf.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var signErr error
+	indexes := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				signers := zsks
+				if toSign[i].Type == RR_DNSKEY {
+					signers = ksks
+				}
+
+				sig, err := signRRset(toSign[i], signers, conf)
+				if err != nil {
+					once.Do(func() { signErr = err })
+					continue
+				}
+				sigs[i] = sig
+			}
+		}()
+	}
+
+	for i := range toSign {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if signErr != nil {
+		return nil, signErr
+	}
+	return sigs, nil
+}
+
+func signRRset(rrset *RRset, keys []*SigningKey, conf ZoneSignConfig) (*RRset, error) {
+	sigs := &RRset{
+		Name:  rrset.Name.Clone(),
+		Type:  RR_RRSIG,
+		Class: rrset.Class,
+		Ttl:   rrset.Ttl,
+	}
+
+	for _, key := range keys {
+		expiration := conf.Expiration
+		//rrsig time is in seconds, so is the jitter
+		if seconds := int64(conf.Jitter / time.Second); seconds > 0 {
+			expiration = expiration.Add(-time.Duration(mrand.Int63n(seconds)) * time.Second)
+		}
+
+		rrsig, err := key.Sign(rrset, conf.Inception, expiration)
+		if err != nil {
+			return nil, err
+		}
+		sigs.Rdatas = append(sigs.Rdatas, rrsig)
+	}
+	return sigs, nil
+}
+
+//names below delegation point or dname owner aren't authoritative, rfc6672
+//section 2.3, while the dname owner itself is
+func authoritativeRRsets(origin *Name, rrsets []*RRset) []*RRset {
+	var cuts, dnames []*Name
+	for _, rrset := range rrsets {
+		if isInZone(&rrset.Name, origin) == false {
+			continue
+		} else if rrset.Type == RR_NS && rrset.Name.Equals(origin) == false {
+			cuts = append(cuts, &rrset.Name)
+		} else if rrset.Type == RR_DNAME {
+			dnames = append(dnames, &rrset.Name)
+		}
+	}
+
+	var authoritative []*RRset
+	for _, rrset := range rrsets {
+		if rrset.Type == RR_RRSIG || len(rrset.Rdatas) == 0 || isInZone(&rrset.Name, origin) == false {
+			continue
+		}
+
+		occluded := false
+		for _, cut := range cuts {
+			if rrset.Name.Equals(cut) {
+				occluded = rrset.Type != RR_DS && rrset.Type != RR_NSEC
+			} else if isInZone(&rrset.Name, cut) {
+				occluded = true
+			}
+
+			if occluded {
+				break
+			}
+		}
+
+		for _, dname := range dnames {
+			if occluded == false && rrset.Name.Equals(dname) == false && isInZone(&rrset.Name, dname) {
+				occluded = true
+			}
+		}
+
+		if occluded == false {
+			authoritative = append(authoritative, rrset)
+		}
+	}
+	return authoritative
+}
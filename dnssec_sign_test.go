@@ -0,0 +1,152 @@
+package g53
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+)
+
+func TestSignRRset(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	signer := NameFromStringUnsafe("example.com.")
+	inception := time.Unix(1600000000, 0)
+	expiration := inception.Add(30 * 24 * time.Hour)
+	now := inception.Add(time.Hour)
+	rrset := buildRRset(t, "www.example.com. 300 IN A 192.0.2.1", "www.example.com. 300 IN A 192.0.2.2")
+	wildcard := buildRRset(t, "*.example.com. 600 IN MX 10 Mail.Example.com.")
+	expanded := buildRRset(t, "a.b.example.com. 60 IN MX 10 mail.example.com.")
+
+	for _, c := range []struct {
+		algorithm uint8
+		key       crypto.Signer
+	}{
+		{ALGORITHM_RSASHA1, rsaKey},
+		{ALGORITHM_RSASHA1_NSEC3_SHA1, rsaKey},
+		{ALGORITHM_RSASHA256, rsaKey},
+		{ALGORITHM_RSASHA512, rsaKey},
+		{ALGORITHM_ECDSAP256SHA256, p256Key},
+		{ALGORITHM_ECDSAP384SHA384, p384Key},
+		{ALGORITHM_ED25519, edKey},
+	} {
+		key, err := NewSigningKey(signer, DNSKEY_FLAG_ZONE, c.algorithm, c.key)
+		Assert(t, err == nil, "create signing key failed:%v", err)
+		dnskey := key.DNSKey()
+		Equal(t, dnskey.Algorithm, c.algorithm)
+
+		//dnskey can be converted back to the same public key
+		pubKey, err := dnskey.CryptoPublicKey()
+		Assert(t, err == nil, "convert dnskey failed:%v", err)
+		Assert(t, pubKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(c.key.Public()), "public key should be same")
+
+		rrsig, err := key.Sign(rrset, inception, expiration)
+		Assert(t, err == nil, "sign failed:%v", err)
+		Equal(t, rrsig.Labels, uint8(3))
+		Equal(t, rrsig.OriginalTtl, uint32(300))
+		Equal(t, rrsig.Tag, dnskey.KeyTag())
+		Equal(t, rrsig.Inception, uint32(1600000000))
+		Equal(t, rrsig.SigExpire, uint32(expiration.Unix()))
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), nil)
+
+		rrsig, err = key.Sign(wildcard, inception, expiration)
+		Assert(t, err == nil, "sign failed:%v", err)
+		Equal(t, rrsig.Labels, uint8(2))
+		Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, now), nil)
+		Equal(t, VerifyRRSigAt(expanded, rrsig, dnskey, now), nil)
+	}
+
+	_, err := NewSigningKey(signer, DNSKEY_FLAG_ZONE, ALGORITHM_ECDSAP384SHA384, p256Key)
+	Equal(t, err, ErrKeyAlgorithmMismatch)
+	_, err = NewSigningKey(signer, DNSKEY_FLAG_ZONE, ALGORITHM_ED25519, rsaKey)
+	Equal(t, err, ErrKeyAlgorithmMismatch)
+	_, err = NewSigningKey(signer, DNSKEY_FLAG_ZONE, ALGORITHM_RSAMD5, rsaKey)
+	Equal(t, err, ErrKeyAlgorithmMismatch)
+
+	key, _ := NewSigningKey(signer, DNSKEY_FLAG_ZONE, ALGORITHM_ED25519, edKey)
+	_, err = key.Sign(rrset, expiration, inception)
+	Equal(t, err, ErrInvalidSignPeriod)
+	_, err = key.Sign(buildRRset(t, "www.example.org. 300 IN A 192.0.2.1"), inception, expiration)
+	Equal(t, err, ErrRRSigRRsetMismatch)
+}
+
+func TestSignZone(t *testing.T) {
+	origin := NameFromStringUnsafe("example.com.")
+	_, kskKey, _ := ed25519.GenerateKey(rand.Reader)
+	zskKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ksk, _ := NewSigningKey(origin, DNSKEY_FLAG_ZONE|DNSKEY_FLAG_SEP, ALGORITHM_ED25519, kskKey)
+	zsk, _ := NewSigningKey(origin, DNSKEY_FLAG_ZONE, ALGORITHM_ECDSAP256SHA256, zskKey)
+
+	zone := []*RRset{
+		buildRRset(t, "example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 1800 900 604800 86400"),
+		buildRRset(t, "example.com. 3600 IN NS ns1.example.com."),
+		buildRRset(t, "example.com. 3600 IN DNSKEY "+ksk.DNSKey().String(), "example.com. 3600 IN DNSKEY "+zsk.DNSKey().String()),
+		buildRRset(t, "ns1.example.com. 3600 IN A 192.0.2.1"),
+		buildRRset(t, "sub.example.com. 3600 IN NS ns.sub.example.com."),
+		buildRRset(t, "sub.example.com. 3600 IN DS 12345 13 2 2bb183af5f22588179a53b0a98631fad1a292118"),
+		buildRRset(t, "ns.sub.example.com. 3600 IN A 192.0.2.2"),
+		buildRRset(t, "www.example.com. 300 IN A 192.0.2.3"),
+		buildRRset(t, "www.example.com. 300 IN RRSIG A 13 3 300 20300101000000 20200101000000 1 example.com. AQID"),
+		buildRRset(t, "www.example.org. 300 IN A 192.0.2.4"),
+		buildRRset(t, "old.example.com. 300 IN DNAME example.net."),
+		buildRRset(t, "www.old.example.com. 300 IN A 192.0.2.5"),
+	}
+
+	inception := time.Unix(1600000000, 0)
+	conf := ZoneSignConfig{
+		Inception:  inception,
+		Expiration: inception.Add(30 * 24 * time.Hour),
+		Jitter:     24 * time.Hour,
+		Workers:    3,
+	}
+	sigs, err := SignZone(origin, zone, []*SigningKey{ksk, zsk}, conf)
+	Assert(t, err == nil, "sign zone failed:%v", err)
+
+	//soa, apex ns, dnskey, ns1 a, delegation ds, www a and dname, name
+	//below dname isn't signed
+	signed := []*RRset{zone[0], zone[1], zone[2], zone[3], zone[5], zone[7], zone[10]}
+	Equal(t, len(sigs), len(signed))
+	now := inception.Add(time.Hour)
+	for i, rrset := range signed {
+		sig := sigs[i]
+		Assert(t, sig.Name.Equals(&rrset.Name), "rrsig owner should be %s", rrset.Name.String(false))
+		Equal(t, sig.Type, RR_RRSIG)
+		Equal(t, sig.Ttl, rrset.Ttl)
+		Equal(t, len(sig.Rdatas), 1)
+
+		rrsig := sig.Rdatas[0].(*RRSig)
+		key := zsk
+		if rrset.Type == RR_DNSKEY {
+			key = ksk
+		}
+		Equal(t, VerifyRRSigAt(rrset, rrsig, key.DNSKey(), now), nil)
+
+		expiration := time.Unix(int64(rrsig.SigExpire), 0)
+		Assert(t, expiration.Before(conf.Expiration.Add(-conf.Jitter)) == false && expiration.After(conf.Expiration) == false,
+			"expiration %v isn't in jitter range", expiration)
+	}
+
+	//single key signs everything
+	sigs, err = SignZone(origin, zone, []*SigningKey{zsk}, ZoneSignConfig{Inception: conf.Inception, Expiration: conf.Expiration})
+	Assert(t, err == nil, "sign zone failed:%v", err)
+	Equal(t, VerifyRRSigAt(zone[2], sigs[2].Rdatas[0].(*RRSig), zsk.DNSKey(), now), nil)
+	Equal(t, sigs[2].Rdatas[0].(*RRSig).SigExpire, uint32(conf.Expiration.Unix()))
+
+	//jitter less than one second has no effect
+	sigs, err = SignZone(origin, zone, []*SigningKey{zsk}, ZoneSignConfig{Inception: conf.Inception, Expiration: conf.Expiration, Jitter: time.Second / 2})
+	Assert(t, err == nil, "sign zone failed:%v", err)
+	Equal(t, sigs[2].Rdatas[0].(*RRSig).SigExpire, uint32(conf.Expiration.Unix()))
+
+	_, err = SignZone(origin, zone, nil, conf)
+	Equal(t, err, ErrNoSigningKey)
+	conf.Jitter = 31 * 24 * time.Hour
+	_, err = SignZone(origin, zone, []*SigningKey{zsk}, conf)
+	Equal(t, err, ErrInvalidSignPeriod)
+}
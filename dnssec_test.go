@@ -0,0 +1,178 @@
+package g53
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func buildRRset(t *testing.T, ss ...string) *RRset {
+	rrset, err := RRsetFromStrings(ss)
+	Assert(t, err == nil, "parse rrset %v failed:%v", ss, err)
+	return rrset
+}
+
+func buildRRSig(t *testing.T, s string) *RRSig {
+	rrset := buildRRset(t, s)
+	return rrset.Rdatas[0].(*RRSig)
+}
+
+func buildDNSKey(t *testing.T, s string) *DNSKey {
+	rrset := buildRRset(t, s)
+	return rrset.Rdatas[0].(*DNSKey)
+}
+
+func TestVerifyRRSigVectors(t *testing.T) {
+	cases := []struct {
+		dnskey string
+		rrset  []string
+		rrsig  string
+	}{
+		//rfc5702 section 6.1
+		{
+			"example.net. 3600 IN DNSKEY 256 3 8 AwEAAcFcGsaxxdgiuuGmCkVImy4h99CqT7jwY3pexPGcnUFtR2Fh36BponcwtkZ4cAgtvd4Qs8PkxUdp6p/DlUmObdk=",
+			[]string{"www.example.net. 3600 IN A 192.0.2.91"},
+			"www.example.net. 3600 IN RRSIG A 8 3 3600 20300101000000 20000101000000 9033 example.net. kRCOH6u7l0QGy9qpC9l1sLncJcOKFLJ7GhiUOibu4teYp5VE9RncriShZNz85mwlMgNEacFYK/lPtPiVYP4bwg==",
+		},
+		//rfc6605 section 6.1
+		{
+			"example.net. 3600 IN DNSKEY 257 3 13 GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA==",
+			[]string{"www.example.net. 3600 IN A 192.0.2.1"},
+			"www.example.net. 3600 IN RRSIG A 13 3 3600 20100909100439 20100812100439 55648 example.net. qx6wLYqmh+l9oCKTN6qIc+bw6ya+KJ8oMz0YP107epXAyGmt+3SNruPFKG7tZoLBLlUzGGus7ZwmwWep666VCw==",
+		},
+		//rfc8080 section 6.1
+		{
+			"example.com. 3600 IN DNSKEY 257 3 15 l02Woi0iS8Aa25FQkUd9RMzZHJpBoRQwAQEX1SxZJA4=",
+			[]string{"example.com. 3600 IN MX 10 mail.example.com."},
+			"example.com. 3600 IN RRSIG MX 15 2 3600 1440021600 1438207200 3613 example.com. oL9krJun7xfBOIWcGHi7mag5/hdZrKWw15jPGrHpjQeRAvTdszaPD+QLs3fx8A4M3e23mRZ9VrbpMngwcrqNAg==",
+		},
+	}
+
+	for _, c := range cases {
+		dnskey := buildDNSKey(t, c.dnskey)
+		rrset := buildRRset(t, c.rrset...)
+		rrsig := buildRRSig(t, c.rrsig)
+		Equal(t, dnskey.KeyTag(), rrsig.Tag)
+
+		now := time.Unix(int64(rrsig.Inception)+1, 0)
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), nil)
+
+		//owner name case and ttl of the rrset don't matter
+		rrset.Name = *NameFromStringUnsafe(strings.ToUpper(rrset.Name.String(false)))
+		rrset.Ttl = 1
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), nil)
+
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, time.Unix(int64(rrsig.Inception)-1, 0)), ErrRRSigNotIncepted)
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, time.Unix(int64(rrsig.SigExpire)+1, 0)), ErrRRSigExpired)
+
+		signature := rrsig.Signature
+		rrsig.Signature = append([]byte(nil), signature...)
+		rrsig.Signature[len(signature)/2] ^= 0xff
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), ErrInvalidSignature)
+		rrsig.Signature = signature
+
+		rrsig.OriginalTtl += 1
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), ErrInvalidSignature)
+		rrsig.OriginalTtl -= 1
+
+		rrset.Type = RR_AAAA
+		Equal(t, VerifyRRSigAt(rrset, rrsig, dnskey, now), ErrRRSigRRsetMismatch)
+	}
+}
+
+func TestRRSigSignerUncompressed(t *testing.T) {
+	rrsig := buildRRset(t, "example.com. 3600 IN RRSIG MX 15 2 3600 1440021600 1438207200 3613 example.com. oL9krJun7xfBOIWcGHi7mag5/hdZrKWw15jPGrHpjQeRAvTdszaPD+QLs3fx8A4M3e23mRZ9VrbpMngwcrqNAg==")
+	render := NewMsgRender()
+	rrsig.Rend(render)
+
+	//signer is same with owner but shouldn't be compressed, rfc4034 section 3.1.7
+	signer := util.NewOutputBuffer(16)
+	NameFromStringUnsafe("example.com.").ToWire(signer)
+	signed := append(signer.Data(), rrsig.Rdatas[0].(*RRSig).Signature...)
+	Assert(t, bytes.HasSuffix(render.Data(), signed), "signer should be rendered without compression")
+
+	parsed, err := RRsetFromWire(util.NewInputBuffer(render.Data()))
+	Assert(t, err == nil, "parse rendered rrsig failed:%v", err)
+	Equal(t, parsed.Rdatas[0].Compare(rrsig.Rdatas[0]), 0)
+}
+
+func TestVerifyRRSigWildcard(t *testing.T) {
+	privKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	dnskey := &DNSKey{
+		Flags:     DNSKEY_FLAG_ZONE,
+		Protocol:  DNSKEY_PROTOCOL,
+		Algorithm: ALGORITHM_ED25519,
+		PublicKey: []byte(privKey.Public().(ed25519.PublicKey)),
+	}
+
+	wildcard := buildRRset(t, "*.example.com. 300 IN TXT \"hello\"", "*.example.com. 300 IN TXT \"a\"")
+	rrsig := &RRSig{
+		Covered:     RR_TXT,
+		Algorithm:   ALGORITHM_ED25519,
+		Labels:      2,
+		OriginalTtl: 300,
+		SigExpire:   2000000000,
+		Inception:   1000000000,
+		Tag:         dnskey.KeyTag(),
+		Signer:      NameFromStringUnsafe("example.com."),
+	}
+	data, err := rrsigSignedData(wildcard, rrsig)
+	Assert(t, err == nil, "build signed data failed:%v", err)
+	rrsig.Signature = ed25519.Sign(privKey, data)
+
+	now := time.Unix(1500000000, 0)
+	Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, now), nil)
+
+	//rrset synthesized from wildcard with rdata in other order
+	expanded := buildRRset(t, "a.b.example.com. 300 IN TXT \"a\"", "a.b.example.com. 300 IN TXT \"hello\"")
+	Equal(t, VerifyRRSigAt(expanded, rrsig, dnskey, now), nil)
+
+	rrsig.Labels = 3
+	Equal(t, VerifyRRSigAt(expanded, rrsig, dnskey, now), ErrInvalidSignature)
+	rrsig.Labels = 5
+	Equal(t, VerifyRRSigAt(expanded, rrsig, dnskey, now), ErrRRSigRRsetMismatch)
+	rrsig.Labels = 2
+
+	other := buildRRset(t, "a.b.example.org. 300 IN TXT \"a\"", "a.b.example.org. 300 IN TXT \"hello\"")
+	Equal(t, VerifyRRSigAt(other, rrsig, dnskey, now), ErrRRSigRRsetMismatch)
+
+	//inception and expiration wrap around 2^32
+	rrsig.Inception = 0xfffffff0
+	rrsig.SigExpire = 0x10
+	data, _ = rrsigSignedData(wildcard, rrsig)
+	rrsig.Signature = ed25519.Sign(privKey, data)
+	Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, time.Unix(0x100000000, 0)), nil)
+	Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, time.Unix(0x100000011, 0)), ErrRRSigExpired)
+
+	dnskey.Flags = 0
+	Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, time.Unix(0x100000000, 0)), ErrRRSigKeyMismatch)
+	dnskey.Flags = DNSKEY_FLAG_ZONE
+	dnskey.Algorithm = ALGORITHM_ECDSAP256SHA256
+	Equal(t, VerifyRRSigAt(wildcard, rrsig, dnskey, time.Unix(0x100000000, 0)), ErrRRSigKeyMismatch)
+}
+
+func TestDNSKeyCryptoPublicKey(t *testing.T) {
+	for _, c := range []struct {
+		algorithm uint8
+		key       string
+		err       error
+	}{
+		{ALGORITHM_RSASHA256, "AwEAAcFcGsaxxdgiuuGmCkVImy4h99CqT7jwY3pexPGcnUFtR2Fh36BponcwtkZ4cAgtvd4Qs8PkxUdp6p/DlUmObdk=", nil},
+		{ALGORITHM_RSASHA256, "AwEAAQ==", ErrInvalidDNSKey},
+		{ALGORITHM_RSASHA256, "AA==", ErrInvalidDNSKey},
+		{ALGORITHM_ECDSAP256SHA256, "GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edb", ErrInvalidDNSKey},
+		{ALGORITHM_ED25519, "l02Woi0iS8Aa25FQkUd9RMzZHJpBoRQwAQEX1SxZ", ErrInvalidDNSKey},
+		{ALGORITHM_RSAMD5, "AwEAAQ==", ErrUnsupportedAlgorithm},
+		{ALGORITHM_ED448, "AwEAAQ==", ErrUnsupportedAlgorithm},
+	} {
+		dnskey, err := DNSKeyFromString(fmt.Sprintf("257 3 %d %s", c.algorithm, c.key))
+		Assert(t, err == nil, "parse dnskey failed:%v", err)
+		_, err = dnskey.CryptoPublicKey()
+		Equal(t, err, c.err)
+	}
+}
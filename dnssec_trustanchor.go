@@ -0,0 +1,400 @@
+package g53
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//hold-down time defined in rfc5011 section 2.4.1 and 2.4.2
+const (
+	RFC5011_ADD_HOLD_DOWN    = 30 * 24 * time.Hour
+	RFC5011_REMOVE_HOLD_DOWN = 30 * 24 * time.Hour
+)
+
+var (
+	ErrTrustAnchorZoneMismatch = errors.New("dnskey rrset doesn't belong to the trust anchor zone")
+	ErrTrustAnchorNotVerified  = errors.New("dnskey rrset isn't signed by trusted key")
+	ErrInvalidTrustAnchorFile  = errors.New("trust anchor file isn't valid")
+)
+
+//key states defined in rfc5011 section 4, key in start state isn't kept
+type TrustAnchorState int
+
+const (
+	TRUST_ANCHOR_ADDPEND TrustAnchorState = iota + 1
+	TRUST_ANCHOR_VALID
+	TRUST_ANCHOR_MISSING
+	TRUST_ANCHOR_REVOKED
+	TRUST_ANCHOR_REMOVED
+)
+
+var trustAnchorStateNames = map[TrustAnchorState]string{
+	TRUST_ANCHOR_ADDPEND: "addpend",
+	TRUST_ANCHOR_VALID:   "valid",
+	TRUST_ANCHOR_MISSING: "missing",
+	TRUST_ANCHOR_REVOKED: "revoked",
+	TRUST_ANCHOR_REMOVED: "removed",
+}
+
+func (s TrustAnchorState) String() string {
+	if name, ok := trustAnchorStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func trustAnchorStateFromString(s string) (TrustAnchorState, error) {
+	for state, name := range trustAnchorStateNames {
+		if name == s {
+			return state, nil
+		}
+	}
+	return 0, ErrInvalidTrustAnchorFile
+}
+
+//timer is the end of add hold-down for addpend key and the end of remove
+//hold-down for revoked key, key is saved without revoke bit
+type ManagedKey struct {
+	Key         *DNSKey
+	State       TrustAnchorState
+	FirstSeen   time.Time
+	LastChanged time.Time
+	Timer       time.Time
+}
+
+//trust anchor of a zone maintained as rfc5011, keys with zone and sep
+//flag in the dnskey rrset are tracked
+type ManagedTrustAnchor struct {
+	Zone           *Name
+	AddHoldDown    time.Duration
+	RemoveHoldDown time.Duration
+
+	lock  sync.Mutex
+	keys  []*ManagedKey
+	clock func() time.Time
+}
+
+//initial keys are valid, clock is time.Now if it's nil
+func NewManagedTrustAnchor(zone *Name, keys []*DNSKey, clock func() time.Time) *ManagedTrustAnchor {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	a := &ManagedTrustAnchor{
+		Zone:           zone,
+		AddHoldDown:    RFC5011_ADD_HOLD_DOWN,
+		RemoveHoldDown: RFC5011_REMOVE_HOLD_DOWN,
+		clock:          clock,
+	}
+
+	now := clock()
+	for _, key := range keys {
+		a.keys = append(a.keys, &ManagedKey{
+			Key:         unrevokedKey(key),
+			State:       TRUST_ANCHOR_VALID,
+			FirstSeen:   now,
+			LastChanged: now,
+		})
+	}
+	return a
+}
+
+func unrevokedKey(key *DNSKey) *DNSKey {
+	k := *key
+	k.Flags &^= DNSKEY_FLAG_REVOKE
+	return &k
+}
+
+//copy of the keys with their states
+func (a *ManagedTrustAnchor) Keys() []ManagedKey {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	keys := make([]ManagedKey, 0, len(a.keys))
+	for _, key := range a.keys {
+		keys = append(keys, *key)
+	}
+	return keys
+}
+
+//valid and missing keys are trusted
+func (a *ManagedTrustAnchor) TrustedKeys() []*DNSKey {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.trustedKeys()
+}
+
+func (a *ManagedTrustAnchor) trustedKeys() []*DNSKey {
+	var keys []*DNSKey
+	for _, key := range a.keys {
+		if key.State == TRUST_ANCHOR_VALID || key.State == TRUST_ANCHOR_MISSING {
+			keys = append(keys, key.Key)
+		}
+	}
+	return keys
+}
+
+//trust anchor used by validator
+func (a *ManagedTrustAnchor) TrustAnchor() *TrustAnchor {
+	return &TrustAnchor{
+		Zone:    a.Zone,
+		DNSKeys: a.TrustedKeys(),
+	}
+}
+
+func (a *ManagedTrustAnchor) findKey(key *DNSKey) *ManagedKey {
+	for _, k := range a.keys {
+		if k.Key.Compare(key) == 0 {
+			return k
+		}
+	}
+	return nil
+}
+
+//update key states with the dnskey rrset of the zone, the rrset should be
+//signed by one of the trusted keys, otherwise only the revocations in it
+//are handled. revoked key must sign the rrset by itself, rfc5011 section
+//2.1, and the self signature is enough since the key has a different key
+//tag after revoke bit is set
+func (a *ManagedTrustAnchor) Update(rrset *RRset, sigs []*RRSig) error {
+	if rrset.Type != RR_DNSKEY || rrset.Name.Equals(a.Zone) == false {
+		return ErrTrustAnchorZoneMismatch
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.clock()
+	sig, err := verifyRRsetWithKeys(rrset, sigs, a.Zone, a.trustedKeys(), now)
+	if err != nil {
+		revoked := false
+		for _, rdata := range rrset.Rdatas {
+			key := rdata.(*DNSKey)
+			if key.IsZoneKey() && key.IsSEP() && key.IsRevoked() {
+				if managed := a.findKey(unrevokedKey(key)); managed != nil && a.revoke(managed, key, rrset, sigs, now) {
+					revoked = true
+				}
+			}
+		}
+		if revoked == false {
+			return ErrTrustAnchorNotVerified
+		}
+		return nil
+	}
+
+	addHoldDown := a.AddHoldDown
+	if ttl := time.Duration(sig.OriginalTtl) * time.Second; ttl > addHoldDown {
+		addHoldDown = ttl
+	}
+
+	seen := make(map[*ManagedKey]bool)
+	for _, rdata := range rrset.Rdatas {
+		key := rdata.(*DNSKey)
+		if key.IsZoneKey() == false || key.IsSEP() == false {
+			continue
+		}
+
+		managed := a.findKey(unrevokedKey(key))
+		if key.IsRevoked() {
+			if managed == nil {
+				continue
+			}
+			seen[managed] = true
+			a.revoke(managed, key, rrset, sigs, now)
+			continue
+		}
+
+		if managed == nil {
+			managed = &ManagedKey{
+				Key:       key,
+				State:     TRUST_ANCHOR_ADDPEND,
+				FirstSeen: now,
+				Timer:     now.Add(addHoldDown),
+			}
+			managed.LastChanged = now
+			a.keys = append(a.keys, managed)
+		}
+		seen[managed] = true
+
+		switch managed.State {
+		case TRUST_ANCHOR_ADDPEND:
+			if now.Before(managed.Timer) == false {
+				managed.setState(TRUST_ANCHOR_VALID, now)
+			}
+		case TRUST_ANCHOR_MISSING:
+			managed.setState(TRUST_ANCHOR_VALID, now)
+		}
+	}
+
+	keys := a.keys[:0]
+	for _, key := range a.keys {
+		if seen[key] == false {
+			switch key.State {
+			case TRUST_ANCHOR_ADDPEND:
+				continue
+			case TRUST_ANCHOR_VALID:
+				key.setState(TRUST_ANCHOR_MISSING, now)
+			}
+		}
+
+		if key.State == TRUST_ANCHOR_REVOKED && now.Before(key.Timer) == false {
+			key.setState(TRUST_ANCHOR_REMOVED, now)
+		}
+		keys = append(keys, key)
+	}
+	a.keys = keys
+	return nil
+}
+
+//revoke the key if the rrset is signed by its revoked form, valid and
+//missing key becomes revoked, addpend key goes back to start state and is
+//forgotten since there is no transition from addpend to revoked
+func (a *ManagedTrustAnchor) revoke(managed *ManagedKey, revoked *DNSKey, rrset *RRset, sigs []*RRSig, now time.Time) bool {
+	if managed.State != TRUST_ANCHOR_ADDPEND && managed.State != TRUST_ANCHOR_VALID &&
+		managed.State != TRUST_ANCHOR_MISSING {
+		return false
+	}
+
+	if _, err := verifyRRsetWithKeys(rrset, sigs, a.Zone, []*DNSKey{revoked}, now); err != nil {
+		return false
+	}
+
+	if managed.State == TRUST_ANCHOR_ADDPEND {
+		keys := a.keys[:0]
+		for _, key := range a.keys {
+			if key != managed {
+				keys = append(keys, key)
+			}
+		}
+		a.keys = keys
+	} else {
+		managed.setState(TRUST_ANCHOR_REVOKED, now)
+		managed.Timer = now.Add(a.RemoveHoldDown)
+	}
+	return true
+}
+
+func (k *ManagedKey) setState(state TrustAnchorState, now time.Time) {
+	k.State = state
+	k.LastChanged = now
+	k.Timer = time.Time{}
+}
+
+//zone is saved first so anchor without keys could be loaded, then each
+//key is saved as a dnskey rr followed by its state and times
+//$ORIGIN example.
+//example. 0 IN DNSKEY 257 3 8 AwEAAb... ;valid;first-seen;last-changed;timer
+func (a *ManagedTrustAnchor) Save(path string) error {
+	a.lock.Lock()
+	var buf bytes.Buffer
+	buf.WriteString(anchorOriginDirective + " " + a.Zone.String(false) + "\n")
+	for _, key := range a.keys {
+		buf.WriteString(strings.Join([]string{
+			a.Zone.String(false), "0", CLASS_IN.String(), RR_DNSKEY.String(), key.Key.String(),
+		}, " "))
+		buf.WriteString(" ;")
+		buf.WriteString(strings.Join([]string{
+			key.State.String(), formatAnchorTime(key.FirstSeen), formatAnchorTime(key.LastChanged), formatAnchorTime(key.Timer),
+		}, ";"))
+		buf.WriteString("\n")
+	}
+	a.lock.Unlock()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func formatAnchorTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseAnchorTime(s string) (time.Time, error) {
+	if s == "-" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+const anchorOriginDirective = "$ORIGIN"
+
+func LoadManagedTrustAnchor(path string, clock func() time.Time) (*ManagedTrustAnchor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var a *ManagedTrustAnchor
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, anchorOriginDirective) {
+			zone, err := NameFromString(strings.TrimSpace(strings.TrimPrefix(line, anchorOriginDirective)))
+			if err != nil {
+				return nil, err
+			} else if a != nil {
+				return nil, ErrInvalidTrustAnchorFile
+			}
+			a = NewManagedTrustAnchor(zone, nil, clock)
+			continue
+		}
+
+		i := strings.Index(line, ";")
+		if i == -1 {
+			return nil, ErrInvalidTrustAnchorFile
+		}
+
+		rrset, err := RRsetFromString(line[:i])
+		if err != nil {
+			return nil, err
+		} else if rrset.Type != RR_DNSKEY {
+			return nil, ErrInvalidTrustAnchorFile
+		}
+
+		if a == nil {
+			zone := rrset.Name.Clone()
+			a = NewManagedTrustAnchor(&zone, nil, clock)
+		} else if a.Zone.Equals(&rrset.Name) == false {
+			return nil, ErrTrustAnchorZoneMismatch
+		}
+
+		fields := strings.Split(line[i+1:], ";")
+		if len(fields) != 4 {
+			return nil, ErrInvalidTrustAnchorFile
+		}
+
+		key := &ManagedKey{Key: rrset.Rdatas[0].(*DNSKey)}
+		if key.State, err = trustAnchorStateFromString(fields[0]); err != nil {
+			return nil, err
+		}
+		for j, t := range []*time.Time{&key.FirstSeen, &key.LastChanged, &key.Timer} {
+			if *t, err = parseAnchorTime(fields[j+1]); err != nil {
+				return nil, fmt.Errorf("invalid time %s in trust anchor file", fields[j+1])
+			}
+		}
+		a.keys = append(a.keys, key)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	} else if a == nil {
+		return nil, ErrInvalidTrustAnchorFile
+	}
+	return a, nil
+}
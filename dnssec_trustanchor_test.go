@@ -0,0 +1,229 @@
+package g53
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestKSK(t *testing.T) *ecdsa.PrivateKey {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Assert(t, err == nil, "generate key failed:%v", err)
+	return privKey
+}
+
+func kskSigner(t *testing.T, zone *Name, privKey *ecdsa.PrivateKey, revoked bool) *SigningKey {
+	flags := DNSKEY_FLAG_ZONE | DNSKEY_FLAG_SEP
+	if revoked {
+		flags |= DNSKEY_FLAG_REVOKE
+	}
+	key, err := NewSigningKey(zone, flags, ALGORITHM_ECDSAP256SHA256, privKey)
+	Assert(t, err == nil, "create signing key failed:%v", err)
+	return key
+}
+
+//dnskey rrset with all the keys which is signed by all of them
+func signedDNSKeys(t *testing.T, zone *Name, now time.Time, keys ...*SigningKey) (*RRset, []*RRSig) {
+	rrset := &RRset{
+		Name:  zone.Clone(),
+		Type:  RR_DNSKEY,
+		Class: CLASS_IN,
+		Ttl:   3600,
+	}
+	for _, key := range keys {
+		rrset.Rdatas = append(rrset.Rdatas, key.DNSKey())
+	}
+
+	var sigs []*RRSig
+	for _, key := range keys {
+		sig, err := key.Sign(rrset, now.Add(-time.Hour), now.Add(time.Hour))
+		Assert(t, err == nil, "sign dnskey failed:%v", err)
+		sigs = append(sigs, sig)
+	}
+	return rrset, sigs
+}
+
+func keyState(a *ManagedTrustAnchor, key *DNSKey) TrustAnchorState {
+	for _, k := range a.Keys() {
+		if k.Key.Compare(unrevokedKey(key)) == 0 {
+			return k.State
+		}
+	}
+	return 0
+}
+
+func TestManagedTrustAnchorRollover(t *testing.T) {
+	zone := NameFromStringUnsafe("example.")
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	priv1, priv2 := newTestKSK(t), newTestKSK(t)
+	k1, k2 := kskSigner(t, zone, priv1, false), kskSigner(t, zone, priv2, false)
+	anchor := NewManagedTrustAnchor(zone, []*DNSKey{k1.DNSKey()}, clock.Now)
+
+	//rrset signed by unknown key is ignored
+	rrset, sigs := signedDNSKeys(t, zone, clock.Now(), k2)
+	Equal(t, anchor.Update(rrset, sigs), ErrTrustAnchorNotVerified)
+	Equal(t, len(anchor.Keys()), 1)
+
+	//new key is trusted after add hold-down
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k2.DNSKey()), TRUST_ANCHOR_ADDPEND)
+	Equal(t, len(anchor.TrustedKeys()), 1)
+	clock.Advance(29 * 24 * time.Hour)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k2.DNSKey()), TRUST_ANCHOR_ADDPEND)
+	clock.Advance(24 * time.Hour)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k2.DNSKey()), TRUST_ANCHOR_VALID)
+	Equal(t, len(anchor.TrustedKeys()), 2)
+
+	//revoked key which doesn't sign the rrset is ignored
+	r1 := kskSigner(t, zone, priv1, true)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), r1, k2)
+	sigs = sigs[1:]
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_VALID)
+
+	//old key is revoked and removed after remove hold-down
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), r1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_REVOKED)
+	Equal(t, len(anchor.TrustedKeys()), 1)
+	Equal(t, anchor.TrustAnchor().DNSKeys[0].Compare(k2.DNSKey()), 0)
+
+	//revoked key can't be used to sign
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1)
+	Equal(t, anchor.Update(rrset, sigs), ErrTrustAnchorNotVerified)
+
+	clock.Advance(30 * 24 * time.Hour)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_REMOVED)
+
+	//removed key never comes back
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_REMOVED)
+	Equal(t, len(anchor.TrustedKeys()), 1)
+}
+
+func TestManagedTrustAnchorMissing(t *testing.T) {
+	zone := NameFromStringUnsafe("example.")
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	k1 := kskSigner(t, zone, newTestKSK(t), false)
+	k2 := kskSigner(t, zone, newTestKSK(t), false)
+	k3 := kskSigner(t, zone, newTestKSK(t), false)
+	anchor := NewManagedTrustAnchor(zone, []*DNSKey{k1.DNSKey(), k2.DNSKey()}, clock.Now)
+
+	//addpend key disappears before hold-down is forgotten
+	rrset, sigs := signedDNSKeys(t, zone, clock.Now(), k1, k2, k3)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k3.DNSKey()), TRUST_ANCHOR_ADDPEND)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k3.DNSKey()), TrustAnchorState(0))
+
+	//valid key absent from the rrset is missing but still trusted
+	Equal(t, keyState(anchor, k2.DNSKey()), TRUST_ANCHOR_MISSING)
+	Equal(t, len(anchor.TrustedKeys()), 2)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k2.DNSKey()), TRUST_ANCHOR_VALID)
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_MISSING)
+
+	//add hold-down is extended to the original ttl of the rrset
+	anchor.AddHoldDown = time.Minute
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k2, k3)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	clock.Advance(30 * time.Minute)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k2, k3)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k3.DNSKey()), TRUST_ANCHOR_ADDPEND)
+	clock.Advance(30 * time.Minute)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k2, k3)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k3.DNSKey()), TRUST_ANCHOR_VALID)
+
+	Equal(t, anchor.Update(buildRRset(t, "example. 3600 IN NS ns.example."), nil), ErrTrustAnchorZoneMismatch)
+}
+
+func TestManagedTrustAnchorPersistence(t *testing.T) {
+	zone := NameFromStringUnsafe("example.")
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	k1 := kskSigner(t, zone, newTestKSK(t), false)
+	k2 := kskSigner(t, zone, newTestKSK(t), false)
+	anchor := NewManagedTrustAnchor(zone, []*DNSKey{k1.DNSKey()}, clock.Now)
+	rrset, sigs := signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+
+	path := filepath.Join(t.TempDir(), "example.keys")
+	Assert(t, anchor.Save(path) == nil, "save trust anchor failed")
+	loaded, err := LoadManagedTrustAnchor(path, clock.Now)
+	Assert(t, err == nil, "load trust anchor failed:%v", err)
+	Assert(t, loaded.Zone.Equals(zone), "zone should be example.")
+	Equal(t, loaded.Keys(), anchor.Keys())
+
+	//hold-down continues after reload
+	clock.Advance(RFC5011_ADD_HOLD_DOWN)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, loaded.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(loaded, k2.DNSKey()), TRUST_ANCHOR_VALID)
+
+	_, err = LoadManagedTrustAnchor(filepath.Join(t.TempDir(), "none"), clock.Now)
+	Assert(t, err != nil, "load nonexistent file should fail")
+}
+
+func TestManagedTrustAnchorSelfRevoke(t *testing.T) {
+	zone := NameFromStringUnsafe("example.")
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	priv1, priv2 := newTestKSK(t), newTestKSK(t)
+	k1 := kskSigner(t, zone, priv1, false)
+	anchor := NewManagedTrustAnchor(zone, []*DNSKey{k1.DNSKey()}, clock.Now)
+
+	//revoked key of unknown key isn't accepted
+	rrset, sigs := signedDNSKeys(t, zone, clock.Now(), kskSigner(t, zone, priv2, true))
+	Equal(t, anchor.Update(rrset, sigs), ErrTrustAnchorNotVerified)
+
+	//rrset signed only by the revoked key revokes it
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), kskSigner(t, zone, priv1, true))
+	Assert(t, anchor.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(anchor, k1.DNSKey()), TRUST_ANCHOR_REVOKED)
+	Equal(t, len(anchor.TrustedKeys()), 0)
+
+	//addpend key which is revoked is forgotten
+	k2 := kskSigner(t, zone, priv2, false)
+	trusted := NewManagedTrustAnchor(zone, []*DNSKey{k1.DNSKey()}, clock.Now)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, k2)
+	Assert(t, trusted.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(trusted, k2.DNSKey()), TRUST_ANCHOR_ADDPEND)
+	rrset, sigs = signedDNSKeys(t, zone, clock.Now(), k1, kskSigner(t, zone, priv2, true))
+	Assert(t, trusted.Update(rrset, sigs) == nil, "update should succeed")
+	Equal(t, keyState(trusted, k2.DNSKey()), TrustAnchorState(0))
+	Equal(t, len(trusted.Keys()), 1)
+
+	//anchor without keys could be saved and loaded
+	empty := NewManagedTrustAnchor(zone, nil, clock.Now)
+	path := filepath.Join(t.TempDir(), "example.keys")
+	Assert(t, empty.Save(path) == nil, "save trust anchor failed")
+	loaded, err := LoadManagedTrustAnchor(path, clock.Now)
+	Assert(t, err == nil, "load trust anchor failed:%v", err)
+	Assert(t, loaded.Zone.Equals(zone), "zone should be example.")
+	Equal(t, len(loaded.Keys()), 0)
+}
@@ -24,11 +24,6 @@ type EDNS struct {
 	Options       []Option
 }
 
-type Option interface {
-	Rend(*MsgRender)
-	String() string
-}
-
 func EdnsFromWire(buf *util.InputBuffer) (*EDNS, error) {
 	if _, err := buf.ReadUint8(); err != nil {
 		return nil, err
@@ -46,33 +41,21 @@ func EdnsFromWire(buf *util.InputBuffer) (*EDNS, error) {
 	}
 
 	flags_, err := TTLFromWire(buf)
+	if err != nil {
+		return nil, err
+	}
+
 	dnssecAware := (uint32(flags_) & EXTFLAG_DO) != 0
 	extendedRcode := uint8(uint32(flags_) >> EXTRCODE_SHIFT)
 	version := uint8((uint32(flags_) & VERSION_MASK) >> VERSION_SHIFT)
-	rdlen, _ := buf.ReadUint16()
-	var opts []Option
-	if rdlen != 0 {
-		code, _ := buf.ReadUint16()
-		switch code {
-		case EDNS_SUBNET:
-			if opt, err := subnetOptFromWire(buf); err == nil {
-				opts = append(opts, opt)
-			} else {
-				return nil, err
-			}
-		case EDNS_VIEW:
-			if opt, err := viewOptFromWire(buf); err == nil {
-				opts = append(opts, opt)
-			} else {
-				return nil, err
-			}
-		case EDNS_EXPIRE:
-			if opt, err := expireOptFromWire(buf); err == nil {
-				opts = append(opts, opt)
-			} else {
-				return nil, err
-			}
-		}
+	rdlen, err := buf.ReadUint16()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := optionsFromWire(buf, rdlen, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	return &EDNS{
@@ -99,7 +82,7 @@ func (e *EDNS) FromRRset(rrset *RRset) error {
 	extendedRcode := uint8(flags >> EXTRCODE_SHIFT)
 	version := uint8((flags & VERSION_MASK) >> VERSION_SHIFT)
 
-	opts := e.Options[:0]
+	var opts []Option
 	if len(rrset.Rdatas) > 0 {
 		for _, rdata := range rrset.Rdatas {
 			opt := rdata.(*OPT)
@@ -108,30 +91,10 @@ func (e *EDNS) FromRRset(rrset *RRset) error {
 			}
 
 			buf := util.NewInputBuffer(opt.Data)
-			code, err := buf.ReadUint16()
-			if err != nil {
+			var err error
+			if opts, err = optionsFromWire(buf, uint16(len(opt.Data)), opts); err != nil {
 				return err
 			}
-
-			if code == EDNS_SUBNET {
-				if option, err := subnetOptFromWire(buf); err == nil {
-					opts = append(opts, option)
-				} else {
-					return err
-				}
-			} else if code == EDNS_VIEW {
-				if option, err := viewOptFromWire(buf); err == nil {
-					opts = append(opts, option)
-				} else {
-					return err
-				}
-			} else if code == EDNS_EXPIRE {
-				if option, err := expireOptFromWire(buf); err == nil {
-					opts = append(opts, option)
-				} else {
-					return err
-				}
-			}
 		}
 	}
 
@@ -152,15 +115,11 @@ func (e *EDNS) ToRRset() *RRset {
 
 	var rdatas []Rdata
 	if len(e.Options) > 0 {
-		render := NewMsgRender()
-		rdatas = make([]Rdata, 0, len(e.Options))
+		buf := util.NewOutputBuffer(64)
 		for _, opt := range e.Options {
-			opt.Rend(render)
-			data := make([]byte, len(render.Data()))
-			copy(data, render.Data())
-			rdatas = append(rdatas, &OPT{data})
-			render.Clear()
+			opt.ToWire(buf)
 		}
+		rdatas = []Rdata{&OPT{buf.Data()}}
 	}
 
 	return &RRset{
@@ -206,7 +165,16 @@ func (e *EDNS) ToWire(buf *util.OutputBuffer) {
 	RRType(RR_OPT).ToWire(buf)
 	RRClass(e.UdpSize).ToWire(buf)
 	RRTTL(flags).ToWire(buf)
-	buf.WriteUint16(0)
+	if len(e.Options) == 0 {
+		buf.WriteUint16(0)
+	} else {
+		pos := buf.Len()
+		buf.Skip(2)
+		for _, opt := range e.Options {
+			opt.ToWire(buf)
+		}
+		buf.WriteUint16At(uint16(buf.Len()-pos-2), pos)
+	}
 }
 
 func (e *EDNS) String() string {
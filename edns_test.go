@@ -1,7 +1,8 @@
 package g53
 
 import (
-	//	"fmt"
+	"fmt"
+	"net"
 	"testing"
 
 	"github.com/ben-han-cn/g53/util"
@@ -48,3 +49,138 @@ func TestEdnsFromToWire(t *testing.T) {
 		DnssecAware:   true,
 	})
 }
+
+func TestEdnsMultiOptions(t *testing.T) {
+	//subnet 1.2.3.0/24, expire 100, unknown option 65001 with data 0102
+	expire := uint32(100)
+	raw := "0000291000000000000019" + "0008000700011800010203" + "0009000400000064" + "fde900020102"
+	matchEdns(t, raw, EDNS{
+		UdpSize: 4096,
+		Options: []Option{
+			&SubnetOpt{Family: 1, Mask: 24, Ip: net.IPv4(1, 2, 3, 0)},
+			&ExpireOption{Expire: &expire},
+			&GenericOption{OptCode: 65001, Data: []byte{1, 2}},
+		},
+	})
+
+	rrset := &RRset{
+		Name:  *Root,
+		Type:  RR_OPT,
+		Class: RRClass(4096),
+	}
+	data, _ := util.HexStrToBytes("00080007000118000102030009000400000064fde900020102")
+	rrset.Rdatas = []Rdata{&OPT{data}}
+	edns := EdnsFromRRset(rrset)
+	Equal(t, len(edns.Options), 3)
+	Equal(t, edns.Options[2], &GenericOption{OptCode: 65001, Data: []byte{1, 2}})
+
+	//options are kept if the new rrset is invalid
+	data, _ = util.HexStrToBytes("fde900020304000900020001")
+	Assert(t, edns.FromRRset(&RRset{Name: *Root, Type: RR_OPT, Rdatas: []Rdata{&OPT{data}}}) != nil, "expire option should be invalid")
+	Equal(t, len(edns.Options), 3)
+	Equal(t, edns.Options[0], &SubnetOpt{Family: 1, Mask: 24, Ip: net.IPv4(1, 2, 3, 0)})
+
+	for _, raw := range []string{
+		//option length exceeds rdata
+		"0000291000000000000006fde900030102",
+		//truncated option header
+		"0000291000000000000002fde9",
+		//subnet address longer than mask
+		"000029100000000000000c0008000800011800010203",
+		//expire option with wrong length
+		"0000291000000000000006000900020001",
+	} {
+		wire, _ := util.HexStrToBytes(raw)
+		_, err := EdnsFromWire(util.NewInputBuffer(wire))
+		Assert(t, err != nil, "%s should be invalid", raw)
+	}
+}
+
+type testOption struct {
+	Value uint16
+}
+
+func (o *testOption) Code() uint16 { return 65002 }
+
+func (o *testOption) Rend(render *MsgRender) {
+	o.ToWire(render.buf)
+}
+
+func (o *testOption) ToWire(buf *util.OutputBuffer) {
+	buf.WriteUint16(65002)
+	buf.WriteUint16(2)
+	buf.WriteUint16(o.Value)
+}
+
+func (o *testOption) String() string {
+	return fmt.Sprintf("; TEST: %d\n", o.Value)
+}
+
+func (o *testOption) Compare(other Option) int {
+	otherOpt, ok := other.(*testOption)
+	if ok == false {
+		return compareOptionData(o, other)
+	}
+	return int(o.Value) - int(otherOpt.Value)
+}
+
+func TestEdnsOptionRegistry(t *testing.T) {
+	raw := "0000291000000000000006fdea00020064"
+	wire, _ := util.HexStrToBytes(raw)
+	edns, err := EdnsFromWire(util.NewInputBuffer(wire))
+	Assert(t, err == nil, "unknown option should be accepted")
+	generic, ok := edns.Options[0].(*GenericOption)
+	Assert(t, ok, "unknown option should be parsed as generic option")
+
+	err = RegisterOption(65002, func(buf *util.InputBuffer, l uint16) (Option, error) {
+		if l != 2 {
+			return nil, fmt.Errorf("test option length should be 2")
+		}
+		v, err := buf.ReadUint16()
+		if err != nil {
+			return nil, err
+		}
+		return &testOption{v}, nil
+	})
+	Assert(t, err == nil, "register new option should succeed")
+	defer unregisterOption(65002)
+	Assert(t, IsOptionRegistered(65002), "option should be registered")
+	Equal(t, RegisterOption(65002, nil), ErrOptionAlreadyRegistered)
+	Equal(t, RegisterOption(EDNS_SUBNET, nil), ErrOptionAlreadyRegistered)
+
+	matchEdns(t, raw, EDNS{
+		UdpSize: 4096,
+		Options: []Option{&testOption{100}},
+	})
+	edns, _ = EdnsFromWire(util.NewInputBuffer(wire))
+	Equal(t, edns.Options[0], &testOption{100})
+	Equal(t, generic.Compare(edns.Options[0]), 0)
+
+	wire, _ = util.HexStrToBytes("0000291000000000000007fdea0003006400")
+	_, err = EdnsFromWire(util.NewInputBuffer(wire))
+	Assert(t, err != nil, "registered parser should be used")
+
+	wire, _ = util.HexStrToBytes(raw)
+	buf := util.NewOutputBuffer(64)
+	edns.ToWire(buf)
+	WireMatch(t, wire, buf.Data())
+}
+
+func TestEdnsOptionCompareWithGeneric(t *testing.T) {
+	expire := uint32(100)
+	for _, c := range []struct {
+		opt     Option
+		generic *GenericOption
+	}{
+		{&SubnetOpt{Family: 1, Mask: 24, Ip: net.IPv4(1, 2, 3, 0)}, &GenericOption{EDNS_SUBNET, []byte{0, 1, 24, 0, 1, 2, 3}}},
+		{&ViewOpt{View: "v1"}, &GenericOption{EDNS_VIEW, []byte("v1")}},
+		{&ExpireOption{Expire: &expire}, &GenericOption{EDNS_EXPIRE, []byte{0, 0, 0, 100}}},
+	} {
+		Equal(t, c.opt.Compare(c.generic), 0)
+		Equal(t, c.generic.Compare(c.opt), 0)
+
+		c.generic.Data = append(c.generic.Data, 0)
+		Assert(t, c.opt.Compare(c.generic) < 0, "%s should be less than longer data", c.opt.String())
+		Assert(t, c.generic.Compare(c.opt) > 0, "%s should be less than longer data", c.opt.String())
+	}
+}
@@ -14,14 +14,50 @@ type ExpireOption struct {
 	Expire *uint32
 }
 
+func init() {
+	mustRegisterOption(EDNS_EXPIRE, expireOptFromWire)
+}
+
+func (o *ExpireOption) Code() uint16 {
+	return EDNS_EXPIRE
+}
+
 func (o *ExpireOption) Rend(render *MsgRender) {
-	render.WriteUint16(EDNS_EXPIRE)
+	o.ToWire(render.buf)
+}
+
+func (o *ExpireOption) ToWire(buf *util.OutputBuffer) {
+	buf.WriteUint16(EDNS_EXPIRE)
 	if o.Expire != nil {
-		render.WriteUint16(4)
-		render.WriteUint32(*o.Expire)
+		buf.WriteUint16(4)
+		buf.WriteUint32(*o.Expire)
 	} else {
-		render.WriteUint16(0)
+		buf.WriteUint16(0)
+	}
+}
+
+//option without expire time is ordered first
+func (o *ExpireOption) Compare(other Option) int {
+	if order := compareOptionCode(o, other); order != 0 {
+		return order
+	}
+
+	otherOpt, ok := other.(*ExpireOption)
+	if ok == false {
+		return compareOptionData(o, other)
+	}
+
+	otherExpire := otherOpt.Expire
+	if o.Expire == nil || otherExpire == nil {
+		if o.Expire != nil {
+			return 1
+		} else if otherExpire != nil {
+			return -1
+		} else {
+			return 0
+		}
 	}
+	return fieldCompare(RDF_C_UINT32, *o.Expire, *otherExpire)
 }
 
 func (o *ExpireOption) String() string {
@@ -32,12 +68,8 @@ func (o *ExpireOption) String() string {
 	}
 }
 
-func expireOptFromWire(buf *util.InputBuffer) (Option, error) {
-	l, err := buf.ReadUint16()
-	if err != nil {
-		return nil, err
-	}
-
+//read from OPTION-DATA
+func expireOptFromWire(buf *util.InputBuffer, l uint16) (Option, error) {
 	if l == 0 {
 		return &ExpireOption{}, nil
 	}
@@ -54,26 +86,6 @@ func expireOptFromWire(buf *util.InputBuffer) (Option, error) {
 	}, nil
 }
 
-func expireOptFromRdata(rdata Rdata) Option {
-	opt := rdata.(*OPT)
-	if len(opt.Data) == 0 {
-		return nil
-	}
-
-	buf := util.NewInputBuffer(opt.Data)
-	code, _ := buf.ReadUint16()
-	if code != EDNS_EXPIRE {
-		return nil
-	}
-
-	option, err := expireOptFromWire(buf)
-	if err == nil {
-		return option
-	} else {
-		return nil
-	}
-}
-
 func (e *EDNS) SetExpireTime(expire uint32) error {
 	e.Options = append(e.Options, &ExpireOption{
 		Expire: &expire,
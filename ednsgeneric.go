@@ -0,0 +1,56 @@
+package g53
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//option which isn't supported, code and raw data is kept
+//so it could be rendered back
+type GenericOption struct {
+	OptCode uint16
+	Data    []byte
+}
+
+func (o *GenericOption) Code() uint16 {
+	return o.OptCode
+}
+
+func (o *GenericOption) Rend(render *MsgRender) {
+	o.ToWire(render.buf)
+}
+
+func (o *GenericOption) ToWire(buf *util.OutputBuffer) {
+	buf.WriteUint16(o.OptCode)
+	buf.WriteUint16(uint16(len(o.Data)))
+	buf.WriteData(o.Data)
+}
+
+func (o *GenericOption) Compare(other Option) int {
+	if order := compareOptionCode(o, other); order != 0 {
+		return order
+	}
+
+	//other option with same code may be parsed after the code is
+	//registered, so compare with its wire format
+	return compareOptionData(o, other)
+}
+
+func (o *GenericOption) String() string {
+	return fmt.Sprintf("; OPT=%d: %s\n", o.OptCode, hex.EncodeToString(o.Data))
+}
+
+//read from OPTION-DATA
+func genericOptFromWire(code uint16, buf *util.InputBuffer, l uint16) (Option, error) {
+	data, err := buf.ReadBytes(uint(l))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenericOption{
+		OptCode: code,
+		Data:    util.CloneBytes(data),
+	}, nil
+}
@@ -0,0 +1,129 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var ErrOptionAlreadyRegistered = errors.New("option code has been registered")
+
+//each option is rendered with its OPTION-CODE, OPTION-LENGTH
+//and OPTION-DATA
+type Option interface {
+	Code() uint16
+	Rend(*MsgRender)
+	ToWire(*util.OutputBuffer)
+	String() string
+	Compare(Option) int
+}
+
+//buf only contains the OPTION-DATA part, l is the OPTION-LENGTH
+type OptionFromWireFunc func(buf *util.InputBuffer, l uint16) (Option, error)
+
+var (
+	optionLock     sync.RWMutex
+	optionRegistry = make(map[uint16]OptionFromWireFunc)
+)
+
+//register the parser for options with code, option without parser
+//registered will be parsed into GenericOption
+func RegisterOption(code uint16, fromWire OptionFromWireFunc) error {
+	optionLock.Lock()
+	defer optionLock.Unlock()
+	if _, ok := optionRegistry[code]; ok {
+		return ErrOptionAlreadyRegistered
+	}
+	optionRegistry[code] = fromWire
+	return nil
+}
+
+func unregisterOption(code uint16) {
+	optionLock.Lock()
+	defer optionLock.Unlock()
+	delete(optionRegistry, code)
+}
+
+func IsOptionRegistered(code uint16) bool {
+	optionLock.RLock()
+	defer optionLock.RUnlock()
+	_, ok := optionRegistry[code]
+	return ok
+}
+
+func mustRegisterOption(code uint16, fromWire OptionFromWireFunc) {
+	if err := RegisterOption(code, fromWire); err != nil {
+		panic(fmt.Sprintf("register option %d failed:%s", code, err.Error()))
+	}
+}
+
+//opt rdata is a sequence of options, each option has a code,
+//length and data, option with unknown code is kept as GenericOption
+func optionsFromWire(buf *util.InputBuffer, rdlen uint16, opts []Option) ([]Option, error) {
+	for rdlen > 0 {
+		if rdlen < 4 {
+			return nil, fmt.Errorf("option header needs 4 bytes but only %d left", rdlen)
+		}
+
+		code, err := buf.ReadUint16()
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := buf.ReadUint16()
+		if err != nil {
+			return nil, err
+		}
+
+		rdlen -= 4
+		if l > rdlen {
+			return nil, fmt.Errorf("option %d length %d exceeds the left %d bytes", code, l, rdlen)
+		}
+		rdlen -= l
+
+		data, err := buf.ReadBytes(uint(l))
+		if err != nil {
+			return nil, err
+		}
+
+		optBuf := util.NewInputBuffer(data)
+		opt, err := optionFromWire(code, optBuf, l)
+		if err != nil {
+			return nil, err
+		} else if optBuf.Position() != uint(l) {
+			return nil, fmt.Errorf("extra data in option %d", code)
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+func optionFromWire(code uint16, buf *util.InputBuffer, l uint16) (Option, error) {
+	optionLock.RLock()
+	fromWire, ok := optionRegistry[code]
+	optionLock.RUnlock()
+
+	if ok {
+		return fromWire(buf, l)
+	} else {
+		return genericOptFromWire(code, buf, l)
+	}
+}
+
+//options with different code are ordered by code
+func compareOptionCode(o1, o2 Option) int {
+	return int(o1.Code()) - int(o2.Code())
+}
+
+//options with same code but different type, like GenericOption parsed
+//before the code is registered, are compared with their OPTION-DATA
+func compareOptionData(o1, o2 Option) int {
+	buf1 := util.NewOutputBuffer(64)
+	o1.ToWire(buf1)
+	buf2 := util.NewOutputBuffer(64)
+	o2.ToWire(buf2)
+	return bytes.Compare(buf1.Data()[4:], buf2.Data()[4:])
+}
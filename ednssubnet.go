@@ -1,6 +1,7 @@
 package g53
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 
@@ -18,76 +19,109 @@ type SubnetOpt struct {
 	Ip     net.IP
 }
 
+func init() {
+	mustRegisterOption(EDNS_SUBNET, subnetOptFromWire)
+}
+
+func (subnet *SubnetOpt) Code() uint16 {
+	return EDNS_SUBNET
+}
+
 func (subnet *SubnetOpt) Rend(render *MsgRender) {
-	render.WriteUint16(EDNS_SUBNET)
+	subnet.ToWire(render.buf)
+}
+
+func (subnet *SubnetOpt) ToWire(buf *util.OutputBuffer) {
+	buf.WriteUint16(EDNS_SUBNET)
 	ipLen := uint(subnet.Mask / 8)
 	if subnet.Mask%8 != 0 {
 		ipLen += 1
 	}
 
-	render.WriteUint16(uint16(2 + 2 + ipLen))
-	render.WriteUint16(subnet.Family)
-	render.WriteUint8(subnet.Mask)
-	render.WriteUint8(subnet.Scope)
+	buf.WriteUint16(uint16(2 + 2 + ipLen))
+	buf.WriteUint16(subnet.Family)
+	buf.WriteUint8(subnet.Mask)
+	buf.WriteUint8(subnet.Scope)
 	var ipToWrite net.IP
 	if subnet.Family == 1 {
 		ipToWrite = subnet.Ip.To4().Mask(net.CIDRMask(int(subnet.Mask), net.IPv4len*8))
 	} else {
 		ipToWrite = subnet.Ip.To16().Mask(net.CIDRMask(int(subnet.Mask), net.IPv6len*8))
 	}
-	render.WriteData([]byte(ipToWrite)[0:ipLen])
+	buf.WriteData([]byte(ipToWrite)[0:ipLen])
+}
+
+func (subnet *SubnetOpt) Compare(other Option) int {
+	if order := compareOptionCode(subnet, other); order != 0 {
+		return order
+	}
+
+	otherSubnet, ok := other.(*SubnetOpt)
+	if ok == false {
+		return compareOptionData(subnet, other)
+	}
+
+	if order := fieldCompare(RDF_C_UINT16, subnet.Family, otherSubnet.Family); order != 0 {
+		return order
+	}
+
+	if order := fieldCompare(RDF_C_UINT8, subnet.Mask, otherSubnet.Mask); order != 0 {
+		return order
+	}
+
+	if order := fieldCompare(RDF_C_UINT8, subnet.Scope, otherSubnet.Scope); order != 0 {
+		return order
+	}
+
+	return bytes.Compare(subnet.Ip.To16(), otherSubnet.Ip.To16())
 }
 
 func (subnet *SubnetOpt) String() string {
 	return fmt.Sprintf("; CLIENT-SUBNET: %s/%d/%d\n", subnet.Ip.String(), subnet.Mask, subnet.Scope)
 }
 
-//read from OPTION-LENGTH
-func subnetOptFromWire(buf *util.InputBuffer) (Option, error) {
-	l, _ := buf.ReadUint16()
+//read from OPTION-DATA
+func subnetOptFromWire(buf *util.InputBuffer, l uint16) (Option, error) {
+	if l < 4 {
+		return nil, fmt.Errorf("subnet option length %d is too short", l)
+	}
+
 	family, _ := buf.ReadUint16()
 	mask, _ := buf.ReadUint8()
 	scope, _ := buf.ReadUint8()
-	var ip net.IP
+	var ipLen uint
 	switch family {
 	case 1:
-		addr := make([]byte, 4)
-		addr_data, _ := buf.ReadBytes(uint(l - 4))
-		copy(addr, addr_data)
-		ip = net.IPv4(addr[0], addr[1], addr[2], addr[3])
+		ipLen = net.IPv4len
 	case 2:
-		addr := make([]byte, 16)
-		addr_data, _ := buf.ReadBytes(uint(l - 4))
-		copy(addr, addr_data)
-		ip = net.IP{addr[0], addr[1], addr[2], addr[3], addr[4],
-			addr[5], addr[6], addr[7], addr[8], addr[9], addr[10],
-			addr[11], addr[12], addr[13], addr[14], addr[15]}
-	}
-
-	if ip != nil {
-		return &SubnetOpt{Family: family,
-			Mask:  mask,
-			Scope: scope,
-			Ip:    ip}, nil
-	} else {
+		ipLen = net.IPv6len
+	default:
 		return nil, fmt.Errorf("unkown family")
 	}
-}
 
-func subnetOptFromRdata(rdata Rdata) Option {
-	data := rdata.(*OPT).Data
-	if len(data) == 0 {
-		return nil
+	addrLen := uint(l - 4)
+	if addrLen > ipLen {
+		return nil, fmt.Errorf("subnet address length %d is too long", addrLen)
+	} else if uint(mask) > ipLen*8 {
+		return nil, fmt.Errorf("subnet mask %d is too long", mask)
+	} else if addrLen != (uint(mask)+7)/8 {
+		return nil, fmt.Errorf("subnet address length %d doesn't match mask %d", addrLen, mask)
 	}
 
-	buf := util.NewInputBuffer(data)
-	code, _ := buf.ReadUint16()
-	if code != EDNS_SUBNET {
-		return nil
+	addr := make([]byte, ipLen)
+	addr_data, _ := buf.ReadBytes(addrLen)
+	copy(addr, addr_data)
+	var ip net.IP
+	if family == 1 {
+		ip = net.IPv4(addr[0], addr[1], addr[2], addr[3])
+	} else {
+		ip = net.IP(addr)
 	}
 
-	opt, _ := subnetOptFromWire(buf)
-	return opt
+	return &SubnetOpt{Family: family,
+		Mask:  mask,
+		Scope: scope,
+		Ip:    ip}, nil
 }
 
 func (e *EDNS) AddSubnetV4(ip_ string) error {
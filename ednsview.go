@@ -2,6 +2,8 @@ package g53
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/ben-han-cn/g53/util"
 )
 
@@ -13,23 +15,42 @@ type ViewOpt struct {
 	View string
 }
 
+func init() {
+	mustRegisterOption(EDNS_VIEW, viewOptFromWire)
+}
+
+func (vo *ViewOpt) Code() uint16 {
+	return EDNS_VIEW
+}
+
 func (vo *ViewOpt) Rend(render *MsgRender) {
-	render.WriteUint16(EDNS_VIEW)
-	render.WriteUint16(uint16(len(vo.View)))
-	render.WriteData([]byte(vo.View))
+	vo.ToWire(render.buf)
 }
 
-func (vo *ViewOpt) String() string {
-	return fmt.Sprintf("; CLIENT-VIEW: %s\n", vo.View)
+func (vo *ViewOpt) ToWire(buf *util.OutputBuffer) {
+	buf.WriteUint16(EDNS_VIEW)
+	buf.WriteUint16(uint16(len(vo.View)))
+	buf.WriteData([]byte(vo.View))
 }
 
-//read from OPTION-LENGTH
-func viewOptFromWire(buf *util.InputBuffer) (Option, error) {
-	l, err := buf.ReadUint16()
-	if err != nil {
-		return nil, err
+func (vo *ViewOpt) Compare(other Option) int {
+	if order := compareOptionCode(vo, other); order != 0 {
+		return order
 	}
 
+	otherView, ok := other.(*ViewOpt)
+	if ok == false {
+		return compareOptionData(vo, other)
+	}
+	return strings.Compare(vo.View, otherView.View)
+}
+
+func (vo *ViewOpt) String() string {
+	return fmt.Sprintf("; CLIENT-VIEW: %s\n", vo.View)
+}
+
+//read from OPTION-DATA
+func viewOptFromWire(buf *util.InputBuffer, l uint16) (Option, error) {
 	view, err := buf.ReadBytes(uint(l))
 	if err != nil {
 		return nil, err
@@ -40,26 +61,6 @@ func viewOptFromWire(buf *util.InputBuffer) (Option, error) {
 	}, nil
 }
 
-func viewOptFromRdata(rdata Rdata) Option {
-	opt := rdata.(*OPT)
-	if len(opt.Data) == 0 {
-		return nil
-	}
-
-	buf := util.NewInputBuffer(opt.Data)
-	code, _ := buf.ReadUint16()
-	if code != EDNS_VIEW {
-		return nil
-	}
-
-	option, err := viewOptFromWire(buf)
-	if err == nil {
-		return option
-	} else {
-		return nil
-	}
-}
-
 func (e *EDNS) AddSubnetView(view string) error {
 	e.Options = append(e.Options, &ViewOpt{
 		View: view,
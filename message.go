@@ -38,6 +38,37 @@ func (s Section) Rend(r *MsgRender) {
 	}
 }
 
+//rend rrsets in order until one of them exceeds the length limit,
+//return the count of rendered rrsets
+func (s Section) rendWithinLimit(r *MsgRender) int {
+	for i := 0; i < len(s); i++ {
+		if s[i].rendWithinLimit(r) == false {
+			return i
+		}
+	}
+	return len(s)
+}
+
+//opt and tsig rrset are put at the end of additional section,
+//split them from the normal rrsets
+func (s Section) splitMeta() (Section, Section) {
+	i := len(s)
+	for i > 0 && (s[i-1].Type == RR_OPT || s[i-1].Type == RR_TSIG) {
+		i -= 1
+	}
+	return s[:i], s[i:]
+}
+
+func (s Section) wireLen() uint32 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	buf := util.NewOutputBuffer(512)
+	s.ToWire(buf)
+	return uint32(buf.Len())
+}
+
 func (s Section) ToWire(buf *util.OutputBuffer) {
 	for i := 0; i < len(s); i++ {
 		s[i].ToWire(buf)
@@ -207,15 +238,73 @@ func (m *Message) GetTsig() (*Tsig, error) {
 	}
 }
 
+//message is truncated at rrset boundary if it exceeds the length
+//limit of render which is disabled by default, answer and authority section are filled before
+//additional section, opt and tsig rr are always kept. for truncated
+//message, tc bit is set and section counts are adjusted in wire data,
+//the message itself isn't modified. rrsets dropped only from additional
+//section don't set tc bit, rfc2181 section 9
 func (m *Message) Rend(r *MsgRender) {
+	pos := r.Len()
 	(&m.Header).Rend(r)
 
+	truncated := false
+	qdCount := uint16(0)
 	if m.Question != nil {
+		qpos := r.Len()
 		m.Question.Rend(r)
+		if r.exceedLenLimit() {
+			r.rollback(qpos)
+			truncated = true
+		} else {
+			qdCount = 1
+		}
 	}
 
-	for i := 0; i < SectionCount; i++ {
-		m.sections[i].Rend(r)
+	additional, meta := m.sections[AdditionalSection].splitMeta()
+	limit := r.LenLimit
+	if limit != 0 {
+		if reserved := meta.wireLen(); reserved < limit {
+			r.LenLimit = limit - reserved
+		} else {
+			//no room left for other rrsets
+			r.LenLimit = uint32(r.Len())
+		}
+	}
+
+	var counts [SectionCount]uint16
+	dropped := false
+	sections := [SectionCount]Section{m.sections[AnswerSection], m.sections[AuthSection], additional}
+	for i := 0; i < SectionCount && truncated == false; i++ {
+		n := sections[i].rendWithinLimit(r)
+		counts[i] = uint16(sections[i][:n].rrCount())
+		if n == len(sections[i]) {
+			continue
+		} else if SectionType(i) == AdditionalSection {
+			dropped = true
+		} else {
+			truncated = true
+		}
+	}
+
+	r.LenLimit = limit
+	n := meta.rendWithinLimit(r)
+	counts[AdditionalSection] += uint16(meta[:n].rrCount())
+	if n != len(meta) {
+		truncated = true
+	}
+
+	if truncated || dropped {
+		header := m.Header
+		if truncated {
+			r.SetTrancated()
+			header.SetFlag(FLAG_TC, true)
+		}
+		r.WriteUint16At(header.flag(), pos+2)
+		r.WriteUint16At(qdCount, pos+4)
+		for i := 0; i < SectionCount; i++ {
+			r.WriteUint16At(counts[i], pos+6+uint(i)*2)
+		}
 	}
 }
 
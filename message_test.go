@@ -1,6 +1,7 @@
 package g53
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/ben-han-cn/g53/util"
@@ -267,3 +268,60 @@ func BenchmarkParseKnetMessage(b *testing.B) {
 func BenchmarkParseTestExample(b *testing.B) {
 	benchmarkParseMessage(b, "04b0850000010002000100020474657374076578616d706c6503636f6d0000010001c00c0001000100000e100004c0000202c00c0001000100000e100004c0000201c0110002000100000e100006036e7331c011c04e0001000100000e100004020202020000291000000000000000")
 }
+
+func TestMessageTruncate(t *testing.T) {
+	knet_cn := "04b08180000100010004000d03777777046b6e657402636e0000010001c00c00010001000002580004caad0b0ac01000020001000000c1001404676e7331097a646e73636c6f7564036e657400c01000020001000000c10014046c6e7332097a646e73636c6f75640362697a00c01000020001000000c1001504676e7332097a646e73636c6f7564036e6574c015c01000020001000000c10015046c6e7331097a646e73636c6f756404696e666f00c039000100010000262c000401089801c0790001000100000599000401089901c09a00010001000007c800046f012189c09a00010001000007c8000477a7e9e9c09a00010001000007c80004b683170bc09a00010001000007c80004010865fdc09a001c0001000007c8001024018d00000400000000000000000001c0590001000100002fea000477a7e9ebc0590001000100002fea0004b683170cc0590001000100002fea0004010865fcc0590001000100002fea00046f01218ac059001c00010000249f001024018d000006000000000000000000010000291000000000000000"
+	wire, _ := util.HexStrToBytes(knet_cn)
+	msg, err := MessageFromWire(util.NewInputBuffer(wire))
+	Assert(t, err == nil, "err should be nil")
+
+	render := NewMsgRender()
+	render.SetLenLimitByEdns(&EDNS{UdpSize: 100})
+	Equal(t, render.LenLimit, DEFAULT_UDP_PAYLOAD)
+	msg.Rend(render)
+	Assert(t, render.IsTrancated() == false, "message shorter than 512 shouldn't be truncated")
+	WireMatch(t, wire, render.Data())
+
+	//gns1 and gns2 glue plus opt, dropped glue doesn't set tc bit
+	render.Clear()
+	render.LenLimit = 220
+	msg.Rend(render)
+	Assert(t, render.IsTrancated() == false, "dropping additional rrsets isn't truncation")
+	Assert(t, render.Len() <= 220, "truncated message is too long")
+	tm, err := MessageFromWire(util.NewInputBuffer(render.Data()))
+	Assert(t, err == nil, "truncated message should be valid but get %v", err)
+	Assert(t, tm.Header.GetFlag(FLAG_TC) == false, "tc bit shouldn't be set")
+	Equal(t, tm.Header.ANCount, uint16(1))
+	Equal(t, tm.Header.NSCount, uint16(4))
+	Equal(t, tm.Header.ARCount, uint16(3))
+	Equal(t, tm.SectionRRsetCount(AdditionalSection), 3)
+	edns, _ := tm.GetEdns()
+	Assert(t, edns != nil && edns.UdpSize == 4096, "opt rr should be kept")
+	Assert(t, msg.Header.GetFlag(FLAG_TC) == false, "original message shouldn't be modified")
+
+	//authority section doesn't fit, additional section is dropped
+	render.Clear()
+	render.LenLimit = 100
+	msg.Rend(render)
+	tm, err = MessageFromWire(util.NewInputBuffer(render.Data()))
+	Assert(t, err == nil, "truncated message should be valid but get %v", err)
+	Assert(t, tm.Header.GetFlag(FLAG_TC), "tc bit should be set")
+	Equal(t, tm.Header.ANCount, uint16(1))
+	Equal(t, tm.Header.NSCount, uint16(0))
+	Equal(t, tm.Header.ARCount, uint16(1))
+	edns, _ = tm.GetEdns()
+	Assert(t, edns != nil, "opt rr should be kept")
+
+	//no length limit by default
+	render.Clear()
+	Equal(t, render.LenLimit, uint32(0))
+	var rrs []string
+	for i := 0; i < 50; i++ {
+		rrs = append(rrs, fmt.Sprintf("www.knet.cn. 600 IN A 192.0.2.%d", i))
+	}
+	rrset, err := RRsetFromStrings(rrs)
+	Assert(t, err == nil, "err should be nil")
+	rrset.Rend(render)
+	Assert(t, render.IsTrancated() == false, "render without limit shouldn't be truncated")
+	Assert(t, render.Len() > 512, "all the rrs should be rendered")
+}
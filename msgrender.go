@@ -67,6 +67,9 @@ const (
 	NO_OFFSET      uint16 = 65535
 )
 
+const DEFAULT_UDP_PAYLOAD uint32 = 512
+
+//LenLimit is the max length of rendered message, 0 means no limit
 type MsgRender struct {
 	buf           *util.OutputBuffer
 	truncated     bool
@@ -80,7 +83,7 @@ func NewMsgRender() *MsgRender {
 	render := MsgRender{
 		buf:           util.NewOutputBuffer(512),
 		truncated:     false,
-		LenLimit:      512,
+		LenLimit:      0,
 		caseSensitive: false,
 	}
 	for i := uint(0); i < BUCKETS; i++ {
@@ -97,6 +100,35 @@ func (r *MsgRender) SetTrancated() {
 	r.truncated = true
 }
 
+//use the udp payload size advertised by client as length limit,
+//client without edns only accept 512 bytes, and according to
+//rfc6891 size less than 512 should be treated as 512
+func (r *MsgRender) SetLenLimitByEdns(edns *EDNS) {
+	if edns == nil || uint32(edns.UdpSize) < DEFAULT_UDP_PAYLOAD {
+		r.LenLimit = DEFAULT_UDP_PAYLOAD
+	} else {
+		r.LenLimit = uint32(edns.UdpSize)
+	}
+}
+
+func (r *MsgRender) exceedLenLimit() bool {
+	return r.LenLimit != 0 && r.Len() > uint(r.LenLimit)
+}
+
+//drop the data after pos, and the compression offsets
+//which point into the dropped data
+func (r *MsgRender) rollback(pos uint) {
+	r.buf.Trim(r.buf.Len() - pos)
+	for i := uint(0); i < BUCKETS; i++ {
+		items := r.table[i]
+		l := len(items)
+		for l > 0 && uint(items[l-1].pos) >= pos {
+			l -= 1
+		}
+		r.table[i] = items[:l]
+	}
+}
+
 func (r *MsgRender) findOffset(buf *util.OutputBuffer, nameBuf *util.InputBuffer, hash uint32) uint16 {
 	bucketId := hash % uint32(BUCKETS)
 	comparator := nameComparator{buf, nameBuf, hash, r.caseSensitive}
@@ -126,7 +158,7 @@ func (r *MsgRender) addOffset(hash, offset, length uint32) {
 
 func (r *MsgRender) Clear() {
 	r.buf.Clear()
-	r.LenLimit = 512
+	r.LenLimit = 0
 	r.truncated = false
 	r.caseSensitive = false
 	for i := uint(0); i < BUCKETS; i++ {
@@ -3,6 +3,7 @@ package g53
 import (
 	"bytes"
 	"errors"
+	"sort"
 
 	"github.com/ben-han-cn/g53/util"
 )
@@ -800,3 +801,15 @@ func (name *Name) IsSubDomain(parent *Name) bool {
 	}
 	return true
 }
+
+//names sorted in canonical order defined in rfc4034 section 6.1, labels
+//are compared from the rightmost one case insensitively
+type NameSlice []*Name
+
+func (names NameSlice) Len() int           { return len(names) }
+func (names NameSlice) Swap(i, j int)      { names[i], names[j] = names[j], names[i] }
+func (names NameSlice) Less(i, j int) bool { return names[i].Compare(names[j], false).Order < 0 }
+
+func SortNames(names []*Name) {
+	sort.Sort(NameSlice(names))
+}
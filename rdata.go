@@ -1,11 +1,19 @@
 package g53
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/ben-han-cn/g53/util"
 )
 
+var (
+	ErrRRTypeExists       = errors.New("rr type has been defined")
+	ErrRRTypeNameConflict = errors.New("rr type name is used by other type")
+)
+
 type Rdata interface {
 	Rend(r *MsgRender)
 	ToWire(buf *util.OutputBuffer)
@@ -13,6 +21,62 @@ type Rdata interface {
 	String() string
 }
 
+var (
+	rdataTypeLock sync.RWMutex
+	rdataSchemas  = make(map[RRType]*rdataSchema)
+)
+
+//register a new rr type with its name used in text format and the
+//fields of its rdata, types already known by g53 cann't be registered.
+//name in rfc3597 generic format like TYPE123 or a class name isn't
+//allowed since it will be ambiguous in text format
+func RegisterRdataType(t RRType, name string, fields []RdataField) error {
+	name = strings.ToLower(name)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("rr type name %q isn't valid", name)
+	} else if isGenericTypeName(name) {
+		return fmt.Errorf("rr type name %q is in generic format", name)
+	} else if _, err := ClassFromString(name); err == nil {
+		return fmt.Errorf("rr type name %q is a class name", name)
+	}
+
+	schema, err := newRdataSchema(t, fields)
+	if err != nil {
+		return err
+	}
+
+	rdataTypeLock.Lock()
+	defer rdataTypeLock.Unlock()
+	if _, ok := typeNameMap[t]; ok {
+		return ErrRRTypeExists
+	}
+	for _, name_ := range typeNameMap {
+		if name_ == name {
+			return ErrRRTypeNameConflict
+		}
+	}
+
+	typeNameMap[t] = name
+	rdataSchemas[t] = schema
+	return nil
+}
+
+func unregisterRdataType(t RRType) {
+	rdataTypeLock.Lock()
+	defer rdataTypeLock.Unlock()
+	if _, ok := rdataSchemas[t]; ok {
+		delete(typeNameMap, t)
+		delete(rdataSchemas, t)
+	}
+}
+
+func getRdataSchema(t RRType) (*rdataSchema, bool) {
+	rdataTypeLock.RLock()
+	defer rdataTypeLock.RUnlock()
+	schema, ok := rdataSchemas[t]
+	return schema, ok
+}
+
 func RdataFromWire(t RRType, buf *util.InputBuffer) (Rdata, error) {
 	rdlen, err := buf.ReadUint16()
 	if err != nil {
@@ -23,7 +87,10 @@ func RdataFromWire(t RRType, buf *util.InputBuffer) (Rdata, error) {
 	if rdlen == 0 {
 		return nil, nil
 	}
+	return rdataFromWire(t, buf, rdlen)
+}
 
+func rdataFromWire(t RRType, buf *util.InputBuffer, rdlen uint16) (Rdata, error) {
 	switch t {
 	case RR_A:
 		return AFromWire(buf, rdlen)
@@ -59,6 +126,76 @@ func RdataFromWire(t RRType, buf *util.InputBuffer) (Rdata, error) {
 		return TsigFromWire(buf, rdlen)
 	case RR_NSEC3:
 		return NSEC3FromWire(buf, rdlen)
+	case RR_NSEC3PARAM:
+		return NSEC3ParamFromWire(buf, rdlen)
+	case RR_NSEC:
+		return NSECFromWire(buf, rdlen)
+	case RR_DNSKEY:
+		return DNSKeyFromWire(buf, rdlen)
+	case RR_CDS:
+		return CDSFromWire(buf, rdlen)
+	case RR_CDNSKEY:
+		return CDNSKeyFromWire(buf, rdlen)
+	case RR_CSYNC:
+		return CSyncFromWire(buf, rdlen)
+	case RR_SVCB:
+		return SVCBFromWire(buf, rdlen)
+	case RR_HTTPS:
+		return HTTPSFromWire(buf, rdlen)
+	case RR_CAA:
+		return CAAFromWire(buf, rdlen)
+	case RR_TLSA:
+		return TLSAFromWire(buf, rdlen)
+	case RR_SMIMEA:
+		return SMIMEAFromWire(buf, rdlen)
+	case RR_SSHFP:
+		return SSHFPFromWire(buf, rdlen)
+	case RR_LOC:
+		return LOCFromWire(buf, rdlen)
+	case RR_HINFO:
+		return HInfoFromWire(buf, rdlen)
+	case RR_AFSDB:
+		return AFSDBFromWire(buf, rdlen)
+	case RR_KX:
+		return KXFromWire(buf, rdlen)
+	case RR_RT:
+		return RTFromWire(buf, rdlen)
+	case RR_PX:
+		return PXFromWire(buf, rdlen)
+	case RR_MINFO:
+		return MInfoFromWire(buf, rdlen)
+	case RR_NULL:
+		return NullFromWire(buf, rdlen)
+	case RR_WKS:
+		return WKSFromWire(buf, rdlen)
+	case RR_URI:
+		return URIFromWire(buf, rdlen)
+	case RR_CERT:
+		return CertFromWire(buf, rdlen)
+	case RR_IPSECKEY:
+		return IPSecKeyFromWire(buf, rdlen)
+	case RR_APL:
+		return APLFromWire(buf, rdlen)
+	case RR_EUI48:
+		return EUI48FromWire(buf, rdlen)
+	case RR_EUI64:
+		return EUI64FromWire(buf, rdlen)
+	case RR_NID:
+		return NIDFromWire(buf, rdlen)
+	case RR_L32:
+		return L32FromWire(buf, rdlen)
+	case RR_L64:
+		return L64FromWire(buf, rdlen)
+	case RR_LP:
+		return LPFromWire(buf, rdlen)
+	case RR_ZONEMD:
+		return ZoneMDFromWire(buf, rdlen)
+	case RR_OPENPGPKEY:
+		return OpenPGPKeyFromWire(buf, rdlen)
+	case RR_DHCID:
+		return DHCIDFromWire(buf, rdlen)
+	case RR_HIP:
+		return HIPFromWire(buf, rdlen)
 	case RR_DS:
 		return DSFromWire(buf, rdlen)
 	case RR_WA:
@@ -68,11 +205,18 @@ func RdataFromWire(t RRType, buf *util.InputBuffer) (Rdata, error) {
 	case RR_WCNAME:
 		return WCNameFromWire(buf, rdlen)
 	default:
-		return nil, fmt.Errorf("unimplement type: %v", t)
+		if schema, ok := getRdataSchema(t); ok {
+			return schema.fromWire(buf, rdlen)
+		}
+		return UnknownRdataFromWire(buf, rdlen)
 	}
 }
 
 func RdataFromString(t RRType, s string) (Rdata, error) {
+	if isGenericRdata(s) {
+		return rdataFromGenericString(t, s)
+	}
+
 	switch t {
 	case RR_A:
 		return AFromString(s)
@@ -106,6 +250,76 @@ func RdataFromString(t RRType, s string) (Rdata, error) {
 		return SPFFromString(s)
 	case RR_NSEC3:
 		return NSEC3FromString(s)
+	case RR_NSEC3PARAM:
+		return NSEC3ParamFromString(s)
+	case RR_NSEC:
+		return NSECFromString(s)
+	case RR_DNSKEY:
+		return DNSKeyFromString(s)
+	case RR_CDS:
+		return CDSFromString(s)
+	case RR_CDNSKEY:
+		return CDNSKeyFromString(s)
+	case RR_CSYNC:
+		return CSyncFromString(s)
+	case RR_SVCB:
+		return SVCBFromString(s)
+	case RR_HTTPS:
+		return HTTPSFromString(s)
+	case RR_CAA:
+		return CAAFromString(s)
+	case RR_TLSA:
+		return TLSAFromString(s)
+	case RR_SMIMEA:
+		return SMIMEAFromString(s)
+	case RR_SSHFP:
+		return SSHFPFromString(s)
+	case RR_LOC:
+		return LOCFromString(s)
+	case RR_HINFO:
+		return HInfoFromString(s)
+	case RR_AFSDB:
+		return AFSDBFromString(s)
+	case RR_KX:
+		return KXFromString(s)
+	case RR_RT:
+		return RTFromString(s)
+	case RR_PX:
+		return PXFromString(s)
+	case RR_MINFO:
+		return MInfoFromString(s)
+	case RR_NULL:
+		return NullFromString(s)
+	case RR_WKS:
+		return WKSFromString(s)
+	case RR_URI:
+		return URIFromString(s)
+	case RR_CERT:
+		return CertFromString(s)
+	case RR_IPSECKEY:
+		return IPSecKeyFromString(s)
+	case RR_APL:
+		return APLFromString(s)
+	case RR_EUI48:
+		return EUI48FromString(s)
+	case RR_EUI64:
+		return EUI64FromString(s)
+	case RR_NID:
+		return NIDFromString(s)
+	case RR_L32:
+		return L32FromString(s)
+	case RR_L64:
+		return L64FromString(s)
+	case RR_LP:
+		return LPFromString(s)
+	case RR_ZONEMD:
+		return ZoneMDFromString(s)
+	case RR_OPENPGPKEY:
+		return OpenPGPKeyFromString(s)
+	case RR_DHCID:
+		return DHCIDFromString(s)
+	case RR_HIP:
+		return HIPFromString(s)
 	case RR_DS:
 		return DSFromString(s)
 	case RR_WA:
@@ -115,6 +329,9 @@ func RdataFromString(t RRType, s string) (Rdata, error) {
 	case RR_WCNAME:
 		return WCNameFromString(s)
 	default:
-		return nil, fmt.Errorf("unimplement type: %v", t)
+		if schema, ok := getRdataSchema(t); ok {
+			return schema.fromString(s)
+		}
+		return nil, fmt.Errorf("unimplement type: %v, rdata should be in generic format", t)
 	}
 }
@@ -0,0 +1,88 @@
+package g53
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//afsdb defined in rfc1183, hostname isn't compressed as rfc3597 requires
+type AFSDB struct {
+	Subtype  uint16
+	Hostname *Name
+}
+
+func (afsdb *AFSDB) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, afsdb.Subtype, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, afsdb.Hostname, r)
+}
+
+func (afsdb *AFSDB) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, afsdb.Subtype, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, afsdb.Hostname, buf)
+}
+
+func (afsdb *AFSDB) Compare(other Rdata) int {
+	otherAFSDB := other.(*AFSDB)
+	order := fieldCompare(RDF_C_UINT16, afsdb.Subtype, otherAFSDB.Subtype)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_NAME_UNCOMPRESS, afsdb.Hostname, otherAFSDB.Hostname)
+}
+
+func (afsdb *AFSDB) String() string {
+	return strings.Join([]string{
+		fieldToString(RDF_D_INT, afsdb.Subtype),
+		fieldToString(RDF_D_NAME, afsdb.Hostname)}, " ")
+}
+
+func AFSDBFromWire(buf *util.InputBuffer, ll uint16) (*AFSDB, error) {
+	f, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	subtype, _ := f.(uint16)
+
+	f, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := f.(*Name)
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &AFSDB{subtype, hostname}, nil
+}
+
+var afsdbRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*$`)
+
+func AFSDBFromString(s string) (*AFSDB, error) {
+	fields := afsdbRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for afsdb isn't 2")
+	}
+
+	fields = fields[1:]
+	f, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	subtype, _ := f.(int)
+	if subtype < 0 || subtype > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	f, err = fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := f.(*Name)
+	return &AFSDB{uint16(subtype), hostname}, nil
+}
@@ -0,0 +1,203 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const (
+	APL_FAMILY_IPV4 uint16 = 1
+	APL_FAMILY_IPV6 uint16 = 2
+)
+
+var ErrUnknownAPLFamily = errors.New("unknown apl address family")
+
+type APLItem struct {
+	Negation bool
+	Family   uint16
+	Prefix   uint8
+	Address  net.IP
+}
+
+func (item APLItem) String() string {
+	s := fmt.Sprintf("%d:%s/%d", item.Family, item.Address.String(), item.Prefix)
+	if item.Negation {
+		return "!" + s
+	}
+	return s
+}
+
+func (item APLItem) addressLen() int {
+	if item.Family == APL_FAMILY_IPV4 {
+		return net.IPv4len
+	}
+	return net.IPv6len
+}
+
+//address is trimmed to remove trailing zero octets
+func (item APLItem) toWire(buf *util.OutputBuffer) {
+	var addr []byte
+	if item.Family == APL_FAMILY_IPV4 {
+		addr = item.Address.To4()
+	} else {
+		addr = item.Address.To16()
+	}
+
+	for len(addr) > 0 && addr[len(addr)-1] == 0 {
+		addr = addr[:len(addr)-1]
+	}
+
+	buf.WriteUint16(item.Family)
+	buf.WriteUint8(item.Prefix)
+	l := uint8(len(addr))
+	if item.Negation {
+		l |= 0x80
+	}
+	buf.WriteUint8(l)
+	buf.WriteData(addr)
+}
+
+//apl defined in rfc3123
+type APL struct {
+	Items []APLItem
+}
+
+func (apl *APL) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, apl.itemsToWire(), r)
+}
+
+func (apl *APL) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, apl.itemsToWire(), buf)
+}
+
+func (apl *APL) itemsToWire() []byte {
+	buf := util.NewOutputBuffer(64)
+	for _, item := range apl.Items {
+		item.toWire(buf)
+	}
+	return buf.Data()
+}
+
+func (apl *APL) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, apl.itemsToWire(), other.(*APL).itemsToWire())
+}
+
+func (apl *APL) String() string {
+	var buf bytes.Buffer
+	for i, item := range apl.Items {
+		if i != 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(item.String())
+	}
+	return buf.String()
+}
+
+func APLFromWire(buf *util.InputBuffer, ll uint16) (*APL, error) {
+	var items []APLItem
+	for ll > 0 {
+		family, left, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix, left, err := fieldFromWire(RDF_C_UINT8, buf, left)
+		if err != nil {
+			return nil, err
+		}
+
+		l, left, err := fieldFromWire(RDF_C_UINT8, buf, left)
+		if err != nil {
+			return nil, err
+		}
+
+		item := APLItem{
+			Negation: l.(uint8)&0x80 != 0,
+			Family:   family.(uint16),
+			Prefix:   prefix.(uint8),
+		}
+		if item.Family != APL_FAMILY_IPV4 && item.Family != APL_FAMILY_IPV6 {
+			return nil, ErrUnknownAPLFamily
+		}
+
+		afdLen := uint16(l.(uint8) & 0x7f)
+		if int(afdLen) > item.addressLen() || int(item.Prefix) > item.addressLen()*8 {
+			return nil, errors.New("apl address or prefix is too long")
+		} else if afdLen > left {
+			return nil, ErrDataIsTooShort
+		}
+
+		afd, _, err := fieldFromWire(RDF_C_BINARY, buf, afdLen)
+		if err != nil {
+			return nil, err
+		}
+
+		addr := afd.([]byte)
+		if afdLen > 0 && addr[afdLen-1] == 0 {
+			return nil, errors.New("apl address has trailing zero octets")
+		}
+		item.Address = make(net.IP, item.addressLen())
+		copy(item.Address, addr)
+
+		items = append(items, item)
+		ll = left - afdLen
+	}
+
+	return &APL{items}, nil
+}
+
+//items are in format [!]afi:address/prefix
+func APLFromString(s string) (*APL, error) {
+	var items []APLItem
+	for _, field := range strings.Fields(s) {
+		item := APLItem{}
+		if strings.HasPrefix(field, "!") {
+			item.Negation = true
+			field = field[1:]
+		}
+
+		colon := strings.IndexByte(field, ':')
+		slash := strings.LastIndexByte(field, '/')
+		if colon == -1 || slash == -1 || slash < colon {
+			return nil, fmt.Errorf("apl item %s isn't valid", field)
+		}
+
+		family, err := strconv.ParseUint(field[:colon], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		item.Family = uint16(family)
+
+		var addr interface{}
+		switch item.Family {
+		case APL_FAMILY_IPV4:
+			addr, err = fieldFromString(RDF_D_IPV4, field[colon+1:slash])
+		case APL_FAMILY_IPV6:
+			addr, err = fieldFromString(RDF_D_IPV6, field[colon+1:slash])
+		default:
+			return nil, ErrUnknownAPLFamily
+		}
+		if err != nil {
+			return nil, err
+		}
+		item.Address = addr.(net.IP)
+
+		prefix, err := strconv.ParseUint(field[slash+1:], 10, 8)
+		if err != nil {
+			return nil, err
+		} else if int(prefix) > item.addressLen()*8 {
+			return nil, ErrOutOfRange
+		}
+		item.Prefix = uint8(prefix)
+
+		items = append(items, item)
+	}
+
+	return &APL{items}, nil
+}
@@ -0,0 +1,147 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const CAA_FLAG_CRITICAL uint8 = 0x80
+
+var ErrInvalidCAATag = errors.New("caa tag should be non-empty alphanumeric string")
+
+type CAA struct {
+	Flags uint8
+	Tag   string
+	Value []byte
+}
+
+func (caa *CAA) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, caa.Flags, r)
+	rendField(RDF_C_BYTE_BINARY, []byte(caa.Tag), r)
+	rendField(RDF_C_BINARY, caa.Value, r)
+}
+
+func (caa *CAA) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, caa.Flags, buf)
+	fieldToWire(RDF_C_BYTE_BINARY, []byte(caa.Tag), buf)
+	fieldToWire(RDF_C_BINARY, caa.Value, buf)
+}
+
+func (caa *CAA) Compare(other Rdata) int {
+	otherCAA := other.(*CAA)
+	order := fieldCompare(RDF_C_UINT8, caa.Flags, otherCAA.Flags)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_BYTE_BINARY, []byte(caa.Tag), []byte(otherCAA.Tag))
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, caa.Value, otherCAA.Value)
+}
+
+func (caa *CAA) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, caa.Flags))
+	buf.WriteString(" ")
+	buf.WriteString(caa.Tag)
+	buf.WriteString(" ")
+	buf.WriteString(quoteCharString(caa.Value))
+	return buf.String()
+}
+
+//issuer critical flag means the ca must understand the tag
+func (caa *CAA) IsCritical() bool {
+	return caa.Flags&CAA_FLAG_CRITICAL != 0
+}
+
+func isValidCAATag(tag string) bool {
+	if len(tag) == 0 || len(tag) > 255 {
+		return false
+	}
+
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func CAAFromWire(buf *util.InputBuffer, ll uint16) (*CAA, error) {
+	flags, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, ll, err := fieldFromWire(RDF_C_BYTE_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if isValidCAATag(string(tag.([]byte))) == false {
+		return nil, ErrInvalidCAATag
+	}
+
+	value, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &CAA{
+		Flags: flags.(uint8),
+		Tag:   string(tag.([]byte)),
+		Value: value.([]byte),
+	}, nil
+}
+
+func CAAFromString(s string) (*CAA, error) {
+	fields, err := splitRdataFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) != 3 {
+		return nil, errors.New("caa should has flags, tag and value")
+	}
+
+	flags, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if flags.(int) < 0 || flags.(int) > 255 {
+		return nil, ErrOutOfRange
+	}
+
+	if isValidCAATag(fields[1]) == false {
+		return nil, ErrInvalidCAATag
+	}
+
+	value := fields[2]
+	if strings.HasPrefix(value, "\"") {
+		if len(value) < 2 || strings.HasSuffix(value, "\"") == false {
+			return nil, ErrQuoteInTxtIsNotInPair
+		}
+		value = value[1 : len(value)-1]
+	}
+
+	v, err := unescapeCharString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CAA{
+		Flags: uint8(flags.(int)),
+		Tag:   fields[1],
+		Value: v,
+	}, nil
+}
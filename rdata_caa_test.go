@@ -0,0 +1,65 @@
+package g53
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func TestCAA(t *testing.T) {
+	cases := []struct {
+		str     string
+		display string
+	}{
+		{`0 issue "ca.example.net"`, `0 issue "ca.example.net"`},
+		{`0 issue ca.example.net`, `0 issue "ca.example.net"`},
+		{`0 issue "ca.example.net; account=230123"`, `0 issue "ca.example.net; account=230123"`},
+		{`0 issuewild ";"`, `0 issuewild ";"`},
+		{`0 iodef "mailto:security@example.com"`, `0 iodef "mailto:security@example.com"`},
+		{`128 tbs "Unknown"`, `128 tbs "Unknown"`},
+		{`0 issue ""`, `0 issue ""`},
+		{`0 issue "say \"hi\"\010"`, `0 issue "say \"hi\"\010"`},
+	}
+
+	for _, c := range cases {
+		rdata, err := RdataFromString(RR_CAA, c.str)
+		Assert(t, err == nil, "parse %s failed:%v", c.str, err)
+		Equal(t, rdata.String(), c.display)
+
+		render := NewMsgRender()
+		rdata.Rend(render)
+		wire := render.Data()
+		l := len(wire)
+		rdata2, err := RdataFromWire(RR_CAA, util.NewInputBuffer(append([]byte{byte(l >> 8), byte(l)}, wire...)))
+		Assert(t, err == nil, "parse %s from wire failed:%v", c.str, err)
+		Equal(t, rdata.Compare(rdata2), 0)
+	}
+
+	rdata, _ := RdataFromString(RR_CAA, `128 issue "ca.example.net"`)
+	caa := rdata.(*CAA)
+	Assert(t, caa.IsCritical(), "flag 128 should be issuer critical")
+	Equal(t, caa.Tag, "issue")
+	Equal(t, string(caa.Value), "ca.example.net")
+	render := NewMsgRender()
+	caa.Rend(render)
+	Equal(t, hex.EncodeToString(render.Data()), "80056973737565"+hex.EncodeToString([]byte("ca.example.net")))
+
+	for _, s := range []string{
+		`0 issue`,
+		`0 is-sue "ca.example.net"`,
+		`0 "" "ca.example.net"`,
+		`256 issue "ca.example.net"`,
+		`0 issue "ca.example.net`,
+		`0 issue ca.example.net extra`,
+	} {
+		_, err := RdataFromString(RR_CAA, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+
+	for _, s := range []string{"0001" + "00", "0004" + "000269", "0004" + "00012d61"} {
+		wire, _ := util.HexStrToBytes(s)
+		_, err := RdataFromWire(RR_CAA, util.NewInputBuffer(wire))
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
@@ -0,0 +1,36 @@
+package g53
+
+import (
+	"github.com/ben-han-cn/g53/util"
+)
+
+//cdnskey has the same rdata format with dnskey, defined in rfc7344
+type CDNSKey struct {
+	DNSKey
+}
+
+func (k *CDNSKey) Compare(other Rdata) int {
+	return k.DNSKey.Compare(&other.(*CDNSKey).DNSKey)
+}
+
+//cdnskey "0 3 0 AA==" means the parent should remove all the ds (rfc8078)
+func (k *CDNSKey) IsDelete() bool {
+	return k.Flags == 0 && k.Protocol == DNSKEY_PROTOCOL && k.Algorithm == 0 &&
+		len(k.PublicKey) == 1 && k.PublicKey[0] == 0
+}
+
+func CDNSKeyFromWire(buf *util.InputBuffer, ll uint16) (*CDNSKey, error) {
+	key, err := DNSKeyFromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &CDNSKey{*key}, nil
+}
+
+func CDNSKeyFromString(s string) (*CDNSKey, error) {
+	key, err := DNSKeyFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &CDNSKey{*key}, nil
+}
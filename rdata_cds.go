@@ -0,0 +1,112 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//cds has the same rdata format with ds, defined in rfc7344
+type CDS struct {
+	DS
+}
+
+func (cds *CDS) Compare(other Rdata) int {
+	return cds.DS.Compare(&other.(*CDS).DS)
+}
+
+//cds "0 0 0 00" means the parent should remove all the ds (rfc8078)
+func (cds *CDS) IsDelete() bool {
+	return cds.KeyTag == 0 && cds.Algorithm == 0 && cds.DigestType == 0 &&
+		bytes.Equal(encodeStringToHex(cds.Digest), []byte{0})
+}
+
+func CDSFromWire(buf *util.InputBuffer, ll uint16) (*CDS, error) {
+	ds, err := DSFromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &CDS{*ds}, nil
+}
+
+func CDSFromString(s string) (*CDS, error) {
+	ds, err := DSFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &CDS{*ds}, nil
+}
+
+var ErrDeleteCDSWithOthers = errors.New("delete cds shouldn't coexist with other cds")
+
+type DSUpdate struct {
+	Add    []*DS
+	Delete []*DS
+}
+
+func (u *DSUpdate) IsEmpty() bool {
+	return len(u.Add) == 0 && len(u.Delete) == 0
+}
+
+//compare the child cds rrset with the parent ds rrset, report the ds
+//which should be added to or deleted from the parent, ds could be nil
+//if parent has no ds
+func CompareCDSWithDS(cds *RRset, ds *RRset) (*DSUpdate, error) {
+	if cds.Type != RR_CDS {
+		return nil, errors.New("child rrset isn't cds")
+	}
+
+	if ds != nil && ds.Type != RR_DS {
+		return nil, errors.New("parent rrset isn't ds")
+	}
+
+	var parent []*DS
+	if ds != nil {
+		for _, rdata := range ds.Rdatas {
+			p, ok := rdata.(*DS)
+			if ok == false {
+				return nil, errors.New("parent rrset has rdata which isn't ds")
+			}
+			parent = append(parent, p)
+		}
+	}
+
+	var child []*DS
+	for _, rdata := range cds.Rdatas {
+		c, ok := rdata.(*CDS)
+		if ok == false {
+			return nil, errors.New("child rrset has rdata which isn't cds")
+		}
+		if c.IsDelete() {
+			if len(cds.Rdatas) != 1 {
+				return nil, ErrDeleteCDSWithOthers
+			}
+			return &DSUpdate{Delete: parent}, nil
+		}
+		child = append(child, &c.DS)
+	}
+
+	update := &DSUpdate{}
+	for _, c := range child {
+		if findDS(parent, c) == false {
+			update.Add = append(update.Add, c)
+		}
+	}
+
+	for _, p := range parent {
+		if findDS(child, p) == false {
+			update.Delete = append(update.Delete, p)
+		}
+	}
+	return update, nil
+}
+
+func findDS(dss []*DS, target *DS) bool {
+	for _, ds := range dss {
+		if ds.Compare(target) == 0 {
+			return true
+		}
+	}
+	return false
+}
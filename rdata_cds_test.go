@@ -0,0 +1,114 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func TestCDSAndCDNSKey(t *testing.T) {
+	rdata, err := RdataFromString(RR_CDS, "20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D")
+	Assert(t, err == nil, "parse cds failed:%v", err)
+	Equal(t, rdata.String(), "20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D")
+	Assert(t, rdata.(*CDS).IsDelete() == false, "normal cds isn't delete")
+
+	render := NewMsgRender()
+	render.WriteUint16(36)
+	rdata.Rend(render)
+	rdata2, err := RdataFromWire(RR_CDS, util.NewInputBuffer(render.Data()))
+	Assert(t, err == nil, "parse cds from wire failed:%v", err)
+	Equal(t, rdata.Compare(rdata2), 0)
+
+	rdata, _ = RdataFromString(RR_CDS, "0 0 0 00")
+	Assert(t, rdata.(*CDS).IsDelete(), "cds 0 0 0 00 is delete")
+
+	rdata, err = RdataFromString(RR_CDNSKEY, rootKSK)
+	Assert(t, err == nil, "parse cdnskey failed:%v", err)
+	Equal(t, rdata.String(), rootKSK)
+	Equal(t, rdata.(*CDNSKey).KeyTag(), uint16(20326))
+	rdata, _ = RdataFromString(RR_CDNSKEY, "0 3 0 AA==")
+	Assert(t, rdata.(*CDNSKey).IsDelete(), "cdnskey 0 3 0 AA== is delete")
+}
+
+func TestCSync(t *testing.T) {
+	//rfc7477 2.3
+	rdata, err := RdataFromString(RR_CSYNC, "66 3 A NS AAAA")
+	Assert(t, err == nil, "parse csync failed:%v", err)
+	Equal(t, rdata.String(), "66 3 A NS AAAA")
+	csync := rdata.(*CSync)
+	Assert(t, csync.IsImmediate() && csync.IsSOAMinimum(), "both flags are set")
+
+	render := NewMsgRender()
+	rdata.Rend(render)
+	wire, _ := util.HexStrToBytes("000000420003" + "000460000008")
+	WireMatch(t, wire, render.Data())
+
+	rdata2, err := RdataFromWire(RR_CSYNC, util.NewInputBuffer(append([]byte{0, byte(len(wire))}, wire...)))
+	Assert(t, err == nil, "parse csync from wire failed:%v", err)
+	Equal(t, rdata2, rdata)
+
+	rdata, err = RdataFromString(RR_CSYNC, "4294967295 0")
+	Assert(t, err == nil, "csync without types is valid:%v", err)
+	Equal(t, rdata.String(), "4294967295 0")
+
+	for _, s := range []string{"66", "4294967296 3 A", "66 65536 A", "66 3 XYZ"} {
+		_, err := RdataFromString(RR_CSYNC, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
+
+func TestCompareCDSWithDS(t *testing.T) {
+	ds, _ := RRsetFromString("example.com. 3600 IN DS 1 8 2 aabb")
+	ds2, _ := RRsetFromString("example.com. 3600 IN DS 2 8 2 ccdd")
+	ds.Rdatas = append(ds.Rdatas, ds2.Rdatas[0])
+
+	cds, _ := RRsetFromString("example.com. 3600 IN CDS 2 8 2 CCDD")
+	cds2, _ := RRsetFromString("example.com. 3600 IN CDS 3 13 2 eeff")
+	cds.Rdatas = append(cds.Rdatas, cds2.Rdatas[0])
+
+	update, err := CompareCDSWithDS(cds, ds)
+	Assert(t, err == nil, "compare failed:%v", err)
+	Equal(t, len(update.Add), 1)
+	Equal(t, update.Add[0].KeyTag, uint16(3))
+	Equal(t, len(update.Delete), 1)
+	Equal(t, update.Delete[0].KeyTag, uint16(1))
+
+	update, err = CompareCDSWithDS(cds, nil)
+	Assert(t, err == nil, "compare failed:%v", err)
+	Equal(t, len(update.Add), 2)
+	Equal(t, len(update.Delete), 0)
+
+	update, _ = CompareCDSWithDS(cds, cds)
+	Assert(t, update == nil, "ds rrset type should be checked")
+
+	ds.Rdatas = ds.Rdatas[1:]
+	ds.Rdatas = append(ds.Rdatas, &cds2.Rdatas[0].(*CDS).DS)
+	update, _ = CompareCDSWithDS(cds, ds)
+	Assert(t, update.IsEmpty(), "cds is same with ds")
+
+	deleteCDS, _ := RRsetFromString("example.com. 3600 IN CDS 0 0 0 00")
+	update, err = CompareCDSWithDS(deleteCDS, ds)
+	Assert(t, err == nil, "compare failed:%v", err)
+	Equal(t, len(update.Add), 0)
+	Equal(t, len(update.Delete), 2)
+
+	deleteCDS.Rdatas = append(deleteCDS.Rdatas, cds2.Rdatas[0])
+	_, err = CompareCDSWithDS(deleteCDS, ds)
+	Equal(t, err, ErrDeleteCDSWithOthers)
+
+	//rdata which doesn't match rrset type
+	ds.Rdatas = append(ds.Rdatas, cds2.Rdatas[0])
+	_, err = CompareCDSWithDS(cds, ds)
+	Assert(t, err != nil, "cds in ds rrset should be rejected")
+	cds.Rdatas = append(cds.Rdatas, ds2.Rdatas[0])
+	_, err = CompareCDSWithDS(cds, nil)
+	Assert(t, err != nil, "ds in cds rrset should be rejected")
+
+	//digest is compared in wire format
+	lower := &DS{KeyTag: 2, Algorithm: 8, DigestType: 2, Digest: "ccdd"}
+	upper := &DS{KeyTag: 2, Algorithm: 8, DigestType: 2, Digest: "CCDD"}
+	Equal(t, lower.Compare(upper), 0)
+	Assert(t, findDS([]*DS{lower}, upper), "ds should be found regardless of digest case")
+	Assert(t, (&DS{KeyTag: 2, Algorithm: 8, DigestType: 2, Digest: "0a"}).Compare(&DS{KeyTag: 2, Algorithm: 8, DigestType: 2, Digest: "0B"}) < 0,
+		"digest should be ordered by its bytes")
+}
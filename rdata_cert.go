@@ -0,0 +1,185 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//certificate types defined in rfc4398
+const (
+	CERT_PKIX    uint16 = 1
+	CERT_SPKI    uint16 = 2
+	CERT_PGP     uint16 = 3
+	CERT_IPKIX   uint16 = 4
+	CERT_ISPKI   uint16 = 5
+	CERT_IPGP    uint16 = 6
+	CERT_ACPKIX  uint16 = 7
+	CERT_IACPKIX uint16 = 8
+	CERT_URI     uint16 = 253
+	CERT_OID     uint16 = 254
+)
+
+var certTypeMnemonics = map[uint16]string{
+	CERT_PKIX:    "PKIX",
+	CERT_SPKI:    "SPKI",
+	CERT_PGP:     "PGP",
+	CERT_IPKIX:   "IPKIX",
+	CERT_ISPKI:   "ISPKI",
+	CERT_IPGP:    "IPGP",
+	CERT_ACPKIX:  "ACPKIX",
+	CERT_IACPKIX: "IACPKIX",
+	CERT_URI:     "URI",
+	CERT_OID:     "OID",
+}
+
+type Cert struct {
+	Type        uint16
+	KeyTag      uint16
+	Algorithm   uint8
+	Certificate []byte
+}
+
+func (c *Cert) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, c.Type, r)
+	rendField(RDF_C_UINT16, c.KeyTag, r)
+	rendField(RDF_C_UINT8, c.Algorithm, r)
+	rendField(RDF_C_BINARY, c.Certificate, r)
+}
+
+func (c *Cert) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, c.Type, buf)
+	fieldToWire(RDF_C_UINT16, c.KeyTag, buf)
+	fieldToWire(RDF_C_UINT8, c.Algorithm, buf)
+	fieldToWire(RDF_C_BINARY, c.Certificate, buf)
+}
+
+func (c *Cert) Compare(other Rdata) int {
+	otherCert := other.(*Cert)
+	order := fieldCompare(RDF_C_UINT16, c.Type, otherCert.Type)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT16, c.KeyTag, otherCert.KeyTag)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, c.Algorithm, otherCert.Algorithm)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, c.Certificate, otherCert.Certificate)
+}
+
+func (c *Cert) String() string {
+	var buf bytes.Buffer
+	if mnemonic, ok := certTypeMnemonics[c.Type]; ok {
+		buf.WriteString(mnemonic)
+	} else {
+		buf.WriteString(fieldToString(RDF_D_INT, c.Type))
+	}
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, c.KeyTag))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, c.Algorithm))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_B64, c.Certificate))
+	return buf.String()
+}
+
+func certTypeFromString(s string) (uint16, error) {
+	for typ, mnemonic := range certTypeMnemonics {
+		if strings.EqualFold(mnemonic, s) {
+			return typ, nil
+		}
+	}
+
+	typ, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("unknown cert type %s", s)
+	}
+	return uint16(typ), nil
+}
+
+func CertFromWire(buf *util.InputBuffer, ll uint16) (*Cert, error) {
+	typ, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	keyTag, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &Cert{
+		Type:        typ.(uint16),
+		KeyTag:      keyTag.(uint16),
+		Algorithm:   algorithm.(uint8),
+		Certificate: certificate.([]byte),
+	}, nil
+}
+
+var certRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(.*?)\s*$`)
+var certDataTemplate = regexp.MustCompile(`\s+`)
+
+//type and algorithm could be mnemonic
+func CertFromString(s string) (*Cert, error) {
+	fields := certRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 5 {
+		return nil, errors.New("short of fields for cert")
+	}
+
+	fields = fields[1:]
+	typ, err := certTypeFromString(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	keyTag, err := fieldFromString(RDF_D_INT, fields[1])
+	if err != nil {
+		return nil, err
+	} else if keyTag.(int) < 0 || keyTag.(int) > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	algorithm, err := algorithmFromString(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := fieldFromString(RDF_D_B64, certDataTemplate.ReplaceAllString(fields[3], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cert{
+		Type:        typ,
+		KeyTag:      uint16(keyTag.(int)),
+		Algorithm:   algorithm,
+		Certificate: certificate.([]byte),
+	}, nil
+}
@@ -0,0 +1,133 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"regexp"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//csync flags defined in rfc7477
+const (
+	CSYNC_FLAG_IMMEDIATE  uint16 = 0x0001
+	CSYNC_FLAG_SOAMINIMUM uint16 = 0x0002
+)
+
+type CSync struct {
+	Serial uint32
+	Flags  uint16
+	Types  []RRType
+}
+
+func (c *CSync) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT32, c.Serial, r)
+	rendField(RDF_C_UINT16, c.Flags, r)
+	rendField(RDF_C_BINARY, encodeTypeBitmap(c.Types), r)
+}
+
+func (c *CSync) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT32, c.Serial, buf)
+	fieldToWire(RDF_C_UINT16, c.Flags, buf)
+	fieldToWire(RDF_C_BINARY, encodeTypeBitmap(c.Types), buf)
+}
+
+func (c *CSync) Compare(other Rdata) int {
+	otherCSync := other.(*CSync)
+	order := fieldCompare(RDF_C_UINT32, c.Serial, otherCSync.Serial)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT16, c.Flags, otherCSync.Flags)
+	if order != 0 {
+		return order
+	}
+
+	return compareTypeBitmap(c.Types, otherCSync.Types)
+}
+
+func (c *CSync) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, c.Serial))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, c.Flags))
+	buf.WriteString(typeBitmapToString(c.Types))
+	return buf.String()
+}
+
+func (c *CSync) IsImmediate() bool {
+	return c.Flags&CSYNC_FLAG_IMMEDIATE != 0
+}
+
+func (c *CSync) IsSOAMinimum() bool {
+	return c.Flags&CSYNC_FLAG_SOAMINIMUM != 0
+}
+
+func CSyncFromWire(buf *util.InputBuffer, ll uint16) (*CSync, error) {
+	serial, ll, err := fieldFromWire(RDF_C_UINT32, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	types, err := decodeTypeBitmap(bitmap.([]byte))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CSync{
+		Serial: serial.(uint32),
+		Flags:  flags.(uint16),
+		Types:  types,
+	}, nil
+}
+
+var csyncRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*(.*?)\s*$`)
+
+func CSyncFromString(s string) (*CSync, error) {
+	fields := csyncRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 4 {
+		return nil, errors.New("short of fields for csync")
+	}
+
+	fields = fields[1:]
+	serial, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if serial.(int) < 0 || int64(serial.(int)) > math.MaxUint32 {
+		return nil, ErrOutOfRange
+	}
+
+	flags, err := fieldFromString(RDF_D_INT, fields[1])
+	if err != nil {
+		return nil, err
+	} else if flags.(int) < 0 || flags.(int) > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	types, err := typeBitmapFromString(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &CSync{
+		Serial: uint32(serial.(int)),
+		Flags:  uint16(flags.(int)),
+		Types:  types,
+	}, nil
+}
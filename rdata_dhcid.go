@@ -0,0 +1,102 @@
+package g53
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//identifier type and digest type defined in rfc4701
+const (
+	DHCID_TYPE_HTYPE_CHADDR uint16 = 0
+	DHCID_TYPE_CLIENT_ID    uint16 = 1
+	DHCID_TYPE_DUID         uint16 = 2
+)
+
+const DHCID_DIGEST_SHA256 uint8 = 1
+
+//identifier type, digest type and at least one octet digest
+const dhcidMinLen = 4
+
+var ErrInvalidDHCID = errors.New("dhcid is too short")
+
+type DHCID struct {
+	Digest []byte
+}
+
+func (dhcid *DHCID) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, dhcid.Digest, r)
+}
+
+func (dhcid *DHCID) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, dhcid.Digest, buf)
+}
+
+func (dhcid *DHCID) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, dhcid.Digest, other.(*DHCID).Digest)
+}
+
+func (dhcid *DHCID) String() string {
+	return fieldToString(RDF_D_B64, dhcid.Digest)
+}
+
+//digest is sha256 over the identifier followed by the fqdn in canonical
+//wire format, the identifier for each type is
+//DHCID_TYPE_HTYPE_CHADDR: htype and chaddr in dhcpv4 message
+//DHCID_TYPE_CLIENT_ID: data of dhcpv4 client identifier option
+//DHCID_TYPE_DUID: client duid in dhcpv6 message or dhcpv4 client
+//identifier option
+func NewDHCID(identifierType uint16, identifier []byte, fqdn *Name) *DHCID {
+	h := sha256.New()
+	h.Write(identifier)
+	h.Write(downcasedName(fqdn).raw)
+
+	buf := util.NewOutputBuffer(3 + sha256.Size)
+	buf.WriteUint16(identifierType)
+	buf.WriteUint8(DHCID_DIGEST_SHA256)
+	buf.WriteData(h.Sum(nil))
+	return &DHCID{buf.Data()}
+}
+
+func NewDHCIDFromHardwareAddr(htype uint8, chaddr net.HardwareAddr, fqdn *Name) *DHCID {
+	return NewDHCID(DHCID_TYPE_HTYPE_CHADDR, append([]byte{htype}, chaddr...), fqdn)
+}
+
+func (dhcid *DHCID) IdentifierType() uint16 {
+	return uint16(dhcid.Digest[0])<<8 | uint16(dhcid.Digest[1])
+}
+
+func (dhcid *DHCID) DigestType() uint8 {
+	return dhcid.Digest[2]
+}
+
+func DHCIDFromWire(buf *util.InputBuffer, ll uint16) (*DHCID, error) {
+	digest, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	if len(digest.([]byte)) < dhcidMinLen {
+		return nil, ErrInvalidDHCID
+	}
+	return &DHCID{digest.([]byte)}, nil
+}
+
+func DHCIDFromString(s string) (*DHCID, error) {
+	digest, err := fieldFromString(RDF_D_B64, hexDataTemplate.ReplaceAllString(strings.TrimSpace(s), ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(digest.([]byte)) < dhcidMinLen {
+		return nil, ErrInvalidDHCID
+	}
+	return &DHCID{digest.([]byte)}, nil
+}
@@ -0,0 +1,28 @@
+package g53
+
+import (
+	"net"
+	"testing"
+)
+
+//examples in rfc4701 section 3.6
+func TestDHCIDDigest(t *testing.T) {
+	mac, _ := net.ParseMAC("01:02:03:04:05:06")
+	dhcid := NewDHCIDFromHardwareAddr(1, mac, NameFromStringUnsafe("client.example.com."))
+	Equal(t, dhcid.String(), "AAABxLmlskllE0MVjd57zHcWmEH3pCQ6VytcKD//7es/deY=")
+	Equal(t, dhcid.IdentifierType(), DHCID_TYPE_HTYPE_CHADDR)
+	Equal(t, dhcid.DigestType(), DHCID_DIGEST_SHA256)
+
+	duid := []byte{0x00, 0x01, 0x00, 0x06, 0x41, 0x2d, 0xf1, 0x66, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	dhcid = NewDHCID(DHCID_TYPE_DUID, duid, NameFromStringUnsafe("chi6.example.com."))
+	Equal(t, dhcid.String(), "AAIBY2/AuCccgoJbsaxcQc9TUapptP69lOjxfNuVAA2kjEA=")
+	Equal(t, dhcid.IdentifierType(), DHCID_TYPE_DUID)
+
+	clientID := []byte{0x01, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c}
+	dhcid = NewDHCID(DHCID_TYPE_CLIENT_ID, clientID, NameFromStringUnsafe("chi.example.com."))
+	Equal(t, dhcid.String(), "AAEBOSD+XR3Os/0LozeXVqcNc7FwCfQdWL3b/NaiUDlW2No=")
+
+	//fqdn is in canonical form
+	dhcid2 := NewDHCID(DHCID_TYPE_CLIENT_ID, clientID, NameFromStringUnsafe("CHI.Example.com."))
+	Equal(t, dhcid.Compare(dhcid2), 0)
+}
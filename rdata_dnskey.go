@@ -0,0 +1,242 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const (
+	DNSKEY_FLAG_ZONE   uint16 = 0x0100
+	DNSKEY_FLAG_REVOKE uint16 = 0x0080 //rfc5011
+	DNSKEY_FLAG_SEP    uint16 = 0x0001
+)
+
+const DNSKEY_PROTOCOL uint8 = 3
+
+//dnssec algorithm numbers
+const (
+	ALGORITHM_RSAMD5             uint8 = 1
+	ALGORITHM_DH                 uint8 = 2
+	ALGORITHM_DSA                uint8 = 3
+	ALGORITHM_RSASHA1            uint8 = 5
+	ALGORITHM_DSA_NSEC3_SHA1     uint8 = 6
+	ALGORITHM_RSASHA1_NSEC3_SHA1 uint8 = 7
+	ALGORITHM_RSASHA256          uint8 = 8
+	ALGORITHM_RSASHA512          uint8 = 10
+	ALGORITHM_ECC_GOST           uint8 = 12
+	ALGORITHM_ECDSAP256SHA256    uint8 = 13
+	ALGORITHM_ECDSAP384SHA384    uint8 = 14
+	ALGORITHM_ED25519            uint8 = 15
+	ALGORITHM_ED448              uint8 = 16
+	ALGORITHM_INDIRECT           uint8 = 252
+	ALGORITHM_PRIVATEDNS         uint8 = 253
+	ALGORITHM_PRIVATEOID         uint8 = 254
+)
+
+var algorithmMnemonics = map[uint8]string{
+	ALGORITHM_RSAMD5:             "RSAMD5",
+	ALGORITHM_DH:                 "DH",
+	ALGORITHM_DSA:                "DSA",
+	ALGORITHM_RSASHA1:            "RSASHA1",
+	ALGORITHM_DSA_NSEC3_SHA1:     "DSA-NSEC3-SHA1",
+	ALGORITHM_RSASHA1_NSEC3_SHA1: "RSASHA1-NSEC3-SHA1",
+	ALGORITHM_RSASHA256:          "RSASHA256",
+	ALGORITHM_RSASHA512:          "RSASHA512",
+	ALGORITHM_ECC_GOST:           "ECC-GOST",
+	ALGORITHM_ECDSAP256SHA256:    "ECDSAP256SHA256",
+	ALGORITHM_ECDSAP384SHA384:    "ECDSAP384SHA384",
+	ALGORITHM_ED25519:            "ED25519",
+	ALGORITHM_ED448:              "ED448",
+	ALGORITHM_INDIRECT:           "INDIRECT",
+	ALGORITHM_PRIVATEDNS:         "PRIVATEDNS",
+	ALGORITHM_PRIVATEOID:         "PRIVATEOID",
+}
+
+//algorithm could be number or mnemonic
+func algorithmFromString(s string) (uint8, error) {
+	for alg, mnemonic := range algorithmMnemonics {
+		if strings.EqualFold(mnemonic, s) {
+			return alg, nil
+		}
+	}
+
+	alg, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("unknown algorithm %s", s)
+	}
+	return uint8(alg), nil
+}
+
+type DNSKey struct {
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8
+	PublicKey []uint8
+}
+
+func (k *DNSKey) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, k.Flags, r)
+	rendField(RDF_C_UINT8, k.Protocol, r)
+	rendField(RDF_C_UINT8, k.Algorithm, r)
+	rendField(RDF_C_BINARY, k.PublicKey, r)
+}
+
+func (k *DNSKey) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, k.Flags, buf)
+	fieldToWire(RDF_C_UINT8, k.Protocol, buf)
+	fieldToWire(RDF_C_UINT8, k.Algorithm, buf)
+	fieldToWire(RDF_C_BINARY, k.PublicKey, buf)
+}
+
+func (k *DNSKey) Compare(other Rdata) int {
+	otherKey := other.(*DNSKey)
+	order := fieldCompare(RDF_C_UINT16, k.Flags, otherKey.Flags)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, k.Protocol, otherKey.Protocol)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, k.Algorithm, otherKey.Algorithm)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, k.PublicKey, otherKey.PublicKey)
+}
+
+func (k *DNSKey) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, k.Flags))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, k.Protocol))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, k.Algorithm))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_B64, k.PublicKey))
+	return buf.String()
+}
+
+func (k *DNSKey) IsZoneKey() bool {
+	return k.Flags&DNSKEY_FLAG_ZONE != 0
+}
+
+func (k *DNSKey) IsSEP() bool {
+	return k.Flags&DNSKEY_FLAG_SEP != 0
+}
+
+func (k *DNSKey) IsRevoked() bool {
+	return k.Flags&DNSKEY_FLAG_REVOKE != 0
+}
+
+//key tag calculation defined in rfc4034 appendix B
+func (k *DNSKey) KeyTag() uint16 {
+	if k.Algorithm == ALGORITHM_RSAMD5 {
+		//the most significant 16 bits of the least significant 24 bits of the modulus
+		if len(k.PublicKey) < 3 {
+			return 0
+		}
+		l := len(k.PublicKey)
+		return uint16(k.PublicKey[l-3])<<8 | uint16(k.PublicKey[l-2])
+	}
+
+	buf := util.NewOutputBuffer(uint(4 + len(k.PublicKey)))
+	k.ToWire(buf)
+	var ac uint32
+	for i, b := range buf.Data() {
+		if i&1 == 1 {
+			ac += uint32(b)
+		} else {
+			ac += uint32(b) << 8
+		}
+	}
+	ac += ac >> 16 & 0xffff
+	return uint16(ac & 0xffff)
+}
+
+func DNSKeyFromWire(buf *util.InputBuffer, ll uint16) (*DNSKey, error) {
+	flags, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &DNSKey{
+		Flags:     flags.(uint16),
+		Protocol:  protocol.(uint8),
+		Algorithm: algorithm.(uint8),
+		PublicKey: key.([]uint8),
+	}, nil
+}
+
+var dnskeyRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(.*?)\s*$`)
+var dnskeyPublicKeyTemplate = regexp.MustCompile(`\s+`)
+
+func DNSKeyFromString(s string) (*DNSKey, error) {
+	fields := dnskeyRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 5 {
+		return nil, errors.New("short of fields for dnskey")
+	}
+
+	fields = fields[1:]
+	flags, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if flags.(int) < 0 || flags.(int) > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	protocol, err := fieldFromString(RDF_D_INT, fields[1])
+	if err != nil {
+		return nil, err
+	} else if protocol.(int) < 0 || protocol.(int) > math.MaxUint8 {
+		return nil, ErrOutOfRange
+	}
+
+	algorithm, err := fieldFromString(RDF_D_INT, fields[2])
+	if err != nil {
+		return nil, err
+	} else if algorithm.(int) < 0 || algorithm.(int) > math.MaxUint8 {
+		return nil, ErrOutOfRange
+	}
+
+	key, err := fieldFromString(RDF_D_B64, dnskeyPublicKeyTemplate.ReplaceAllString(fields[3], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DNSKey{
+		Flags:     uint16(flags.(int)),
+		Protocol:  uint8(protocol.(int)),
+		Algorithm: uint8(algorithm.(int)),
+		PublicKey: key.([]uint8),
+	}, nil
+}
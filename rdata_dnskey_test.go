@@ -0,0 +1,43 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const rootKSK = "257 3 8 AwEAAaz/tAm8yTn4Mfeh5eyI96WSVexTBAvkMgJzkKTOiW1vkIbzxeF3+/4RgWOq7HrxRixHlFlExOLAJr5emLvN7SWXgnLh4+B5xQlNVz8Og8kvArMtNROxVQuCaSnIDdD5LKyWbRd2n9WGe2R8PzgCmr3EgVLrjyBxWezF0jLHwVN8efS3rCj/EWgvIWgb9tarpVUDK/b58Da+sqqls3eNbuv7pr+eoZG+SrDK6nWeL3c6H5Apxz7LjVc1uTIdsIXxuOLYA4/ilBmSVIzuDWfdRUfhHdY6+cn8HFRm+2hM8AnXGXws9555KrUB5qihylGa8subX2Nn6UwNR1AkUTV74bU="
+
+func TestDNSKey(t *testing.T) {
+	rdata, err := RdataFromString(RR_DNSKEY, rootKSK)
+	Assert(t, err == nil, "parse dnskey failed:%v", err)
+	key := rdata.(*DNSKey)
+	Equal(t, key.Flags, uint16(257))
+	Assert(t, key.IsZoneKey() && key.IsSEP() && !key.IsRevoked(), "root key should be ksk")
+	Equal(t, key.KeyTag(), uint16(20326))
+	Equal(t, key.String(), rootKSK)
+
+	//public key could be splitted by space
+	rdata2, err := RdataFromString(RR_DNSKEY, rootKSK[:40]+" "+rootKSK[40:100]+"  "+rootKSK[100:])
+	Assert(t, err == nil, "parse dnskey failed:%v", err)
+	Equal(t, rdata.Compare(rdata2), 0)
+
+	render := NewMsgRender()
+	key.Rend(render)
+	wire := render.Data()
+	rdata2, err = RdataFromWire(RR_DNSKEY, util.NewInputBuffer(append([]byte{byte(len(wire) >> 8), byte(len(wire))}, wire...)))
+	Assert(t, err == nil, "dnskey from wire failed:%v", err)
+	Equal(t, rdata2, rdata)
+
+	key.Flags = 256
+	Assert(t, rdata2.Compare(key) > 0, "ksk should bigger than zsk")
+
+	//rsamd5 key tag use the last bits of modulus
+	md5key := &DNSKey{Flags: 256, Protocol: 3, Algorithm: ALGORITHM_RSAMD5, PublicKey: []byte{1, 2, 3, 0xab, 0xcd, 0xef}}
+	Equal(t, md5key.KeyTag(), uint16(0xabcd))
+
+	for _, s := range []string{"65536 3 8 AwEAAQ==", "257 256 8 AwEAAQ==", "257 3 256 AwEAAQ==", "-1 3 8 AwEAAQ=="} {
+		_, err := DNSKeyFromString(s)
+		Equal(t, err, ErrOutOfRange)
+	}
+}
@@ -2,7 +2,11 @@ package g53
 
 import (
 	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -10,6 +14,16 @@ import (
 	"github.com/ben-han-cn/g53/util"
 )
 
+//digest type defined in rfc4034, rfc4509 and rfc6605
+const (
+	DS_DIGEST_SHA1   uint8 = 1
+	DS_DIGEST_SHA256 uint8 = 2
+	DS_DIGEST_GOST   uint8 = 3
+	DS_DIGEST_SHA384 uint8 = 4
+)
+
+var ErrUnsupportedDigestType = errors.New("ds digest type isn't supported")
+
 type DS struct {
 	KeyTag     uint16
 	Algorithm  uint8
@@ -29,6 +43,8 @@ func (ds *DS) String() string {
 	return buf.String()
 }
 
+//digest is compared in wire format, ds parsed from text keeps the hex
+//string in upper case and ds from wire in lower case, they are same
 func (ds *DS) Compare(other Rdata) int {
 	otherDS := other.(*DS)
 
@@ -47,7 +63,7 @@ func (ds *DS) Compare(other Rdata) int {
 		return order
 	}
 
-	return fieldCompare(RDF_C_BYTE_BINARY, []byte(ds.Digest), []byte(otherDS.Digest))
+	return fieldCompare(RDF_C_BINARY, encodeStringToHex(ds.Digest), encodeStringToHex(otherDS.Digest))
 }
 
 func (ds *DS) Rend(r *MsgRender) {
@@ -64,6 +80,54 @@ func (ds *DS) ToWire(buf *util.OutputBuffer) {
 	fieldToWire(RDF_C_BINARY, encodeStringToHex(ds.Digest), buf)
 }
 
+//generate ds for the dnskey, the digest is calculated over the canonical
+//owner name of the dnskey followed by the dnskey rdata
+func NewDS(owner *Name, dnskey *DNSKey, digestType uint8) (*DS, error) {
+	digest, err := dsDigest(owner, dnskey, digestType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DS{
+		KeyTag:     dnskey.KeyTag(),
+		Algorithm:  dnskey.Algorithm,
+		DigestType: digestType,
+		Digest:     hex.EncodeToString(digest),
+	}, nil
+}
+
+//check whether the ds refers to the dnskey, ds with unsupported digest
+//type never matches
+func (ds *DS) Match(owner *Name, dnskey *DNSKey) bool {
+	if ds.Algorithm != dnskey.Algorithm || ds.KeyTag != dnskey.KeyTag() {
+		return false
+	}
+
+	digest, err := dsDigest(owner, dnskey, ds.DigestType)
+	return err == nil && bytes.Equal(digest, encodeStringToHex(ds.Digest))
+}
+
+func dsDigest(owner *Name, dnskey *DNSKey, digestType uint8) ([]byte, error) {
+	buf := util.NewOutputBuffer(uint(owner.Length() + 4 + uint(len(dnskey.PublicKey))))
+	downcasedName(owner).ToWire(buf)
+	dnskey.ToWire(buf)
+	data := buf.Data()
+
+	switch digestType {
+	case DS_DIGEST_SHA1:
+		digest := sha1.Sum(data)
+		return digest[:], nil
+	case DS_DIGEST_SHA256:
+		digest := sha256.Sum256(data)
+		return digest[:], nil
+	case DS_DIGEST_SHA384:
+		digest := sha512.Sum384(data)
+		return digest[:], nil
+	default:
+		return nil, ErrUnsupportedDigestType
+	}
+}
+
 func DSFromWire(buf *util.InputBuffer, ll uint16) (*DS, error) {
 	keyTag, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
 	if err != nil {
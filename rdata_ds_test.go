@@ -35,3 +35,51 @@ func TestDSFromWire(t *testing.T) {
 	ds.Rend(render)
 	WireMatch(t, render.Data(), ds_wire)
 }
+
+func TestDSFromDNSKey(t *testing.T) {
+	dskey := "256 3 5 AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvxegXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9XzcnOf+EPbtG9DMBmADjFDc2w/rljwvFw=="
+	p384Key := "257 3 14 xKYaNhWdGOfJ+nPrL8/arkwf2EY3MDJ+SErKivBVSum1w/egsXvSADtNJhyem5RCOpgQ6K8X1DRSEkrbYQ+OB+v8/uX45NBwY8rp65F6Glur8I/mlVNgF6W/qTI37m40"
+	for _, c := range []struct {
+		owner      string
+		dnskey     string
+		digestType uint8
+		ds         string
+	}{
+		//rfc4034 section 5.4
+		{"dskey.example.com.", dskey, DS_DIGEST_SHA1, "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+		//rfc4509 section 2.3
+		{"dskey.example.com.", dskey, DS_DIGEST_SHA256, "60485 5 2 D4B7D520E7BB5F0F67674A0CCEB1E3E0614B93C4F9E99B8383F6A1E4469DA50A"},
+		{".", rootKSK, DS_DIGEST_SHA256, "20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D"},
+		//rfc6605 section 6.2
+		{"example.net.", p384Key, DS_DIGEST_SHA384, "10771 14 4 72D7B62976CE06438E9C0BF319013CF801F09ECC84B8D7E9495F27E305C6A9B0563A9B5F4D288405C3008A946DF983D6"},
+	} {
+		dnskey, err := DNSKeyFromString(c.dnskey)
+		Assert(t, err == nil, "parse dnskey failed:%v", err)
+		owner := NameFromStringUnsafe(c.owner)
+
+		ds, err := NewDS(owner, dnskey, c.digestType)
+		Assert(t, err == nil, "generate ds failed:%v", err)
+		Equal(t, ds.String(), c.ds)
+
+		ds, _ = DSFromString(strings.ToLower(c.ds))
+		Assert(t, ds.Match(owner, dnskey), "%s should match the dnskey", c.ds)
+		upperOwner, _ := NewName(strings.ToUpper(c.owner), false)
+		Assert(t, ds.Match(upperOwner, dnskey), "owner name is case insensitive")
+		Assert(t, ds.Match(NameFromStringUnsafe("other.example."), dnskey) == false, "%s shouldn't match other owner", c.ds)
+
+		render := NewMsgRender()
+		ds.Rend(render)
+		wireDS, err := DSFromWire(util.NewInputBuffer(render.Data()), uint16(render.Len()))
+		Assert(t, err == nil, "parse ds from wire failed:%v", err)
+		Assert(t, wireDS.Match(owner, dnskey), "%s from wire should match the dnskey", c.ds)
+
+		ds.Digest = ds.Digest[:len(ds.Digest)-2] + "00"
+		Assert(t, ds.Match(owner, dnskey) == false, "modified ds shouldn't match")
+	}
+
+	dnskey, _ := DNSKeyFromString(dskey)
+	_, err := NewDS(NameFromStringUnsafe("dskey.example.com."), dnskey, DS_DIGEST_GOST)
+	Equal(t, err, ErrUnsupportedDigestType)
+	ds, _ := DSFromString("60485 5 3 2BB183AF5F22588179A53B0A98631FAD1A292118")
+	Assert(t, ds.Match(NameFromStringUnsafe("dskey.example.com."), dnskey) == false, "unsupported digest type shouldn't match")
+}
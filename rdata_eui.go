@@ -0,0 +1,154 @@
+package g53
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//eui48 and eui64 defined in rfc7043, address is displayed as hex
+//octets separated by hyphen
+const (
+	EUI48_LEN = 6
+	EUI64_LEN = 8
+)
+
+type EUI48 struct {
+	Address net.HardwareAddr
+}
+
+func NewEUI48(addr net.HardwareAddr) (*EUI48, error) {
+	if len(addr) != EUI48_LEN {
+		return nil, fmt.Errorf("eui48 should has %d octets", EUI48_LEN)
+	}
+	return &EUI48{append(net.HardwareAddr(nil), addr...)}, nil
+}
+
+func (e *EUI48) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, []byte(e.Address), r)
+}
+
+func (e *EUI48) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, []byte(e.Address), buf)
+}
+
+func (e *EUI48) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, []byte(e.Address), []byte(other.(*EUI48).Address))
+}
+
+func (e *EUI48) String() string {
+	return euiToString(e.Address)
+}
+
+func (e *EUI48) HardwareAddr() net.HardwareAddr {
+	return append(net.HardwareAddr(nil), e.Address...)
+}
+
+func EUI48FromWire(buf *util.InputBuffer, ll uint16) (*EUI48, error) {
+	addr, err := euiFromWire(buf, ll, EUI48_LEN)
+	if err != nil {
+		return nil, err
+	}
+	return &EUI48{addr}, nil
+}
+
+func EUI48FromString(s string) (*EUI48, error) {
+	addr, err := euiFromString(s, EUI48_LEN)
+	if err != nil {
+		return nil, err
+	}
+	return &EUI48{addr}, nil
+}
+
+type EUI64 struct {
+	Address net.HardwareAddr
+}
+
+func NewEUI64(addr net.HardwareAddr) (*EUI64, error) {
+	if len(addr) != EUI64_LEN {
+		return nil, fmt.Errorf("eui64 should has %d octets", EUI64_LEN)
+	}
+	return &EUI64{append(net.HardwareAddr(nil), addr...)}, nil
+}
+
+func (e *EUI64) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, []byte(e.Address), r)
+}
+
+func (e *EUI64) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, []byte(e.Address), buf)
+}
+
+func (e *EUI64) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, []byte(e.Address), []byte(other.(*EUI64).Address))
+}
+
+func (e *EUI64) String() string {
+	return euiToString(e.Address)
+}
+
+func (e *EUI64) HardwareAddr() net.HardwareAddr {
+	return append(net.HardwareAddr(nil), e.Address...)
+}
+
+func EUI64FromWire(buf *util.InputBuffer, ll uint16) (*EUI64, error) {
+	addr, err := euiFromWire(buf, ll, EUI64_LEN)
+	if err != nil {
+		return nil, err
+	}
+	return &EUI64{addr}, nil
+}
+
+func EUI64FromString(s string) (*EUI64, error) {
+	addr, err := euiFromString(s, EUI64_LEN)
+	if err != nil {
+		return nil, err
+	}
+	return &EUI64{addr}, nil
+}
+
+func euiToString(addr net.HardwareAddr) string {
+	octets := make([]string, len(addr))
+	for i, b := range addr {
+		octets[i] = hex.EncodeToString([]byte{b})
+	}
+	return strings.Join(octets, "-")
+}
+
+func euiFromWire(buf *util.InputBuffer, ll uint16, l int) (net.HardwareAddr, error) {
+	if int(ll) != l {
+		return nil, fmt.Errorf("eui rdata length should be %d", l)
+	}
+
+	addr, _, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return net.HardwareAddr(addr.([]byte)), nil
+}
+
+//only xx-xx-xx-xx-xx-xx format is accepted
+func euiFromString(s string, l int) (net.HardwareAddr, error) {
+	octets := strings.Split(strings.TrimSpace(s), "-")
+	if len(octets) != l {
+		return nil, fmt.Errorf("eui should has %d hyphen separated octets", l)
+	}
+
+	addr := make(net.HardwareAddr, l)
+	for i, octet := range octets {
+		if len(octet) != 2 {
+			return nil, errors.New("eui octet should be two hex digits")
+		}
+
+		b, err := hex.DecodeString(octet)
+		if err != nil {
+			return nil, err
+		}
+		addr[i] = b[0]
+	}
+	return addr, nil
+}
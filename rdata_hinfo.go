@@ -0,0 +1,81 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+type HInfo struct {
+	CPU string
+	OS  string
+}
+
+func (h *HInfo) Rend(r *MsgRender) {
+	rendField(RDF_C_BYTE_BINARY, []byte(h.CPU), r)
+	rendField(RDF_C_BYTE_BINARY, []byte(h.OS), r)
+}
+
+func (h *HInfo) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BYTE_BINARY, []byte(h.CPU), buf)
+	fieldToWire(RDF_C_BYTE_BINARY, []byte(h.OS), buf)
+}
+
+func (h *HInfo) Compare(other Rdata) int {
+	otherHInfo := other.(*HInfo)
+	order := fieldCompare(RDF_C_BYTE_BINARY, []byte(h.CPU), []byte(otherHInfo.CPU))
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BYTE_BINARY, []byte(h.OS), []byte(otherHInfo.OS))
+}
+
+func (h *HInfo) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(quoteCharString([]byte(h.CPU)))
+	buf.WriteByte(' ')
+	buf.WriteString(quoteCharString([]byte(h.OS)))
+	return buf.String()
+}
+
+func HInfoFromWire(buf *util.InputBuffer, ll uint16) (*HInfo, error) {
+	cpu, ll, err := fieldFromWire(RDF_C_BYTE_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	os, ll, err := fieldFromWire(RDF_C_BYTE_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &HInfo{string(cpu.([]byte)), string(os.([]byte))}, nil
+}
+
+func HInfoFromString(s string) (*HInfo, error) {
+	fields, err := splitRdataFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) != 2 {
+		return nil, errors.New("fields count for hinfo isn't 2")
+	}
+
+	var strs [2]string
+	for i, field := range fields {
+		d, err := charStringFromString(field)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = string(d)
+	}
+
+	return &HInfo{strs[0], strs[1]}, nil
+}
@@ -0,0 +1,197 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//host identity protocol defined in rfc8005, rendezvous servers
+//aren't compressed
+type HIP struct {
+	PKAlgorithm       uint8
+	HIT               []byte
+	PublicKey         []byte
+	RendezvousServers []*Name
+}
+
+func (hip *HIP) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, uint8(len(hip.HIT)), r)
+	rendField(RDF_C_UINT8, hip.PKAlgorithm, r)
+	rendField(RDF_C_UINT16, uint16(len(hip.PublicKey)), r)
+	rendField(RDF_C_BINARY, hip.HIT, r)
+	rendField(RDF_C_BINARY, hip.PublicKey, r)
+	for _, server := range hip.RendezvousServers {
+		rendField(RDF_C_NAME_UNCOMPRESS, server, r)
+	}
+}
+
+func (hip *HIP) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, uint8(len(hip.HIT)), buf)
+	fieldToWire(RDF_C_UINT8, hip.PKAlgorithm, buf)
+	fieldToWire(RDF_C_UINT16, uint16(len(hip.PublicKey)), buf)
+	fieldToWire(RDF_C_BINARY, hip.HIT, buf)
+	fieldToWire(RDF_C_BINARY, hip.PublicKey, buf)
+	for _, server := range hip.RendezvousServers {
+		fieldToWire(RDF_C_NAME_UNCOMPRESS, server, buf)
+	}
+}
+
+func (hip *HIP) Compare(other Rdata) int {
+	otherHIP := other.(*HIP)
+	order := fieldCompare(RDF_C_UINT8, uint8(len(hip.HIT)), uint8(len(otherHIP.HIT)))
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, hip.PKAlgorithm, otherHIP.PKAlgorithm)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT16, uint16(len(hip.PublicKey)), uint16(len(otherHIP.PublicKey)))
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_BINARY, hip.HIT, otherHIP.HIT)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_BINARY, hip.PublicKey, otherHIP.PublicKey)
+	if order != 0 {
+		return order
+	}
+
+	for i, server := range hip.RendezvousServers {
+		if i >= len(otherHIP.RendezvousServers) {
+			return 1
+		}
+
+		order = fieldCompare(RDF_C_NAME_UNCOMPRESS, server, otherHIP.RendezvousServers[i])
+		if order != 0 {
+			return order
+		}
+	}
+
+	if len(hip.RendezvousServers) < len(otherHIP.RendezvousServers) {
+		return -1
+	}
+	return 0
+}
+
+func (hip *HIP) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, hip.PKAlgorithm))
+	buf.WriteString(" ")
+	buf.WriteString(strings.ToUpper(fieldToString(RDF_D_HEX, hip.HIT)))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_B64, hip.PublicKey))
+	for _, server := range hip.RendezvousServers {
+		buf.WriteString(" ")
+		buf.WriteString(fieldToString(RDF_D_NAME, server))
+	}
+	return buf.String()
+}
+
+func HIPFromWire(buf *util.InputBuffer, ll uint16) (*HIP, error) {
+	hitLen, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	keyLen, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if hitLen.(uint8) == 0 || keyLen.(uint16) == 0 {
+		return nil, errors.New("hip hit and public key shouldn't be empty")
+	}
+
+	if uint32(hitLen.(uint8))+uint32(keyLen.(uint16)) > uint32(ll) {
+		return nil, errors.New("hip hit or public key is truncated")
+	}
+
+	hit, _, err := fieldFromWire(RDF_C_BINARY, buf, uint16(hitLen.(uint8)))
+	if err != nil {
+		return nil, err
+	}
+	ll -= uint16(hitLen.(uint8))
+
+	key, _, err := fieldFromWire(RDF_C_BINARY, buf, keyLen.(uint16))
+	if err != nil {
+		return nil, err
+	}
+	ll -= keyLen.(uint16)
+
+	var servers []*Name
+	for ll > 0 {
+		var server interface{}
+		server, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, server.(*Name))
+	}
+
+	return &HIP{
+		PKAlgorithm:       algorithm.(uint8),
+		HIT:               hit.([]byte),
+		PublicKey:         key.([]byte),
+		RendezvousServers: servers,
+	}, nil
+}
+
+func HIPFromString(s string) (*HIP, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return nil, errors.New("short of fields for hip")
+	}
+
+	algorithm, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if algorithm.(int) < 0 || algorithm.(int) > 255 {
+		return nil, ErrOutOfRange
+	}
+
+	hit, err := fieldFromString(RDF_D_HEX, fields[1])
+	if err != nil {
+		return nil, err
+	} else if len(hit.([]byte)) == 0 || len(hit.([]byte)) > 255 {
+		return nil, ErrOutOfRange
+	}
+
+	key, err := fieldFromString(RDF_D_B64, fields[2])
+	if err != nil {
+		return nil, err
+	} else if len(key.([]byte)) == 0 || len(key.([]byte)) > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	var servers []*Name
+	for _, field := range fields[3:] {
+		server, err := fieldFromString(RDF_D_NAME, field)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, server.(*Name))
+	}
+
+	return &HIP{
+		PKAlgorithm:       uint8(algorithm.(int)),
+		HIT:               hit.([]byte),
+		PublicKey:         key.([]byte),
+		RendezvousServers: servers,
+	}, nil
+}
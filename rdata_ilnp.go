@@ -0,0 +1,307 @@
+package g53
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//ilnp records defined in rfc6742, NodeID and Locator64 are displayed as
+//four colon separated 16 bits hex groups
+type NID struct {
+	Preference uint16
+	NodeID     uint64
+}
+
+func (n *NID) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, n.Preference, r)
+	rendField(RDF_C_BINARY, uint64ToBytes(n.NodeID), r)
+}
+
+func (n *NID) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, n.Preference, buf)
+	fieldToWire(RDF_C_BINARY, uint64ToBytes(n.NodeID), buf)
+}
+
+func (n *NID) Compare(other Rdata) int {
+	otherNID := other.(*NID)
+	order := fieldCompare(RDF_C_UINT16, n.Preference, otherNID.Preference)
+	if order != 0 {
+		return order
+	}
+	return fieldCompare(RDF_C_BINARY, uint64ToBytes(n.NodeID), uint64ToBytes(otherNID.NodeID))
+}
+
+func (n *NID) String() string {
+	return fieldToString(RDF_D_INT, n.Preference) + " " + ilnp64ToString(n.NodeID)
+}
+
+func NIDFromWire(buf *util.InputBuffer, ll uint16) (*NID, error) {
+	preference, id, err := ilnp64FromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &NID{preference, id}, nil
+}
+
+func NIDFromString(s string) (*NID, error) {
+	preference, id, err := ilnp64FromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &NID{preference, id}, nil
+}
+
+type L64 struct {
+	Preference uint16
+	Locator64  uint64
+}
+
+func (l *L64) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, l.Preference, r)
+	rendField(RDF_C_BINARY, uint64ToBytes(l.Locator64), r)
+}
+
+func (l *L64) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, l.Preference, buf)
+	fieldToWire(RDF_C_BINARY, uint64ToBytes(l.Locator64), buf)
+}
+
+func (l *L64) Compare(other Rdata) int {
+	otherL64 := other.(*L64)
+	order := fieldCompare(RDF_C_UINT16, l.Preference, otherL64.Preference)
+	if order != 0 {
+		return order
+	}
+	return fieldCompare(RDF_C_BINARY, uint64ToBytes(l.Locator64), uint64ToBytes(otherL64.Locator64))
+}
+
+func (l *L64) String() string {
+	return fieldToString(RDF_D_INT, l.Preference) + " " + ilnp64ToString(l.Locator64)
+}
+
+func L64FromWire(buf *util.InputBuffer, ll uint16) (*L64, error) {
+	preference, locator, err := ilnp64FromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &L64{preference, locator}, nil
+}
+
+func L64FromString(s string) (*L64, error) {
+	preference, locator, err := ilnp64FromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &L64{preference, locator}, nil
+}
+
+//Locator32 is displayed as ipv4 address
+type L32 struct {
+	Preference uint16
+	Locator32  net.IP
+}
+
+func (l *L32) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, l.Preference, r)
+	rendField(RDF_C_IPV4, l.Locator32, r)
+}
+
+func (l *L32) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, l.Preference, buf)
+	fieldToWire(RDF_C_IPV4, l.Locator32, buf)
+}
+
+func (l *L32) Compare(other Rdata) int {
+	otherL32 := other.(*L32)
+	order := fieldCompare(RDF_C_UINT16, l.Preference, otherL32.Preference)
+	if order != 0 {
+		return order
+	}
+	return fieldCompare(RDF_C_IPV4, l.Locator32, otherL32.Locator32)
+}
+
+func (l *L32) String() string {
+	return fieldToString(RDF_D_INT, l.Preference) + " " + fieldToString(RDF_D_IPV4, l.Locator32)
+}
+
+func L32FromWire(buf *util.InputBuffer, ll uint16) (*L32, error) {
+	preference, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	locator, ll, err := fieldFromWire(RDF_C_IPV4, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+	return &L32{preference.(uint16), locator.(net.IP)}, nil
+}
+
+var ilnpRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*$`)
+
+func L32FromString(s string) (*L32, error) {
+	fields := ilnpRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for l32 isn't 2")
+	}
+
+	preference, err := preferenceFromString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	locator, err := fieldFromString(RDF_D_IPV4, fields[2])
+	if err != nil {
+		return nil, err
+	}
+	return &L32{preference, locator.(net.IP)}, nil
+}
+
+//FQDN isn't compressed as rfc3597 requires
+type LP struct {
+	Preference uint16
+	FQDN       *Name
+}
+
+func (lp *LP) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, lp.Preference, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, lp.FQDN, r)
+}
+
+func (lp *LP) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, lp.Preference, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, lp.FQDN, buf)
+}
+
+func (lp *LP) Compare(other Rdata) int {
+	otherLP := other.(*LP)
+	order := fieldCompare(RDF_C_UINT16, lp.Preference, otherLP.Preference)
+	if order != 0 {
+		return order
+	}
+	return fieldCompare(RDF_C_NAME_UNCOMPRESS, lp.FQDN, otherLP.FQDN)
+}
+
+func (lp *LP) String() string {
+	return fieldToString(RDF_D_INT, lp.Preference) + " " + fieldToString(RDF_D_NAME, lp.FQDN)
+}
+
+func LPFromWire(buf *util.InputBuffer, ll uint16) (*LP, error) {
+	preference, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	fqdn, ll, err := fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+	return &LP{preference.(uint16), fqdn.(*Name)}, nil
+}
+
+func LPFromString(s string) (*LP, error) {
+	fields := ilnpRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for lp isn't 2")
+	}
+
+	preference, err := preferenceFromString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	fqdn, err := fieldFromString(RDF_D_NAME, fields[2])
+	if err != nil {
+		return nil, err
+	}
+	return &LP{preference, fqdn.(*Name)}, nil
+}
+
+func preferenceFromString(s string) (uint16, error) {
+	d, err := fieldFromString(RDF_D_INT, s)
+	if err != nil {
+		return 0, err
+	} else if d.(int) < 0 || d.(int) > math.MaxUint16 {
+		return 0, ErrOutOfRange
+	}
+	return uint16(d.(int)), nil
+}
+
+func uint64ToBytes(v uint64) []byte {
+	buf := util.NewOutputBuffer(8)
+	buf.WriteUint32(uint32(v >> 32))
+	buf.WriteUint32(uint32(v))
+	return buf.Data()
+}
+
+func ilnp64ToString(v uint64) string {
+	return fmt.Sprintf("%04x:%04x:%04x:%04x", v>>48, (v>>32)&0xffff, (v>>16)&0xffff, v&0xffff)
+}
+
+func ilnp64FromWire(buf *util.InputBuffer, ll uint16) (uint16, uint64, error) {
+	preference, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	high, ll, err := fieldFromWire(RDF_C_UINT32, buf, ll)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	low, ll, err := fieldFromWire(RDF_C_UINT32, buf, ll)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if ll != 0 {
+		return 0, 0, errors.New("extra data in rdata part")
+	}
+	return preference.(uint16), uint64(high.(uint32))<<32 | uint64(low.(uint32)), nil
+}
+
+func ilnp64FromString(s string) (uint16, uint64, error) {
+	fields := ilnpRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return 0, 0, errors.New("fields count isn't 2")
+	}
+
+	preference, err := preferenceFromString(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	groups := strings.Split(fields[2], ":")
+	if len(groups) != 4 {
+		return 0, 0, errors.New("64 bits identifier should has 4 colon separated groups")
+	}
+
+	var v uint64
+	for _, group := range groups {
+		if len(group) == 0 || len(group) > 4 {
+			return 0, 0, fmt.Errorf("invalid 16 bits hex group %s", group)
+		}
+
+		d, err := strconv.ParseUint(group, 16, 16)
+		if err != nil {
+			return 0, 0, err
+		}
+		v = v<<16 | d
+	}
+	return preference, v, nil
+}
@@ -0,0 +1,221 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//gateway types defined in rfc4025
+const (
+	IPSECKEY_GATEWAY_NONE uint8 = 0
+	IPSECKEY_GATEWAY_IPV4 uint8 = 1
+	IPSECKEY_GATEWAY_IPV6 uint8 = 2
+	IPSECKEY_GATEWAY_NAME uint8 = 3
+)
+
+var ErrUnknownGatewayType = errors.New("unknown ipseckey gateway type")
+
+//only one of GatewayAddr and GatewayName is used according to GatewayType
+type IPSecKey struct {
+	Precedence  uint8
+	GatewayType uint8
+	Algorithm   uint8
+	GatewayAddr net.IP
+	GatewayName *Name
+	PublicKey   []byte
+}
+
+func (k *IPSecKey) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, k.Precedence, r)
+	rendField(RDF_C_UINT8, k.GatewayType, r)
+	rendField(RDF_C_UINT8, k.Algorithm, r)
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_IPV4:
+		rendField(RDF_C_IPV4, k.GatewayAddr.To4(), r)
+	case IPSECKEY_GATEWAY_IPV6:
+		rendField(RDF_C_IPV6, k.GatewayAddr.To16(), r)
+	case IPSECKEY_GATEWAY_NAME:
+		rendField(RDF_C_NAME_UNCOMPRESS, k.GatewayName, r)
+	}
+	rendField(RDF_C_BINARY, k.PublicKey, r)
+}
+
+func (k *IPSecKey) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, k.Precedence, buf)
+	fieldToWire(RDF_C_UINT8, k.GatewayType, buf)
+	fieldToWire(RDF_C_UINT8, k.Algorithm, buf)
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_IPV4:
+		fieldToWire(RDF_C_IPV4, k.GatewayAddr.To4(), buf)
+	case IPSECKEY_GATEWAY_IPV6:
+		fieldToWire(RDF_C_IPV6, k.GatewayAddr.To16(), buf)
+	case IPSECKEY_GATEWAY_NAME:
+		fieldToWire(RDF_C_NAME_UNCOMPRESS, k.GatewayName, buf)
+	}
+	fieldToWire(RDF_C_BINARY, k.PublicKey, buf)
+}
+
+func (k *IPSecKey) Compare(other Rdata) int {
+	otherKey := other.(*IPSecKey)
+	for _, pair := range [][2]uint8{
+		{k.Precedence, otherKey.Precedence},
+		{k.GatewayType, otherKey.GatewayType},
+		{k.Algorithm, otherKey.Algorithm},
+	} {
+		if order := fieldCompare(RDF_C_UINT8, pair[0], pair[1]); order != 0 {
+			return order
+		}
+	}
+
+	var order int
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_IPV4, IPSECKEY_GATEWAY_IPV6:
+		order = fieldCompare(RDF_C_IPV6, k.GatewayAddr.To16(), otherKey.GatewayAddr.To16())
+	case IPSECKEY_GATEWAY_NAME:
+		order = fieldCompare(RDF_C_NAME_UNCOMPRESS, k.GatewayName, otherKey.GatewayName)
+	}
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, k.PublicKey, otherKey.PublicKey)
+}
+
+func (k *IPSecKey) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, k.Precedence))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, k.GatewayType))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, k.Algorithm))
+	buf.WriteString(" ")
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_IPV4, IPSECKEY_GATEWAY_IPV6:
+		buf.WriteString(k.GatewayAddr.String())
+	case IPSECKEY_GATEWAY_NAME:
+		buf.WriteString(fieldToString(RDF_D_NAME, k.GatewayName))
+	default:
+		buf.WriteString(".")
+	}
+
+	if len(k.PublicKey) > 0 {
+		buf.WriteString(" ")
+		buf.WriteString(fieldToString(RDF_D_B64, k.PublicKey))
+	}
+	return buf.String()
+}
+
+func IPSecKeyFromWire(buf *util.InputBuffer, ll uint16) (*IPSecKey, error) {
+	var header [3]uint8
+	for i := 0; i < 3; i++ {
+		d, left, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+		header[i], ll = d.(uint8), left
+	}
+
+	k := &IPSecKey{
+		Precedence:  header[0],
+		GatewayType: header[1],
+		Algorithm:   header[2],
+	}
+
+	var gateway interface{}
+	var err error
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_NONE:
+	case IPSECKEY_GATEWAY_IPV4:
+		gateway, ll, err = fieldFromWire(RDF_C_IPV4, buf, ll)
+	case IPSECKEY_GATEWAY_IPV6:
+		gateway, ll, err = fieldFromWire(RDF_C_IPV6, buf, ll)
+	case IPSECKEY_GATEWAY_NAME:
+		gateway, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	default:
+		return nil, ErrUnknownGatewayType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch g := gateway.(type) {
+	case net.IP:
+		k.GatewayAddr = g
+	case *Name:
+		k.GatewayName = g
+	}
+
+	key, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	k.PublicKey = key.([]byte)
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+	return k, nil
+}
+
+//public key is optional
+func IPSecKeyFromString(s string) (*IPSecKey, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return nil, errors.New("short of fields for ipseckey")
+	}
+
+	var header [3]uint8
+	for i := 0; i < 3; i++ {
+		d, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		header[i] = uint8(d)
+	}
+
+	k := &IPSecKey{
+		Precedence:  header[0],
+		GatewayType: header[1],
+		Algorithm:   header[2],
+	}
+
+	switch k.GatewayType {
+	case IPSECKEY_GATEWAY_NONE:
+		if fields[3] != "." {
+			return nil, fmt.Errorf("gateway should be . but get %s", fields[3])
+		}
+	case IPSECKEY_GATEWAY_IPV4:
+		ip, err := fieldFromString(RDF_D_IPV4, fields[3])
+		if err != nil {
+			return nil, err
+		}
+		k.GatewayAddr = ip.(net.IP)
+	case IPSECKEY_GATEWAY_IPV6:
+		ip, err := fieldFromString(RDF_D_IPV6, fields[3])
+		if err != nil {
+			return nil, err
+		}
+		k.GatewayAddr = ip.(net.IP)
+	case IPSECKEY_GATEWAY_NAME:
+		name, err := fieldFromString(RDF_D_NAME, fields[3])
+		if err != nil {
+			return nil, err
+		}
+		k.GatewayName = name.(*Name)
+	default:
+		return nil, ErrUnknownGatewayType
+	}
+
+	key, err := fieldFromString(RDF_D_B64, strings.Join(fields[4:], ""))
+	if err != nil {
+		return nil, err
+	}
+	k.PublicKey = key.([]byte)
+	return k, nil
+}
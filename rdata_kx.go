@@ -0,0 +1,88 @@
+package g53
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//kx defined in rfc2230, exchanger must not be compressed
+type KX struct {
+	Preference uint16
+	Exchanger  *Name
+}
+
+func (kx *KX) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, kx.Preference, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, kx.Exchanger, r)
+}
+
+func (kx *KX) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, kx.Preference, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, kx.Exchanger, buf)
+}
+
+func (kx *KX) Compare(other Rdata) int {
+	otherKX := other.(*KX)
+	order := fieldCompare(RDF_C_UINT16, kx.Preference, otherKX.Preference)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_NAME_UNCOMPRESS, kx.Exchanger, otherKX.Exchanger)
+}
+
+func (kx *KX) String() string {
+	return strings.Join([]string{
+		fieldToString(RDF_D_INT, kx.Preference),
+		fieldToString(RDF_D_NAME, kx.Exchanger)}, " ")
+}
+
+func KXFromWire(buf *util.InputBuffer, ll uint16) (*KX, error) {
+	f, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(uint16)
+
+	f, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	exchanger, _ := f.(*Name)
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &KX{preference, exchanger}, nil
+}
+
+var kxRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*$`)
+
+func KXFromString(s string) (*KX, error) {
+	fields := kxRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for kx isn't 2")
+	}
+
+	fields = fields[1:]
+	f, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(int)
+	if preference < 0 || preference > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	f, err = fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+	exchanger, _ := f.(*Name)
+	return &KX{uint16(preference), exchanger}, nil
+}
@@ -0,0 +1,376 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//location information defined in rfc1876
+const (
+	LOC_EQUATOR       uint32 = 1 << 31  //latitude and longitude of equator and prime meridian
+	LOC_ALTITUDE_BASE int64  = 10000000 //altitude in cm is relative to 100000m below the reference
+	LOC_DEFAULT_SIZE  uint8  = 0x12     //1m
+	LOC_DEFAULT_HP    uint8  = 0x16     //10000m
+	LOC_DEFAULT_VP    uint8  = 0x13     //10m
+)
+
+var (
+	ErrInvalidLOCPrecision = errors.New("loc size or precision isn't valid")
+	ErrInvalidLOCCoord     = errors.New("loc latitude or longitude isn't valid")
+	ErrInvalidLOCAltitude  = errors.New("loc altitude isn't valid")
+)
+
+type LOC struct {
+	Version   uint8
+	Size      uint8
+	HorizPre  uint8
+	VertPre   uint8
+	Latitude  uint32
+	Longitude uint32
+	Altitude  uint32
+}
+
+func (loc *LOC) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, loc.Version, r)
+	rendField(RDF_C_UINT8, loc.Size, r)
+	rendField(RDF_C_UINT8, loc.HorizPre, r)
+	rendField(RDF_C_UINT8, loc.VertPre, r)
+	rendField(RDF_C_UINT32, loc.Latitude, r)
+	rendField(RDF_C_UINT32, loc.Longitude, r)
+	rendField(RDF_C_UINT32, loc.Altitude, r)
+}
+
+func (loc *LOC) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, loc.Version, buf)
+	fieldToWire(RDF_C_UINT8, loc.Size, buf)
+	fieldToWire(RDF_C_UINT8, loc.HorizPre, buf)
+	fieldToWire(RDF_C_UINT8, loc.VertPre, buf)
+	fieldToWire(RDF_C_UINT32, loc.Latitude, buf)
+	fieldToWire(RDF_C_UINT32, loc.Longitude, buf)
+	fieldToWire(RDF_C_UINT32, loc.Altitude, buf)
+}
+
+func (loc *LOC) Compare(other Rdata) int {
+	otherLOC := other.(*LOC)
+	for _, pair := range [][2]uint8{
+		{loc.Version, otherLOC.Version},
+		{loc.Size, otherLOC.Size},
+		{loc.HorizPre, otherLOC.HorizPre},
+		{loc.VertPre, otherLOC.VertPre},
+	} {
+		if order := fieldCompare(RDF_C_UINT8, pair[0], pair[1]); order != 0 {
+			return order
+		}
+	}
+
+	for _, pair := range [][2]uint32{
+		{loc.Latitude, otherLOC.Latitude},
+		{loc.Longitude, otherLOC.Longitude},
+		{loc.Altitude, otherLOC.Altitude},
+	} {
+		if order := fieldCompare(RDF_C_UINT32, pair[0], pair[1]); order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+func (loc *LOC) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(locCoordToString(loc.Latitude, "N", "S"))
+	buf.WriteString(" ")
+	buf.WriteString(locCoordToString(loc.Longitude, "E", "W"))
+	buf.WriteString(" ")
+
+	alt := int64(loc.Altitude) - LOC_ALTITUDE_BASE
+	sign := ""
+	if alt < 0 {
+		sign = "-"
+		alt = -alt
+	}
+	fmt.Fprintf(&buf, "%s%d.%02dm", sign, alt/100, alt%100)
+
+	for _, p := range []uint8{loc.Size, loc.HorizPre, loc.VertPre} {
+		buf.WriteString(" ")
+		buf.WriteString(locCMToString(locPrecisionToCM(p)))
+	}
+	return buf.String()
+}
+
+//decimal degrees, south latitude is negative
+func (loc *LOC) LatitudeDegrees() float64 {
+	return float64(int64(loc.Latitude)-int64(LOC_EQUATOR)) / 3600000
+}
+
+//decimal degrees, west longitude is negative
+func (loc *LOC) LongitudeDegrees() float64 {
+	return float64(int64(loc.Longitude)-int64(LOC_EQUATOR)) / 3600000
+}
+
+func (loc *LOC) AltitudeMeters() float64 {
+	return float64(int64(loc.Altitude)-LOC_ALTITUDE_BASE) / 100
+}
+
+func (loc *LOC) SizeMeters() float64 {
+	return float64(locPrecisionToCM(loc.Size)) / 100
+}
+
+func (loc *LOC) HorizPreMeters() float64 {
+	return float64(locPrecisionToCM(loc.HorizPre)) / 100
+}
+
+func (loc *LOC) VertPreMeters() float64 {
+	return float64(locPrecisionToCM(loc.VertPre)) / 100
+}
+
+//size and precision is encoded as mantissa (high nibble) and power of
+//ten exponent (low nibble) in centimeters
+func locPrecisionToCM(p uint8) uint64 {
+	cm := uint64(p >> 4)
+	for i := uint8(0); i < p&0x0f; i++ {
+		cm *= 10
+	}
+	return cm
+}
+
+//value which can't be represented exactly like 12m is rounded to the
+//nearest one, 12m is 1e3 cm and 1.5m is 2e2 cm
+func locPrecisionFromCM(cm uint64) (uint8, error) {
+	var exp uint8
+	pow := uint64(1)
+	for cm >= pow*10 {
+		pow *= 10
+		exp += 1
+	}
+
+	mantissa := (cm + pow/2) / pow
+	if mantissa == 10 {
+		mantissa = 1
+		exp += 1
+	}
+
+	if exp > 9 {
+		return 0, ErrInvalidLOCPrecision
+	}
+	return uint8(mantissa)<<4 | exp, nil
+}
+
+func isValidLOCPrecision(p uint8) bool {
+	return p>>4 <= 9 && p&0x0f <= 9
+}
+
+func locCMToString(cm uint64) string {
+	if cm%100 == 0 {
+		return fmt.Sprintf("%dm", cm/100)
+	}
+	return fmt.Sprintf("%d.%02dm", cm/100, cm%100)
+}
+
+func locCoordToString(v uint32, positive, negative string) string {
+	hemisphere := positive
+	var d int64
+	if v >= LOC_EQUATOR {
+		d = int64(v - LOC_EQUATOR)
+	} else {
+		d = int64(LOC_EQUATOR - v)
+		hemisphere = negative
+	}
+
+	deg := d / 3600000
+	d %= 3600000
+	min := d / 60000
+	d %= 60000
+	return fmt.Sprintf("%d %d %d.%03d %s", deg, min, d/1000, d%1000, hemisphere)
+}
+
+//parse decimal number into integer with specified fraction digits
+//without loss, "1.5" with 2 digits is 150
+func parseFixedPoint(s string, digits int) (int64, error) {
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	if intPart == "" || len(fracPart) > digits || isDigits(intPart) == false || isDigits(fracPart) == false {
+		return 0, fmt.Errorf("invalid number %s", s)
+	}
+
+	fracPart += strings.Repeat("0", digits-len(fracPart))
+	v, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) == false {
+			return false
+		}
+	}
+	return true
+}
+
+//parse "d [m [s]] hemisphere", return the consumed token count
+func locCoordFromString(tokens []string, positive, negative string, maxDegree int64) (uint32, int, error) {
+	var parts []string
+	hemisphere := ""
+	for _, token := range tokens {
+		upper := strings.ToUpper(token)
+		if upper == positive || upper == negative {
+			hemisphere = upper
+			break
+		}
+		parts = append(parts, token)
+	}
+
+	if hemisphere == "" || len(parts) == 0 || len(parts) > 3 {
+		return 0, 0, ErrInvalidLOCCoord
+	}
+
+	var deg, min, ms int64
+	var err error
+	if deg, err = parseFixedPoint(parts[0], 0); err != nil || deg < 0 || deg > maxDegree {
+		return 0, 0, ErrInvalidLOCCoord
+	}
+
+	if len(parts) > 1 {
+		if min, err = parseFixedPoint(parts[1], 0); err != nil || min < 0 || min >= 60 {
+			return 0, 0, ErrInvalidLOCCoord
+		}
+	}
+
+	if len(parts) > 2 {
+		if ms, err = parseFixedPoint(parts[2], 3); err != nil || ms < 0 || ms >= 60000 {
+			return 0, 0, ErrInvalidLOCCoord
+		}
+	}
+
+	v := deg*3600000 + min*60000 + ms
+	if v > maxDegree*3600000 {
+		return 0, 0, ErrInvalidLOCCoord
+	}
+
+	if hemisphere == negative {
+		return LOC_EQUATOR - uint32(v), len(parts) + 1, nil
+	}
+	return LOC_EQUATOR + uint32(v), len(parts) + 1, nil
+}
+
+func locMetersFromString(s string) (int64, error) {
+	return parseFixedPoint(strings.TrimSuffix(strings.ToLower(s), "m"), 2)
+}
+
+func LOCFromWire(buf *util.InputBuffer, ll uint16) (*LOC, error) {
+	var header [4]uint8
+	for i := 0; i < 4; i++ {
+		b, left, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+		header[i], ll = b.(uint8), left
+	}
+
+	if header[0] != 0 {
+		return nil, fmt.Errorf("unsupported loc version %d", header[0])
+	}
+
+	for _, p := range header[1:] {
+		if isValidLOCPrecision(p) == false {
+			return nil, ErrInvalidLOCPrecision
+		}
+	}
+
+	var ints [3]uint32
+	for i := 0; i < 3; i++ {
+		d, left, err := fieldFromWire(RDF_C_UINT32, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+		ints[i], ll = d.(uint32), left
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &LOC{
+		Version:   header[0],
+		Size:      header[1],
+		HorizPre:  header[2],
+		VertPre:   header[3],
+		Latitude:  ints[0],
+		Longitude: ints[1],
+		Altitude:  ints[2],
+	}, nil
+}
+
+//size and precisions are rounded to the nearest value of the mantissa and
+//exponent encoding, so 12m is saved as 10m, values rounded beyond 90000000m
+//are invalid
+func LOCFromString(s string) (*LOC, error) {
+	tokens := strings.Fields(s)
+	lat, n, err := locCoordFromString(tokens, "N", "S", 90)
+	if err != nil {
+		return nil, err
+	}
+	tokens = tokens[n:]
+
+	long, n, err := locCoordFromString(tokens, "E", "W", 180)
+	if err != nil {
+		return nil, err
+	}
+	tokens = tokens[n:]
+
+	if len(tokens) == 0 || len(tokens) > 4 {
+		return nil, errors.New("loc should have altitude and at most three precisions")
+	}
+
+	alt, err := locMetersFromString(tokens[0])
+	if err != nil {
+		return nil, err
+	}
+	alt += LOC_ALTITUDE_BASE
+	if alt < 0 || alt > int64(^uint32(0)) {
+		return nil, ErrInvalidLOCAltitude
+	}
+
+	precisions := []uint8{LOC_DEFAULT_SIZE, LOC_DEFAULT_HP, LOC_DEFAULT_VP}
+	for i, token := range tokens[1:] {
+		cm, err := locMetersFromString(token)
+		if err != nil {
+			return nil, err
+		} else if cm < 0 {
+			return nil, ErrInvalidLOCPrecision
+		}
+
+		if precisions[i], err = locPrecisionFromCM(uint64(cm)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LOC{
+		Size:      precisions[0],
+		HorizPre:  precisions[1],
+		VertPre:   precisions[2],
+		Latitude:  lat,
+		Longitude: long,
+		Altitude:  uint32(alt),
+	}, nil
+}
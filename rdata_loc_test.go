@@ -0,0 +1,88 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func TestLOC(t *testing.T) {
+	//rfc1876 appendix
+	rdata, err := RdataFromString(RR_LOC, "42 21 54 N 71 06 18 W -24m 30m")
+	Assert(t, err == nil, "parse loc failed:%v", err)
+	Equal(t, rdata.String(), "42 21 54.000 N 71 6 18.000 W -24.00m 30m 10000m 10m")
+
+	render := NewMsgRender()
+	rdata.Rend(render)
+	wire, _ := util.HexStrToBytes("0033161389172dd070be15f000988d20")
+	WireMatch(t, wire, render.Data())
+
+	rdata2, err := RdataFromWire(RR_LOC, util.NewInputBuffer(append([]byte{0, 16}, wire...)))
+	Assert(t, err == nil, "parse loc from wire failed:%v", err)
+	Equal(t, rdata2, rdata)
+
+	loc := rdata.(*LOC)
+	Equal(t, loc.LatitudeDegrees(), 42.365)
+	Equal(t, loc.LongitudeDegrees(), -71.105)
+	Equal(t, loc.AltitudeMeters(), -24.0)
+	Equal(t, loc.SizeMeters(), 30.0)
+	Equal(t, loc.HorizPreMeters(), 10000.0)
+	Equal(t, loc.VertPreMeters(), 10.0)
+
+	cases := []struct {
+		str     string
+		display string
+	}{
+		{"42 21 43.952 N 71 5 6.344 W -24m 1m 200m 10m", "42 21 43.952 N 71 5 6.344 W -24.00m 1m 200m 10m"},
+		{"52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m", "52 22 23.000 N 4 53 32.000 E -2.00m 0m 10000m 10m"},
+		{"32 7 19 S 116 2 25 E 10m", "32 7 19.000 S 116 2 25.000 E 10.00m 1m 10000m 10m"},
+		{"0 N 0 E 0", "0 0 0.000 N 0 0 0.000 E 0.00m 1m 10000m 10m"},
+		{"90 S 180 W 42849672.95m 0.5m 90000000m 0.01m", "90 0 0.000 S 180 0 0.000 W 42849672.95m 0.50m 90000000m 0.01m"},
+		{"1 2 N 3 4 5.6 e -100000m", "1 2 0.000 N 3 4 5.600 E -100000.00m 1m 10000m 10m"},
+		//size and precision are rounded to the nearest representable value
+		{"1 N 2 E 0m 12m 1.5m 2.5m", "1 0 0.000 N 2 0 0.000 E 0.00m 10m 2m 3m"},
+		{"1 N 2 E 0m 0.14m 96m 89999999m", "1 0 0.000 N 2 0 0.000 E 0.00m 0.10m 100m 90000000m"},
+	}
+
+	for _, c := range cases {
+		rdata, err := RdataFromString(RR_LOC, c.str)
+		Assert(t, err == nil, "parse %s failed:%v", c.str, err)
+		Equal(t, rdata.String(), c.display)
+
+		rdata2, err := RdataFromString(RR_LOC, rdata.String())
+		Assert(t, err == nil, "parse %s failed:%v", rdata.String(), err)
+		Equal(t, rdata2, rdata)
+	}
+
+	for _, s := range []string{
+		"42 21 54 71 06 18 W -24m",
+		"42 21 54 N 71 06 18 W",
+		"91 N 71 06 18 W 0m",
+		"90 0 0.001 N 71 W 0m",
+		"42 60 N 71 W 0m",
+		"42 21 60 N 71 W 0m",
+		"42 21 1.0001 N 71 W 0m",
+		"42 N 181 W 0m",
+		"42 N 71 W -100000.01m",
+		"42 N 71 W 42849672.96m",
+		"42 N 71 W 0m 95000000m",
+		"42 N 71 W 0m -1m",
+		"42 N 71 W 0m 1m 1m 1m 1m",
+		"42 N 71 W 0.001m",
+		"-42 N 71 W 0m",
+		"42 1 2 3 N 71 W 0m",
+	} {
+		_, err := RdataFromString(RR_LOC, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+
+	for _, s := range []string{
+		"0133161389172dd070be15f000988d20",
+		"003a161389172dd070be15f000988d20",
+		"0033161389172dd070be15f000988d",
+	} {
+		wire, _ := util.HexStrToBytes(s)
+		_, err := RdataFromWire(RR_LOC, util.NewInputBuffer(append([]byte{0, byte(len(wire))}, wire...)))
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
@@ -0,0 +1,81 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//minfo defined in rfc1035, names are compressible
+type MInfo struct {
+	RMailbox *Name
+	EMailbox *Name
+}
+
+func (minfo *MInfo) Rend(r *MsgRender) {
+	rendField(RDF_C_NAME, minfo.RMailbox, r)
+	rendField(RDF_C_NAME, minfo.EMailbox, r)
+}
+
+func (minfo *MInfo) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_NAME, minfo.RMailbox, buf)
+	fieldToWire(RDF_C_NAME, minfo.EMailbox, buf)
+}
+
+func (minfo *MInfo) Compare(other Rdata) int {
+	ord := fieldCompare(RDF_C_NAME, minfo.RMailbox, other.(*MInfo).RMailbox)
+	if ord == 0 {
+		return fieldCompare(RDF_C_NAME, minfo.EMailbox, other.(*MInfo).EMailbox)
+	} else {
+		return ord
+	}
+}
+
+func (minfo *MInfo) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_NAME, minfo.RMailbox))
+	buf.WriteByte(' ')
+	buf.WriteString(fieldToString(RDF_D_NAME, minfo.EMailbox))
+	return buf.String()
+}
+
+func MInfoFromWire(buf *util.InputBuffer, ll uint16) (*MInfo, error) {
+	rmailbox, ll, err := fieldFromWire(RDF_C_NAME, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	emailbox, ll, err := fieldFromWire(RDF_C_NAME, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+	return &MInfo{rmailbox.(*Name), emailbox.(*Name)}, nil
+}
+
+var minfoRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*$`)
+
+func MInfoFromString(s string) (*MInfo, error) {
+	fields := minfoRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("short of fields for minfo")
+	}
+
+	fields = fields[1:]
+	rmailbox, err := fieldFromString(RDF_D_NAME, fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	emailbox, err := fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &MInfo{rmailbox.(*Name), emailbox.(*Name)}, nil
+}
@@ -0,0 +1,206 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+type NSEC struct {
+	NextDomain *Name
+	Types      []RRType
+}
+
+func (nsec *NSEC) Rend(r *MsgRender) {
+	rendField(RDF_C_NAME_UNCOMPRESS, nsec.NextDomain, r)
+	rendField(RDF_C_BINARY, encodeTypeBitmap(nsec.Types), r)
+}
+
+func (nsec *NSEC) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, nsec.NextDomain, buf)
+	fieldToWire(RDF_C_BINARY, encodeTypeBitmap(nsec.Types), buf)
+}
+
+func (nsec *NSEC) Compare(other Rdata) int {
+	otherNSEC := other.(*NSEC)
+	order := fieldCompare(RDF_C_NAME_UNCOMPRESS, nsec.NextDomain, otherNSEC.NextDomain)
+	if order != 0 {
+		return order
+	}
+
+	return compareTypeBitmap(nsec.Types, otherNSEC.Types)
+}
+
+func (nsec *NSEC) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_NAME, nsec.NextDomain))
+	buf.WriteString(typeBitmapToString(nsec.Types))
+	return buf.String()
+}
+
+func NSECFromWire(buf *util.InputBuffer, ll uint16) (*NSEC, error) {
+	next, ll, err := fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	types, err := decodeTypeBitmap(bitmap.([]byte))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NSEC{
+		NextDomain: next.(*Name),
+		Types:      types,
+	}, nil
+}
+
+var nsecRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s*(.*?)\s*$`)
+
+func NSECFromString(s string) (*NSEC, error) {
+	fields := nsecRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("short of fields for nsec")
+	}
+
+	fields = fields[1:]
+	next, err := fieldFromString(RDF_D_NAME, fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := typeBitmapFromString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &NSEC{
+		NextDomain: next.(*Name),
+		Types:      types,
+	}, nil
+}
+
+//type bitmap defined in rfc4034 4.1.2, shared by nsec and nsec3
+//types are sorted and deduplicated before encoding
+func encodeTypeBitmap(types []RRType) []byte {
+	if len(types) == 0 {
+		return []byte{}
+	}
+
+	sorted := make([]RRType, len(types))
+	copy(sorted, types)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	bitmap := make([]byte, 0, 2+32)
+	var window [32]byte
+	currentWindow, length := int(sorted[0]>>8), 0
+	flush := func() {
+		bitmap = append(bitmap, byte(currentWindow), byte(length))
+		bitmap = append(bitmap, window[:length]...)
+		window = [32]byte{}
+	}
+
+	for _, typ := range sorted {
+		w := int(typ >> 8)
+		if w != currentWindow {
+			flush()
+			currentWindow, length = w, 0
+		}
+
+		octet := int(typ&0xff) / 8
+		window[octet] |= byte(0x80 >> (typ % 8))
+		if octet+1 > length {
+			length = octet + 1
+		}
+	}
+	flush()
+	return bitmap
+}
+
+func decodeTypeBitmap(msg []byte) ([]RRType, error) {
+	var types []RRType
+	length, window, lastwindow := 0, 0, -1
+	offset := 0
+	for offset < len(msg) {
+		if offset+2 > len(msg) {
+			return nil, fmt.Errorf("overflow unpacking type bitmap")
+		}
+
+		window = int(msg[offset])
+		length = int(msg[offset+1])
+		offset += 2
+		if window <= lastwindow {
+			return nil, fmt.Errorf("out of order type bitmap block")
+		}
+
+		if length == 0 {
+			return nil, fmt.Errorf("empty type bitmap block")
+		}
+
+		if length > 32 {
+			return nil, fmt.Errorf("type bitmap block longer than 32")
+		}
+
+		if offset+length > len(msg) {
+			return nil, fmt.Errorf("overflowing type bitmap")
+		}
+
+		for j := 0; j < length; j++ {
+			b := msg[offset+j]
+			base := window*256 + j*8
+			for i := 7; i >= 0; i-- {
+				if (b>>uint16(i))&0x01 != 0 {
+					types = append(types, RRType(base+7-i))
+				}
+			}
+		}
+		offset += length
+		lastwindow = window
+	}
+
+	return types, nil
+}
+
+func compareTypeBitmap(types1, types2 []RRType) int {
+	return fieldCompare(RDF_C_BINARY, encodeTypeBitmap(types1), encodeTypeBitmap(types2))
+}
+
+var typeBitmapTemplate = regexp.MustCompile(`\s+`)
+
+func typeBitmapFromString(s string) ([]RRType, error) {
+	var types []RRType
+	if s == "" {
+		return types, nil
+	}
+
+	for _, field := range typeBitmapTemplate.Split(s, -1) {
+		typ, err := TypeFromString(field)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, typ)
+	}
+	return types, nil
+}
+
+func typeBitmapToString(types []RRType) string {
+	var buf bytes.Buffer
+	for _, typ := range types {
+		buf.WriteString(" ")
+		buf.WriteString(typ.String())
+	}
+	return buf.String()
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/ben-han-cn/g53/util"
 )
@@ -30,15 +31,13 @@ func (nsec3 *NSEC3) String() string {
 	buf.WriteString(fieldToString(RDF_D_INT, nsec3.Iterations))
 	buf.WriteString(" ")
 	if nsec3.SaltLength == 0 {
-		nsec3.Salt = "-"
+		buf.WriteString("-")
+	} else {
+		buf.WriteString(fieldToString(RDF_D_STR, nsec3.Salt))
 	}
-	buf.WriteString(fieldToString(RDF_D_STR, nsec3.Salt))
 	buf.WriteString(" ")
 	buf.WriteString(fieldToString(RDF_D_STR, nsec3.NextHash))
-	for _, typ := range nsec3.Types {
-		buf.WriteString(" ")
-		buf.WriteString(fieldToString(RDF_D_STR, typ.String()))
-	}
+	buf.WriteString(typeBitmapToString(nsec3.Types))
 	return buf.String()
 }
 
@@ -79,13 +78,7 @@ func (nsec3 *NSEC3) Compare(other Rdata) int {
 		return order
 	}
 
-	for i, typ := range nsec3.Types {
-		if order := fieldCompare(RDF_C_UINT16, uint16(typ), uint16(otherNSEC3.Types[i])); order != 0 {
-			return order
-		}
-	}
-
-	return 0
+	return compareTypeBitmap(nsec3.Types, otherNSEC3.Types)
 }
 
 func (nsec3 *NSEC3) Rend(r *MsgRender) {
@@ -96,7 +89,7 @@ func (nsec3 *NSEC3) Rend(r *MsgRender) {
 	rendField(RDF_C_BINARY, encodeStringToHex(nsec3.Salt), r)
 	rendField(RDF_C_UINT8, nsec3.HashLength, r)
 	rendField(RDF_C_BINARY, encodeNSEC3NextHash([]byte(nsec3.NextHash)), r)
-	rendField(RDF_C_BINARY, encodeNSEC3Bytes(nsec3.Types), r)
+	rendField(RDF_C_BINARY, encodeTypeBitmap(nsec3.Types), r)
 }
 
 func (nsec3 *NSEC3) ToWire(buf *util.OutputBuffer) {
@@ -107,7 +100,7 @@ func (nsec3 *NSEC3) ToWire(buf *util.OutputBuffer) {
 	fieldToWire(RDF_C_BINARY, encodeStringToHex(nsec3.Salt), buf)
 	fieldToWire(RDF_C_UINT8, nsec3.HashLength, buf)
 	fieldToWire(RDF_C_BINARY, encodeNSEC3NextHash([]byte(nsec3.NextHash)), buf)
-	fieldToWire(RDF_C_BINARY, encodeNSEC3Bytes(nsec3.Types), buf)
+	fieldToWire(RDF_C_BINARY, encodeTypeBitmap(nsec3.Types), buf)
 }
 
 func encodeStringToHex(saltStr string) []byte {
@@ -123,27 +116,6 @@ func encodeNSEC3NextHash(nextHash []byte) []byte {
 	return buf
 }
 
-func encodeNSEC3Bytes(nsec3Types []RRType) []byte {
-	types := make([]byte, (2+32)*len(nsec3Types))
-	var lastwindow, lastlength uint16
-	offset := 0
-	for _, typ := range nsec3Types {
-		window := uint16(typ) / 256
-		length := (uint16(typ)-window*256)/8 + 1
-		if window > lastwindow && lastlength != 0 {
-			offset += int(lastlength) + 2
-			lastlength = 0
-		}
-
-		types[offset] = byte(window)
-		types[offset+1] = byte(length)
-		types[offset+1+int(length)] |= byte(1 << (7 - (uint16(typ) % 8)))
-		lastwindow, lastlength = window, length
-	}
-
-	return types[:offset+int(lastlength)+2]
-}
-
 func NSEC3FromWire(buf *util.InputBuffer, ll uint16) (*NSEC3, error) {
 	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
 	if err != nil {
@@ -193,7 +165,7 @@ func NSEC3FromWire(buf *util.InputBuffer, ll uint16) (*NSEC3, error) {
 		return nil, fmt.Errorf("extra data in rdata part")
 	}
 
-	types, err := decodeNSEC3Types(nsec3Types.([]byte))
+	types, err := decodeTypeBitmap(nsec3Types.([]byte))
 	if err != nil {
 		return nil, err
 	}
@@ -210,123 +182,58 @@ func NSEC3FromWire(buf *util.InputBuffer, ll uint16) (*NSEC3, error) {
 	}, nil
 }
 
-func decodeNSEC3Types(msg []byte) ([]RRType, error) {
-	var nsec3Types []RRType
-	length, window, lastwindow := 0, 0, -1
-	offset := 0
-	for offset < len(msg) {
-		if offset+2 > len(msg) {
-			return nil, fmt.Errorf("overflow unpacking NSEC3 types")
-		}
-
-		window = int(msg[offset])
-		length = int(msg[offset+1])
-		offset += 2
-		if window <= lastwindow {
-			return nil, fmt.Errorf("out of order NSEC3 types block")
-		}
-
-		if length == 0 {
-			return nil, fmt.Errorf("empty NSEC3 Types")
-		}
-
-		if length > 32 {
-			return nil, fmt.Errorf("NSEC3 Types longer than 32")
-		}
-
-		if offset+length > len(msg) {
-			return nil, fmt.Errorf("overflowing NSEC3 types")
-		}
-
-		for j := 0; j < length; j++ {
-			b := msg[offset+j]
-			base := window*256 + j*8
-			for i := 7; i >= 0; i-- {
-				if (b>>uint16(i))&0x01 != 0 {
-					nsec3Types = append(nsec3Types, RRType(base+7-i))
-				}
-			}
-		}
-		offset += length
-		lastwindow = window
-	}
-
-	return nsec3Types, nil
-}
-
-var nsec3RdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(.*?)\s*$`)
-var nsec3TypesTemplate = regexp.MustCompile(`\s+`)
+var nsec3RdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s*(.*?)\s*$`)
 
+//presentation format defined in rfc5155 section 3.3, salt is "-" if it's
+//empty and next hashed owner name is in base32hex without padding
 func NSEC3FromString(s string) (*NSEC3, error) {
 	fields := nsec3RdataTemplate.FindStringSubmatch(s)
-	if len(fields) != 9 {
+	if len(fields) != 7 {
 		return nil, fmt.Errorf("short of fields for nsec3")
 	}
 
 	fields = fields[1:]
-	algorithm, err := fieldFromString(RDF_D_INT, fields[0])
-	if err != nil {
-		return nil, err
-	}
-
-	flags, err := fieldFromString(RDF_D_INT, fields[1])
-	if err != nil {
-		return nil, err
-	}
-
-	iterations, err := fieldFromString(RDF_D_INT, fields[2])
-	if err != nil {
-		return nil, err
-	}
-
-	saltLen, err := fieldFromString(RDF_D_INT, fields[3])
-	if err != nil {
-		return nil, err
-	}
-
-	salt, err := fieldFromString(RDF_D_STR, fields[4])
-	if err != nil {
-		return nil, err
+	var ints [3]int
+	for i, max := range []int{255, 255, 65535} {
+		d, err := fieldFromString(RDF_D_INT, fields[i])
+		if err != nil {
+			return nil, err
+		} else if d.(int) < 0 || d.(int) > max {
+			return nil, ErrOutOfRange
+		}
+		ints[i] = d.(int)
 	}
 
-	if len(salt.(string)) != saltLen.(int) {
-		return nil, fmt.Errorf("nsec3 from string failed with salt_len %v not equal len of salt %v",
-			saltLen.(int), len(salt.(string)))
+	salt := ""
+	if fields[3] != "-" {
+		d, err := hex.DecodeString(fields[3])
+		if err != nil {
+			return nil, err
+		} else if len(d) > 255 {
+			return nil, ErrOutOfRange
+		}
+		salt = hex.EncodeToString(d)
 	}
 
-	hashLen, err := fieldFromString(RDF_D_INT, fields[5])
-	if err != nil {
-		return nil, err
+	nextHash := strings.ToUpper(fields[4])
+	hash := encodeNSEC3NextHash([]byte(nextHash))
+	if len(hash) == 0 || base32.HexEncoding.EncodeToString(hash) != nextHash {
+		return nil, fmt.Errorf("nsec3 next hashed owner name %s isn't valid", fields[4])
 	}
 
-	nextHash, err := fieldFromString(RDF_D_STR, fields[6])
+	types, err := typeBitmapFromString(fields[5])
 	if err != nil {
 		return nil, err
 	}
 
-	if len(nextHash.(string)) != hashLen.(int) {
-		return nil, fmt.Errorf("nsec3 from string failed with hash_len %v and len of next_hash_domain %v",
-			hashLen.(int), len(nextHash.(string)))
-	}
-
-	var types []RRType
-	for _, field := range nsec3TypesTemplate.Split(fields[7], -1) {
-		typ, err := TypeFromString(field)
-		if err != nil {
-			return nil, err
-		} else {
-			types = append(types, typ)
-		}
-	}
-
 	return &NSEC3{
-		Algorithm:  uint8(algorithm.(int)),
-		Flags:      uint8(flags.(int)),
-		Iterations: uint16(iterations.(int)),
-		SaltLength: uint8(saltLen.(int)),
-		Salt:       salt.(string),
-		HashLength: uint8(hashLen.(int)),
-		NextHash:   nextHash.(string),
+		Algorithm:  uint8(ints[0]),
+		Flags:      uint8(ints[1]),
+		Iterations: uint16(ints[2]),
+		SaltLength: uint8(len(salt) / 2),
+		Salt:       salt,
+		HashLength: uint8(len(hash)),
+		NextHash:   nextHash,
 		Types:      types,
 	}, nil
 }
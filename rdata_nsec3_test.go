@@ -41,3 +41,64 @@ func TestNSEC3FromWire(t *testing.T) {
 	nsec3.Rend(render)
 	WireMatch(t, render.Data(), nsec3_wire)
 }
+
+func TestNSEC3Param(t *testing.T) {
+	wire, _ := util.HexStrToBytes("0009" + "0100000a04aabbccdd")
+	rdata, err := RdataFromWire(RR_NSEC3PARAM, util.NewInputBuffer(wire))
+	Assert(t, err == nil, "nsec3param from wire failed:%v", err)
+	Equal(t, rdata.String(), "1 0 10 aabbccdd")
+
+	rdata2, err := RdataFromString(RR_NSEC3PARAM, "1 0 10 AABBCCDD")
+	Assert(t, err == nil, "nsec3param from string failed:%v", err)
+	Equal(t, rdata.Compare(rdata2), 0)
+
+	render := NewMsgRender()
+	render.WriteUint16(9)
+	rdata2.Rend(render)
+	WireMatch(t, wire, render.Data())
+
+	rdata, err = RdataFromString(RR_NSEC3PARAM, "1 0 0 -")
+	Assert(t, err == nil, "nsec3param without salt failed:%v", err)
+	Equal(t, rdata.(*NSEC3Param).SaltLength, uint8(0))
+	Equal(t, rdata.String(), "1 0 0 -")
+	Assert(t, rdata.Compare(rdata2) < 0, "nsec3param with less iterations should be smaller")
+
+	wire, _ = util.HexStrToBytes("0009" + "0100000a05aabbccdd")
+	_, err = RdataFromWire(RR_NSEC3PARAM, util.NewInputBuffer(wire))
+	Assert(t, err != nil, "salt length mismatch should be rejected")
+
+	for _, s := range []string{"256 0 10 -", "1 256 10 -", "1 0 65536 -"} {
+		_, err := NSEC3ParamFromString(s)
+		Equal(t, err, ErrOutOfRange)
+	}
+}
+
+func TestNSEC3FromString(t *testing.T) {
+	nsec3, err := NSEC3FromString("1 1 12 aabbccdd 2t7b4g4vsa5smi47k61mv5bv1a22bojr MX DNSKEY NS SOA NSEC3PARAM RRSIG")
+	Assert(t, err == nil, "nsec3 from string failed:%v", err)
+	Assert(t, nsec3.SaltLength == 4 && nsec3.HashLength == 20, "salt and hash length should be in bytes")
+	Assert(t, nsec3.IsOptOut(), "nsec3 should be opt-out")
+	Equal(t, nsec3.String(), "1 1 12 aabbccdd 2T7B4G4VSA5SMI47K61MV5BV1A22BOJR MX DNSKEY NS SOA NSEC3PARAM RRSIG")
+
+	render := NewMsgRender()
+	nsec3.Rend(render)
+	other, err := NSEC3FromWire(util.NewInputBuffer(render.Data()), uint16(len(render.Data())))
+	Assert(t, err == nil, "nsec3 from wire failed:%v", err)
+	Assert(t, nsec3.Compare(other) == 0, "nsec3 wire round trip changed rdata")
+
+	nsec3, err = NSEC3FromString("1 0 0 - CK0Q1GIN43N1ARRC9OSM6QPQR81H5M9A")
+	Assert(t, err == nil, "nsec3 from string failed:%v", err)
+	Assert(t, nsec3.SaltLength == 0 && len(nsec3.Types) == 0, "nsec3 should have no salt and types")
+	Equal(t, nsec3.String(), "1 0 0 - CK0Q1GIN43N1ARRC9OSM6QPQR81H5M9A")
+	Equal(t, nsec3.Salt, "")
+
+	for _, s := range []string{
+		"1 1 12 aabbccdd",
+		"1 1 12 aabbccd 2t7b4g4vsa5smi47k61mv5bv1a22bojr",
+		"1 1 65536 aabbccdd 2t7b4g4vsa5smi47k61mv5bv1a22bojr",
+		"1 1 12 aabbccdd 2t7b4g4vsa5smi47k61mv5bv1a22boj!",
+	} {
+		_, err := NSEC3FromString(s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
@@ -0,0 +1,167 @@
+package g53
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"regexp"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+type NSEC3Param struct {
+	Algorithm  uint8
+	Flags      uint8
+	Iterations uint16
+	SaltLength uint8
+	Salt       string
+}
+
+func (param *NSEC3Param) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, param.Algorithm, r)
+	rendField(RDF_C_UINT8, param.Flags, r)
+	rendField(RDF_C_UINT16, param.Iterations, r)
+	rendField(RDF_C_UINT8, param.SaltLength, r)
+	rendField(RDF_C_BINARY, encodeStringToHex(param.Salt), r)
+}
+
+func (param *NSEC3Param) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, param.Algorithm, buf)
+	fieldToWire(RDF_C_UINT8, param.Flags, buf)
+	fieldToWire(RDF_C_UINT16, param.Iterations, buf)
+	fieldToWire(RDF_C_UINT8, param.SaltLength, buf)
+	fieldToWire(RDF_C_BINARY, encodeStringToHex(param.Salt), buf)
+}
+
+func (param *NSEC3Param) Compare(other Rdata) int {
+	otherParam := other.(*NSEC3Param)
+	order := fieldCompare(RDF_C_UINT8, param.Algorithm, otherParam.Algorithm)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, param.Flags, otherParam.Flags)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT16, param.Iterations, otherParam.Iterations)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, param.SaltLength, otherParam.SaltLength)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, encodeStringToHex(param.Salt), encodeStringToHex(otherParam.Salt))
+}
+
+func (param *NSEC3Param) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, param.Algorithm))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, param.Flags))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, param.Iterations))
+	buf.WriteString(" ")
+	if param.SaltLength == 0 {
+		buf.WriteString("-")
+	} else {
+		buf.WriteString(fieldToString(RDF_D_STR, param.Salt))
+	}
+	return buf.String()
+}
+
+func NSEC3ParamFromWire(buf *util.InputBuffer, ll uint16) (*NSEC3Param, error) {
+	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	iterations, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	saltLen, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint16(saltLen.(uint8)) != ll {
+		return nil, errors.New("salt length doesn't match rdata length")
+	}
+
+	salt, _, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NSEC3Param{
+		Algorithm:  algorithm.(uint8),
+		Flags:      flags.(uint8),
+		Iterations: iterations.(uint16),
+		SaltLength: saltLen.(uint8),
+		Salt:       hex.EncodeToString(salt.([]uint8)),
+	}, nil
+}
+
+var nsec3ParamRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s*$`)
+
+func NSEC3ParamFromString(s string) (*NSEC3Param, error) {
+	fields := nsec3ParamRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 5 {
+		return nil, errors.New("short of fields for nsec3param")
+	}
+
+	fields = fields[1:]
+	algorithm, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if algorithm.(int) < 0 || algorithm.(int) > math.MaxUint8 {
+		return nil, ErrOutOfRange
+	}
+
+	flags, err := fieldFromString(RDF_D_INT, fields[1])
+	if err != nil {
+		return nil, err
+	} else if flags.(int) < 0 || flags.(int) > math.MaxUint8 {
+		return nil, ErrOutOfRange
+	}
+
+	iterations, err := fieldFromString(RDF_D_INT, fields[2])
+	if err != nil {
+		return nil, err
+	} else if iterations.(int) < 0 || iterations.(int) > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	var salt []uint8
+	if fields[3] != "-" {
+		d, err := fieldFromString(RDF_D_HEX, fields[3])
+		if err != nil {
+			return nil, err
+		}
+		salt = d.([]uint8)
+	}
+
+	if len(salt) > 255 {
+		return nil, errors.New("salt is too long")
+	}
+
+	return &NSEC3Param{
+		Algorithm:  uint8(algorithm.(int)),
+		Flags:      uint8(flags.(int)),
+		Iterations: uint16(iterations.(int)),
+		SaltLength: uint8(len(salt)),
+		Salt:       hex.EncodeToString(salt),
+	}, nil
+}
@@ -0,0 +1,43 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func TestNSEC(t *testing.T) {
+	//alfa.example.com. 86400 IN NSEC host.example.com. A MX RRSIG NSEC TYPE1234 (rfc4034 4.3)
+	wire, _ := util.HexStrToBytes("0037" + "04686f7374076578616d706c6503636f6d00" +
+		"0006400100000003" + "041b" + "000000000000000000000000000000000000000000000000000020")
+	rdata, err := RdataFromWire(RR_NSEC, util.NewInputBuffer(wire))
+	Assert(t, err == nil, "nsec from wire failed:%v", err)
+	nsec := rdata.(*NSEC)
+	NameEqToStr(t, nsec.NextDomain, "host.example.com.")
+	Equal(t, nsec.Types, []RRType{RR_A, RR_MX, RR_RRSIG, RR_NSEC, RRType(1234)})
+	Equal(t, nsec.String(), "host.example.com. A MX RRSIG NSEC TYPE1234")
+
+	rdata2, err := RdataFromString(RR_NSEC, "host.example.com. NSEC TYPE1234 a rrsig mx mx")
+	Assert(t, err == nil, "nsec from string failed:%v", err)
+	Equal(t, rdata.Compare(rdata2), 0)
+
+	//next domain shouldn't be compressed
+	render := NewMsgRender()
+	name, _ := NameFromString("example.com.")
+	render.WriteName(name, true)
+	render.WriteUint16(0x37)
+	rdata2.Rend(render)
+	WireMatch(t, render.Data()[13:], wire)
+
+	empty, err := RdataFromString(RR_NSEC, "example.com.")
+	Assert(t, err == nil, "nsec without types should be valid:%v", err)
+	Equal(t, len(empty.(*NSEC).Types), 0)
+	Assert(t, empty.Compare(rdata) < 0, "example.com should smaller than host.example.com")
+
+	for _, bitmap := range []string{"0000", "0101", "000140000140", "0021" + "00000000000000000000000000000000000000000000000000000000000000000000"} {
+		wire, _ := util.HexStrToBytes("0002" + "00" + bitmap)
+		wire[1] = byte(len(wire) - 2)
+		_, err := RdataFromWire(RR_NSEC, util.NewInputBuffer(wire))
+		Assert(t, err != nil, "bitmap %s should be invalid", bitmap)
+	}
+}
@@ -0,0 +1,59 @@
+package g53
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//null has no presentation format, so generic format in rfc3597
+//is used for text
+type Null struct {
+	Data []byte
+}
+
+func (n *Null) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, n.Data, r)
+}
+
+func (n *Null) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, n.Data, buf)
+}
+
+func (n *Null) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, n.Data, other.(*Null).Data)
+}
+
+func (n *Null) String() string {
+	if len(n.Data) == 0 {
+		return genericRdataPrefix + " 0"
+	} else {
+		return strings.Join([]string{
+			genericRdataPrefix,
+			strconv.Itoa(len(n.Data)),
+			fieldToString(RDF_D_HEX, n.Data)}, " ")
+	}
+}
+
+func NullFromWire(buf *util.InputBuffer, ll uint16) (*Null, error) {
+	f, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	} else if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	} else {
+		d, _ := f.([]uint8)
+		return &Null{d}, nil
+	}
+}
+
+func NullFromString(s string) (*Null, error) {
+	d, err := genericRdataFromString(s)
+	if err != nil {
+		return nil, err
+	} else {
+		return &Null{d}, nil
+	}
+}
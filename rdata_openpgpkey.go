@@ -0,0 +1,54 @@
+package g53
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//openpgp transferable public key defined in rfc7929
+type OpenPGPKey struct {
+	PublicKey []byte
+}
+
+func (k *OpenPGPKey) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, k.PublicKey, r)
+}
+
+func (k *OpenPGPKey) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, k.PublicKey, buf)
+}
+
+func (k *OpenPGPKey) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, k.PublicKey, other.(*OpenPGPKey).PublicKey)
+}
+
+func (k *OpenPGPKey) String() string {
+	return fieldToString(RDF_D_B64, k.PublicKey)
+}
+
+func OpenPGPKeyFromWire(buf *util.InputBuffer, ll uint16) (*OpenPGPKey, error) {
+	key, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+	return &OpenPGPKey{key.([]byte)}, nil
+}
+
+func OpenPGPKeyFromString(s string) (*OpenPGPKey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("openpgpkey is empty")
+	}
+
+	key, err := fieldFromString(RDF_D_B64, hexDataTemplate.ReplaceAllString(s, ""))
+	if err != nil {
+		return nil, err
+	}
+	return &OpenPGPKey{key.([]byte)}, nil
+}
@@ -0,0 +1,109 @@
+package g53
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//px defined in rfc2163, names aren't compressed as rfc3597 requires
+type PX struct {
+	Preference uint16
+	Map822     *Name
+	MapX400    *Name
+}
+
+func (px *PX) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, px.Preference, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, px.Map822, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, px.MapX400, r)
+}
+
+func (px *PX) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, px.Preference, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, px.Map822, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, px.MapX400, buf)
+}
+
+func (px *PX) Compare(other Rdata) int {
+	otherPX := other.(*PX)
+	order := fieldCompare(RDF_C_UINT16, px.Preference, otherPX.Preference)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_NAME_UNCOMPRESS, px.Map822, otherPX.Map822)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_NAME_UNCOMPRESS, px.MapX400, otherPX.MapX400)
+}
+
+func (px *PX) String() string {
+	return strings.Join([]string{
+		fieldToString(RDF_D_INT, px.Preference),
+		fieldToString(RDF_D_NAME, px.Map822),
+		fieldToString(RDF_D_NAME, px.MapX400)}, " ")
+}
+
+func PXFromWire(buf *util.InputBuffer, ll uint16) (*PX, error) {
+	f, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(uint16)
+
+	f, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	map822, _ := f.(*Name)
+
+	f, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	mapX400, _ := f.(*Name)
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &PX{preference, map822, mapX400}, nil
+}
+
+var pxRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s*$`)
+
+func PXFromString(s string) (*PX, error) {
+	fields := pxRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 4 {
+		return nil, errors.New("fields count for px isn't 3")
+	}
+
+	fields = fields[1:]
+	f, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(int)
+	if preference < 0 || preference > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	f, err = fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+	map822, _ := f.(*Name)
+
+	f, err = fieldFromString(RDF_D_NAME, fields[2])
+	if err != nil {
+		return nil, err
+	}
+	mapX400, _ := f.(*Name)
+	return &PX{uint16(preference), map822, mapX400}, nil
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/ben-han-cn/g53/util"
 )
@@ -28,7 +30,7 @@ func (rrsig *RRSig) Rend(r *MsgRender) {
 	rendField(RDF_C_UINT32, rrsig.SigExpire, r)
 	rendField(RDF_C_UINT32, rrsig.Inception, r)
 	rendField(RDF_C_UINT16, rrsig.Tag, r)
-	rendField(RDF_C_NAME, rrsig.Signer, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, rrsig.Signer, r)
 	rendField(RDF_C_BINARY, rrsig.Signature, r)
 }
 
@@ -40,7 +42,7 @@ func (rrsig *RRSig) ToWire(buf *util.OutputBuffer) {
 	fieldToWire(RDF_C_UINT32, rrsig.SigExpire, buf)
 	fieldToWire(RDF_C_UINT32, rrsig.Inception, buf)
 	fieldToWire(RDF_C_UINT16, rrsig.Tag, buf)
-	fieldToWire(RDF_C_NAME, rrsig.Signer, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, rrsig.Signer, buf)
 	fieldToWire(RDF_C_BINARY, rrsig.Signature, buf)
 }
 
@@ -81,7 +83,7 @@ func (rrsig *RRSig) Compare(other Rdata) int {
 		return order
 	}
 
-	order = fieldCompare(RDF_C_NAME, rrsig.Signer, otherRRSig.Signer)
+	order = fieldCompare(RDF_C_NAME_UNCOMPRESS, rrsig.Signer, otherRRSig.Signer)
 	if order != 0 {
 		return order
 	}
@@ -147,7 +149,7 @@ func RRSigFromWire(buf *util.InputBuffer, ll uint16) (*RRSig, error) {
 		return nil, err
 	}
 
-	signer, ll, err := fieldFromWire(RDF_C_NAME, buf, ll)
+	signer, ll, err := fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +166,24 @@ func RRSigFromWire(buf *util.InputBuffer, ll uint16) (*RRSig, error) {
 	return &RRSig{RRType(covered.(uint16)), algorithm.(uint8), labels.(uint8), originalTtl.(uint32), sigExpire.(uint32), inception.(uint32), tag.(uint16), signer.(*Name), signature.([]uint8)}, nil
 }
 
+//time is either seconds since 1970 or in YYYYMMDDHHmmSS format in utc,
+//rfc4034 section 3.2
+func rrsigTimeFromString(s string) (uint32, error) {
+	if len(s) == 14 && isDigits(s) {
+		t, err := time.Parse("20060102150405", s)
+		if err != nil {
+			return 0, err
+		}
+		return uint32(t.Unix()), nil
+	}
+
+	d, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(d), nil
+}
+
 var rrsigRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s*$`)
 
 func RRSigFromString(s string) (*RRSig, error) {
@@ -193,12 +213,12 @@ func RRSigFromString(s string) (*RRSig, error) {
 		return nil, err
 	}
 
-	sigExpire, err := fieldFromString(RDF_D_INT, fields[4])
+	sigExpire, err := rrsigTimeFromString(fields[4])
 	if err != nil {
 		return nil, err
 	}
 
-	inception, err := fieldFromString(RDF_D_INT, fields[5])
+	inception, err := rrsigTimeFromString(fields[5])
 	if err != nil {
 		return nil, err
 	}
@@ -218,5 +238,5 @@ func RRSigFromString(s string) (*RRSig, error) {
 		return nil, err
 	}
 
-	return &RRSig{covered, uint8(algorithm.(int)), uint8(labels.(int)), uint32(originalTtl.(int)), uint32(sigExpire.(int)), uint32(inception.(int)), uint16(tag.(int)), signer.(*Name), signature.([]uint8)}, nil
+	return &RRSig{covered, uint8(algorithm.(int)), uint8(labels.(int)), uint32(originalTtl.(int)), sigExpire, inception, uint16(tag.(int)), signer.(*Name), signature.([]uint8)}, nil
 }
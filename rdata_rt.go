@@ -0,0 +1,88 @@
+package g53
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//rt defined in rfc1183, host isn't compressed as rfc3597 requires
+type RT struct {
+	Preference uint16
+	Host       *Name
+}
+
+func (rt *RT) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, rt.Preference, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, rt.Host, r)
+}
+
+func (rt *RT) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, rt.Preference, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, rt.Host, buf)
+}
+
+func (rt *RT) Compare(other Rdata) int {
+	otherRT := other.(*RT)
+	order := fieldCompare(RDF_C_UINT16, rt.Preference, otherRT.Preference)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_NAME_UNCOMPRESS, rt.Host, otherRT.Host)
+}
+
+func (rt *RT) String() string {
+	return strings.Join([]string{
+		fieldToString(RDF_D_INT, rt.Preference),
+		fieldToString(RDF_D_NAME, rt.Host)}, " ")
+}
+
+func RTFromWire(buf *util.InputBuffer, ll uint16) (*RT, error) {
+	f, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(uint16)
+
+	f, ll, err = fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := f.(*Name)
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &RT{preference, host}, nil
+}
+
+var rtRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s*$`)
+
+func RTFromString(s string) (*RT, error) {
+	fields := rtRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for rt isn't 2")
+	}
+
+	fields = fields[1:]
+	f, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	preference, _ := f.(int)
+	if preference < 0 || preference > math.MaxUint16 {
+		return nil, ErrOutOfRange
+	}
+
+	f, err = fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+	host, _ := f.(*Name)
+	return &RT{uint16(preference), host}, nil
+}
@@ -0,0 +1,287 @@
+package g53
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var ErrFieldValueMismatch = errors.New("field value doesn't match its coding type")
+
+//field of rdata, coding type decides the wire format and how it's
+//compressed, display type decides the text format
+type RdataField struct {
+	Name    string
+	Coding  RDFCodingType
+	Display RDFDisplayType
+}
+
+var fieldDisplayTypes = map[RDFCodingType][]RDFDisplayType{
+	RDF_C_NAME:            {RDF_D_NAME},
+	RDF_C_NAME_UNCOMPRESS: {RDF_D_NAME},
+	RDF_C_UINT8:           {RDF_D_INT},
+	RDF_C_UINT16:          {RDF_D_INT},
+	RDF_C_UINT32:          {RDF_D_INT},
+	RDF_C_IPV4:            {RDF_D_IPV4},
+	RDF_C_IPV6:            {RDF_D_IPV6},
+	RDF_C_BINARY:          {RDF_D_HEX, RDF_D_B32, RDF_D_B64},
+	RDF_C_BYTE_BINARY:     {RDF_D_STR, RDF_D_HEX, RDF_D_B32, RDF_D_B64},
+	RDF_C_TXT:             {RDF_D_TXT},
+}
+
+type rdataSchema struct {
+	typ    RRType
+	fields []RdataField
+}
+
+func newRdataSchema(t RRType, fields []RdataField) (*rdataSchema, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("rdata has no field")
+	}
+
+	for i, f := range fields {
+		displays, ok := fieldDisplayTypes[f.Coding]
+		if ok == false {
+			return nil, fmt.Errorf("field %s has unknown coding type", f.Name)
+		}
+
+		valid := false
+		for _, d := range displays {
+			if d == f.Display {
+				valid = true
+				break
+			}
+		}
+		if valid == false {
+			return nil, fmt.Errorf("field %s display type doesn't match coding type", f.Name)
+		}
+
+		//binary and txt field take all the left data
+		if (f.Coding == RDF_C_BINARY || f.Coding == RDF_C_TXT) && i != len(fields)-1 {
+			return nil, fmt.Errorf("field %s should be the last field", f.Name)
+		}
+	}
+
+	clone := make([]RdataField, len(fields))
+	copy(clone, fields)
+	return &rdataSchema{
+		typ:    t,
+		fields: clone,
+	}, nil
+}
+
+func (s *rdataSchema) fromWire(buf *util.InputBuffer, ll uint16) (*FieldRdata, error) {
+	values := make([]interface{}, 0, len(s.fields))
+	for _, f := range s.fields {
+		v, ll_, err := fieldFromWire(f.Coding, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+		ll = ll_
+		values = append(values, v)
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &FieldRdata{
+		Type:   s.typ,
+		Fields: values,
+		schema: s,
+	}, nil
+}
+
+func (s *rdataSchema) fromString(str string) (*FieldRdata, error) {
+	tokens, err := splitRdataFields(str)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldCount := len(s.fields)
+	last := s.fields[fieldCount-1]
+	if last.Coding == RDF_C_BINARY || last.Coding == RDF_C_TXT {
+		if len(tokens) >= fieldCount {
+			sep := ""
+			if last.Coding == RDF_C_TXT {
+				sep = " "
+			}
+			tokens = append(tokens[:fieldCount-1], strings.Join(tokens[fieldCount-1:], sep))
+		} else if len(tokens) == fieldCount-1 && last.Coding == RDF_C_BINARY {
+			tokens = append(tokens, "")
+		}
+	}
+
+	if len(tokens) != fieldCount {
+		return nil, fmt.Errorf("%s rdata should has %d fields but get %d", s.typ.String(), fieldCount, len(tokens))
+	}
+
+	values := make([]interface{}, 0, fieldCount)
+	for i, f := range s.fields {
+		v, err := fieldValueFromString(f, tokens[i])
+		if err != nil {
+			return nil, fmt.Errorf("field %s is invalid:%s", f.Name, err.Error())
+		}
+		values = append(values, v)
+	}
+
+	return &FieldRdata{
+		Type:   s.typ,
+		Fields: values,
+		schema: s,
+	}, nil
+}
+
+func fieldValueFromString(f RdataField, s string) (interface{}, error) {
+	v, err := fieldFromString(f.Display, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Coding {
+	case RDF_C_UINT8:
+		d, _ := v.(int)
+		if d < 0 || d > math.MaxUint8 {
+			return nil, ErrOutOfRange
+		}
+		return uint8(d), nil
+
+	case RDF_C_UINT16:
+		d, _ := v.(int)
+		if d < 0 || d > math.MaxUint16 {
+			return nil, ErrOutOfRange
+		}
+		return uint16(d), nil
+
+	case RDF_C_UINT32:
+		d, _ := v.(int)
+		if d < 0 || d > math.MaxUint32 {
+			return nil, ErrOutOfRange
+		}
+		return uint32(d), nil
+
+	case RDF_C_BYTE_BINARY:
+		var d []byte
+		if str, ok := v.(string); ok {
+			d = []byte(str)
+		} else {
+			d, _ = v.([]byte)
+		}
+		if len(d) > math.MaxUint8 {
+			return nil, ErrStringIsTooLong
+		}
+		return d, nil
+
+	default:
+		return v, nil
+	}
+}
+
+func isFieldValueValid(ct RDFCodingType, v interface{}) bool {
+	var ok bool
+	switch ct {
+	case RDF_C_NAME, RDF_C_NAME_UNCOMPRESS:
+		var n *Name
+		n, ok = v.(*Name)
+		ok = ok && n != nil
+	case RDF_C_UINT8:
+		_, ok = v.(uint8)
+	case RDF_C_UINT16:
+		_, ok = v.(uint16)
+	case RDF_C_UINT32:
+		_, ok = v.(uint32)
+	case RDF_C_IPV4:
+		var ip net.IP
+		ip, ok = v.(net.IP)
+		ok = ok && len(ip) == net.IPv4len
+	case RDF_C_IPV6:
+		var ip net.IP
+		ip, ok = v.(net.IP)
+		ok = ok && len(ip) == net.IPv6len
+	case RDF_C_BINARY:
+		_, ok = v.([]byte)
+	case RDF_C_BYTE_BINARY:
+		var d []byte
+		d, ok = v.([]byte)
+		ok = ok && len(d) <= math.MaxUint8
+	case RDF_C_TXT:
+		_, ok = v.([]string)
+	}
+	return ok
+}
+
+//rdata of type registered by RegisterRdataType, the go type of each
+//field value is decided by its coding type
+//  RDF_C_NAME, RDF_C_NAME_UNCOMPRESS: *Name
+//  RDF_C_UINT8, RDF_C_UINT16, RDF_C_UINT32: uint8, uint16, uint32
+//  RDF_C_IPV4, RDF_C_IPV6: net.IP with 4 or 16 bytes
+//  RDF_C_BINARY, RDF_C_BYTE_BINARY: []byte
+//  RDF_C_TXT: []string
+type FieldRdata struct {
+	Type   RRType
+	Fields []interface{}
+	schema *rdataSchema
+}
+
+func NewFieldRdata(t RRType, values ...interface{}) (*FieldRdata, error) {
+	schema, ok := getRdataSchema(t)
+	if ok == false {
+		return nil, fmt.Errorf("type %v isn't registered with fields", t)
+	}
+
+	if len(values) != len(schema.fields) {
+		return nil, fmt.Errorf("%s rdata should has %d fields but get %d", t.String(), len(schema.fields), len(values))
+	}
+
+	for i, f := range schema.fields {
+		if isFieldValueValid(f.Coding, values[i]) == false {
+			return nil, fmt.Errorf("field %s:%s", f.Name, ErrFieldValueMismatch.Error())
+		}
+	}
+
+	return &FieldRdata{
+		Type:   t,
+		Fields: values,
+		schema: schema,
+	}, nil
+}
+
+func (rd *FieldRdata) Rend(r *MsgRender) {
+	for i, f := range rd.schema.fields {
+		rendField(f.Coding, rd.Fields[i], r)
+	}
+}
+
+func (rd *FieldRdata) ToWire(buf *util.OutputBuffer) {
+	for i, f := range rd.schema.fields {
+		fieldToWire(f.Coding, rd.Fields[i], buf)
+	}
+}
+
+func (rd *FieldRdata) Compare(other Rdata) int {
+	otherRdata := other.(*FieldRdata)
+	for i, f := range rd.schema.fields {
+		if order := fieldCompare(f.Coding, rd.Fields[i], otherRdata.Fields[i]); order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+func (rd *FieldRdata) String() string {
+	var ss []string
+	for i, f := range rd.schema.fields {
+		v := rd.Fields[i]
+		if f.Display == RDF_D_STR {
+			d, _ := v.([]byte)
+			ss = append(ss, "\""+fieldToString(RDF_D_STR, string(d))+"\"")
+		} else {
+			ss = append(ss, fieldToString(f.Display, v))
+		}
+	}
+	return strings.Join(ss, " ")
+}
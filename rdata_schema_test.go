@@ -0,0 +1,87 @@
+package g53
+
+import (
+	"net"
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const testSchemaType RRType = 65280
+
+func TestRegisterRdataType(t *testing.T) {
+	fields := []RdataField{
+		{"preference", RDF_C_UINT16, RDF_D_INT},
+		{"target", RDF_C_NAME, RDF_D_NAME},
+		{"host", RDF_C_NAME_UNCOMPRESS, RDF_D_NAME},
+		{"addr", RDF_C_IPV4, RDF_D_IPV4},
+		{"desc", RDF_C_BYTE_BINARY, RDF_D_STR},
+		{"data", RDF_C_BINARY, RDF_D_HEX},
+	}
+	err := RegisterRdataType(testSchemaType, "TEST", fields)
+	Assert(t, err == nil, "register new type failed:%v", err)
+	defer unregisterRdataType(testSchemaType)
+	Equal(t, RegisterRdataType(testSchemaType, "test", fields), ErrRRTypeExists)
+	Equal(t, RegisterRdataType(RR_A, "a", fields), ErrRRTypeExists)
+	Equal(t, RegisterRdataType(65281, "mx", fields), ErrRRTypeNameConflict)
+	Assert(t, RegisterRdataType(65281, "bad", []RdataField{{"data", RDF_C_BINARY, RDF_D_HEX}, {"port", RDF_C_UINT16, RDF_D_INT}}) != nil, "binary field should be the last one")
+	Assert(t, RegisterRdataType(65281, "bad", []RdataField{{"port", RDF_C_UINT16, RDF_D_NAME}}) != nil, "display type should match coding type")
+	for _, name := range []string{"TYPE123", "type65281", "CLASS1", "in", "ANY"} {
+		Assert(t, RegisterRdataType(65281, name, fields) != nil, "name %s should be rejected", name)
+	}
+	Assert(t, RegisterRdataType(65281, "TYPEX", fields) == nil, "name not in generic format should be accepted")
+	unregisterRdataType(65281)
+	_, err = TypeFromString("typex")
+	Equal(t, err, ErrUnknownRRType)
+
+	typ, err := TypeFromString("test")
+	Assert(t, err == nil, "registered type name should be known")
+	Equal(t, typ, testSchemaType)
+	Equal(t, testSchemaType.String(), "TEST")
+
+	rrset, err := RRsetFromString("a.example.com. 300 IN TEST 10 mail.example.com. host.example.com. 192.0.2.1 \"hello world\" 0102 0a0b")
+	Assert(t, err == nil, "parse registered type failed:%v", err)
+	rdata, ok := rrset.Rdatas[0].(*FieldRdata)
+	Assert(t, ok, "rdata should be field rdata")
+	Equal(t, rdata.Fields[0], uint16(10))
+	Equal(t, rdata.Fields[4], []byte("hello world"))
+	Equal(t, rdata.String(), "10 mail.example.com. host.example.com. 192.0.2.1 \"hello world\" 01020a0b")
+
+	//hex field keeps the leading zero of each byte so it could be parsed back,
+	//which also applies to opt rdata
+	opt, err := OPTFromString("00080a0b")
+	Assert(t, err == nil, "parse opt failed:%v", err)
+	Equal(t, opt.String(), "00080a0b")
+	_, err = OPTFromString("0008zz")
+	Assert(t, err != nil, "invalid hex should fail")
+
+	_, err = RdataFromString(testSchemaType, "70000 mail.example.com. host.example.com. 192.0.2.1 a 01")
+	Assert(t, err != nil, "uint16 field out of range should fail")
+	_, err = RdataFromString(testSchemaType, "10 mail.example.com. host.example.com. 192.0.2.1")
+	Assert(t, err != nil, "short of fields should fail")
+
+	//target is compressed, host isn't
+	render := NewMsgRender()
+	rrset.Rend(render)
+	rendered := render.Data()
+	buf := util.NewInputBuffer(rendered)
+	parsed, err := RRsetFromWire(buf)
+	Assert(t, err == nil, "parse rendered rrset failed:%v", err)
+	Equal(t, parsed.Rdatas[0].Compare(rdata), 0)
+	Equal(t, parsed.Rdatas[0].String(), rdata.String())
+
+	out := util.NewOutputBuffer(64)
+	rdata.ToWire(out)
+	rdataWire, _ := util.HexStrToBytes("000a046d61696c076578616d706c6503636f6d0004686f7374076578616d706c6503636f6d00c00002010b68656c6c6f20776f726c6401020a0b")
+	WireMatch(t, rdataWire, out.Data())
+	//target is compressed to mail plus pointer to example.com
+	Equal(t, len(rendered), 15+10+len(rdataWire)-11)
+
+	built, err := NewFieldRdata(testSchemaType, uint16(10), NameFromStringUnsafe("mail.example.com."),
+		NameFromStringUnsafe("host.example.com."), net.IPv4(192, 0, 2, 1).To4(), []byte("hello world"), []byte{1, 2, 10, 11})
+	Assert(t, err == nil, "build field rdata failed:%v", err)
+	Equal(t, built.Compare(rdata), 0)
+	_, err = NewFieldRdata(testSchemaType, 10, NameFromStringUnsafe("mail.example.com."),
+		NameFromStringUnsafe("host.example.com."), net.IPv4(192, 0, 2, 1).To4(), []byte("hello world"), []byte{1, 2, 10, 11})
+	Assert(t, err != nil, "field value type should match coding type")
+}
@@ -0,0 +1,208 @@
+package g53
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//algorithm and fingerprint type defined in rfc4255, rfc6594 and rfc7479
+const (
+	SSHFP_ALGORITHM_RSA     uint8 = 1
+	SSHFP_ALGORITHM_DSA     uint8 = 2
+	SSHFP_ALGORITHM_ECDSA   uint8 = 3
+	SSHFP_ALGORITHM_ED25519 uint8 = 4
+	SSHFP_ALGORITHM_ED448   uint8 = 6
+)
+
+const (
+	SSHFP_TYPE_SHA1   uint8 = 1
+	SSHFP_TYPE_SHA256 uint8 = 2
+)
+
+var (
+	ErrUnknownSSHKeyAlgorithm = errors.New("unknown ssh public key algorithm")
+	ErrUnknownSSHFPType       = errors.New("unknown sshfp fingerprint type")
+	ErrInvalidSSHPublicKey    = errors.New("invalid ssh public key")
+)
+
+var sshKeyAlgorithms = map[string]uint8{
+	"ssh-rsa":             SSHFP_ALGORITHM_RSA,
+	"ssh-dss":             SSHFP_ALGORITHM_DSA,
+	"ecdsa-sha2-nistp256": SSHFP_ALGORITHM_ECDSA,
+	"ecdsa-sha2-nistp384": SSHFP_ALGORITHM_ECDSA,
+	"ecdsa-sha2-nistp521": SSHFP_ALGORITHM_ECDSA,
+	"ssh-ed25519":         SSHFP_ALGORITHM_ED25519,
+	"ssh-ed448":           SSHFP_ALGORITHM_ED448,
+}
+
+type SSHFP struct {
+	Algorithm   uint8
+	FpType      uint8
+	Fingerprint []byte
+}
+
+func (sshfp *SSHFP) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, sshfp.Algorithm, r)
+	rendField(RDF_C_UINT8, sshfp.FpType, r)
+	rendField(RDF_C_BINARY, sshfp.Fingerprint, r)
+}
+
+func (sshfp *SSHFP) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, sshfp.Algorithm, buf)
+	fieldToWire(RDF_C_UINT8, sshfp.FpType, buf)
+	fieldToWire(RDF_C_BINARY, sshfp.Fingerprint, buf)
+}
+
+func (sshfp *SSHFP) Compare(other Rdata) int {
+	otherSSHFP := other.(*SSHFP)
+	order := fieldCompare(RDF_C_UINT8, sshfp.Algorithm, otherSSHFP.Algorithm)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, sshfp.FpType, otherSSHFP.FpType)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, sshfp.Fingerprint, otherSSHFP.Fingerprint)
+}
+
+func (sshfp *SSHFP) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, sshfp.Algorithm))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, sshfp.FpType))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_HEX, sshfp.Fingerprint))
+	return buf.String()
+}
+
+//key is the ssh wire format public key, which is the base64 decoded
+//data in authorized_keys or the result of ssh.PublicKey.Marshal
+func NewSSHFP(key []byte, fpType uint8) (*SSHFP, error) {
+	if len(key) < 4 {
+		return nil, ErrInvalidSSHPublicKey
+	}
+
+	nameLen := binary.BigEndian.Uint32(key)
+	if uint32(len(key)-4) < nameLen {
+		return nil, ErrInvalidSSHPublicKey
+	}
+
+	algorithm, ok := sshKeyAlgorithms[string(key[4:4+nameLen])]
+	if ok == false {
+		return nil, ErrUnknownSSHKeyAlgorithm
+	}
+
+	var fingerprint []byte
+	switch fpType {
+	case SSHFP_TYPE_SHA1:
+		digest := sha1.Sum(key)
+		fingerprint = digest[:]
+	case SSHFP_TYPE_SHA256:
+		digest := sha256.Sum256(key)
+		fingerprint = digest[:]
+	default:
+		return nil, ErrUnknownSSHFPType
+	}
+
+	return &SSHFP{
+		Algorithm:   algorithm,
+		FpType:      fpType,
+		Fingerprint: fingerprint,
+	}, nil
+}
+
+//line is in authorized_keys format like "ssh-ed25519 AAAAC3Nza... comment"
+func NewSSHFPFromAuthorizedKey(line string, fpType uint8) (*SSHFP, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil, ErrInvalidSSHPublicKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	sshfp, err := NewSSHFP(key, fpType)
+	if err != nil {
+		return nil, err
+	}
+
+	if sshKeyAlgorithms[fields[0]] != sshfp.Algorithm {
+		return nil, ErrInvalidSSHPublicKey
+	}
+	return sshfp, nil
+}
+
+func SSHFPFromWire(buf *util.InputBuffer, ll uint16) (*SSHFP, error) {
+	algorithm, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	fpType, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &SSHFP{
+		Algorithm:   algorithm.(uint8),
+		FpType:      fpType.(uint8),
+		Fingerprint: fingerprint.([]byte),
+	}, nil
+}
+
+var sshfpRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(.*?)\s*$`)
+
+func SSHFPFromString(s string) (*SSHFP, error) {
+	fields := sshfpRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 4 {
+		return nil, errors.New("short of fields for sshfp")
+	}
+
+	fields = fields[1:]
+	algorithm, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if algorithm.(int) < 0 || algorithm.(int) > 255 {
+		return nil, ErrOutOfRange
+	}
+
+	fpType, err := fieldFromString(RDF_D_INT, fields[1])
+	if err != nil {
+		return nil, err
+	} else if fpType.(int) < 0 || fpType.(int) > 255 {
+		return nil, ErrOutOfRange
+	}
+
+	fingerprint, err := fieldFromString(RDF_D_HEX, hexDataTemplate.ReplaceAllString(fields[2], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHFP{
+		Algorithm:   uint8(algorithm.(int)),
+		FpType:      uint8(fpType.(int)),
+		Fingerprint: fingerprint.([]byte),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func TestSSHFP(t *testing.T) {
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKhL6lC6bb76fPt8N9T14tKJ1/emDNTUKlw63PTl7deb user@host"
+	sshfp, err := NewSSHFPFromAuthorizedKey(key, SSHFP_TYPE_SHA1)
+	Assert(t, err == nil, "create sshfp failed:%v", err)
+	Equal(t, sshfp.String(), "4 1 5cb16d28992e9613603d2bdb683a066281e2418c")
+
+	sshfp, err = NewSSHFPFromAuthorizedKey(key, SSHFP_TYPE_SHA256)
+	Assert(t, err == nil, "create sshfp failed:%v", err)
+	Equal(t, sshfp.String(), "4 2 ad05c93ccecc95f4581441d517bae8e258aa7433725649b04802e8beb155d365")
+
+	rdata, err := RdataFromString(RR_SSHFP, "4 2 AD05C93CCECC95F4581441D517BAE8E2 58AA7433725649B04802E8BEB155D365")
+	Assert(t, err == nil, "parse sshfp failed:%v", err)
+	Equal(t, rdata.Compare(sshfp), 0)
+
+	render := NewMsgRender()
+	render.WriteUint16(34)
+	rdata.Rend(render)
+	rdata2, err := RdataFromWire(RR_SSHFP, util.NewInputBuffer(render.Data()))
+	Assert(t, err == nil, "parse sshfp from wire failed:%v", err)
+	Equal(t, rdata2, rdata)
+
+	_, err = NewSSHFPFromAuthorizedKey(key, 3)
+	Equal(t, err, ErrUnknownSSHFPType)
+	_, err = NewSSHFPFromAuthorizedKey("ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIKhL6lC6bb76fPt8N9T14tKJ1/emDNTUKlw63PTl7deb", SSHFP_TYPE_SHA1)
+	Equal(t, err, ErrInvalidSSHPublicKey)
+	_, err = NewSSHFP([]byte{0, 0, 0, 3, 'f', 'o', 'o'}, SSHFP_TYPE_SHA1)
+	Equal(t, err, ErrUnknownSSHKeyAlgorithm)
+	_, err = NewSSHFP([]byte{0, 0, 0, 8, 'f', 'o', 'o'}, SSHFP_TYPE_SHA1)
+	Equal(t, err, ErrInvalidSSHPublicKey)
+}
@@ -0,0 +1,514 @@
+package g53
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//svc param keys defined in rfc9460 and rfc9461
+type SvcParamKey uint16
+
+const (
+	SVCB_KEY_MANDATORY       SvcParamKey = 0
+	SVCB_KEY_ALPN            SvcParamKey = 1
+	SVCB_KEY_NO_DEFAULT_ALPN SvcParamKey = 2
+	SVCB_KEY_PORT            SvcParamKey = 3
+	SVCB_KEY_IPV4HINT        SvcParamKey = 4
+	SVCB_KEY_ECH             SvcParamKey = 5
+	SVCB_KEY_IPV6HINT        SvcParamKey = 6
+	SVCB_KEY_DOHPATH         SvcParamKey = 7
+	SVCB_KEY_INVALID         SvcParamKey = 65535
+)
+
+var (
+	ErrUnknownSvcParamKey   = errors.New("unknown svc param key")
+	ErrDuplicateSvcParamKey = errors.New("duplicate svc param key")
+	ErrSvcParamKeyNotSorted = errors.New("svc param keys aren't in strictly increasing order")
+	ErrInvalidSvcParamValue = errors.New("invalid svc param value")
+	ErrMissingMandatoryKey  = errors.New("key in mandatory doesn't exist")
+)
+
+var svcParamKeyNames = map[SvcParamKey]string{
+	SVCB_KEY_MANDATORY:       "mandatory",
+	SVCB_KEY_ALPN:            "alpn",
+	SVCB_KEY_NO_DEFAULT_ALPN: "no-default-alpn",
+	SVCB_KEY_PORT:            "port",
+	SVCB_KEY_IPV4HINT:        "ipv4hint",
+	SVCB_KEY_ECH:             "ech",
+	SVCB_KEY_IPV6HINT:        "ipv6hint",
+	SVCB_KEY_DOHPATH:         "dohpath",
+}
+
+func (k SvcParamKey) String() string {
+	if s, ok := svcParamKeyNames[k]; ok {
+		return s
+	}
+	return "key" + strconv.Itoa(int(k))
+}
+
+func SvcParamKeyFromString(s string) (SvcParamKey, error) {
+	s = strings.ToLower(s)
+	for k, name := range svcParamKeyNames {
+		if name == s {
+			return k, nil
+		}
+	}
+
+	if strings.HasPrefix(s, "key") && len(s) > 3 {
+		if k, err := strconv.ParseUint(s[3:], 10, 16); err == nil && SvcParamKey(k) != SVCB_KEY_INVALID {
+			return SvcParamKey(k), nil
+		}
+	}
+	return SVCB_KEY_INVALID, ErrUnknownSvcParamKey
+}
+
+//Value holds the wire format of the param value
+type SvcParam struct {
+	Key   SvcParamKey
+	Value []byte
+}
+
+func (p SvcParam) String() string {
+	if len(p.Value) == 0 {
+		return p.Key.String()
+	}
+	return p.Key.String() + "=" + svcParamValueToString(p.Key, p.Value)
+}
+
+func SvcParamFromString(s string) (SvcParam, error) {
+	keyStr, valueStr, hasValue := s, "", false
+	if i := strings.IndexByte(s, '='); i != -1 {
+		keyStr, valueStr, hasValue = s[:i], s[i+1:], true
+		if len(valueStr) >= 2 && valueStr[0] == '"' && valueStr[len(valueStr)-1] == '"' {
+			valueStr = valueStr[1 : len(valueStr)-1]
+		}
+	}
+
+	key, err := SvcParamKeyFromString(keyStr)
+	if err != nil {
+		return SvcParam{}, err
+	}
+
+	if hasValue == false || valueStr == "" {
+		if key == SVCB_KEY_NO_DEFAULT_ALPN || key > SVCB_KEY_DOHPATH {
+			return SvcParam{Key: key}, nil
+		}
+		return SvcParam{}, fmt.Errorf("svc param %s has no value", key)
+	}
+
+	value, err := svcParamValueFromString(key, valueStr)
+	if err != nil {
+		return SvcParam{}, err
+	}
+
+	if err := validateSvcParamValue(key, value); err != nil {
+		return SvcParam{}, err
+	}
+	return SvcParam{Key: key, Value: value}, nil
+}
+
+func svcParamValueFromString(key SvcParamKey, s string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch key {
+	case SVCB_KEY_MANDATORY:
+		var keys []int
+		for _, field := range strings.Split(s, ",") {
+			k, err := SvcParamKeyFromString(field)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, int(k))
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			buf.WriteByte(byte(k >> 8))
+			buf.WriteByte(byte(k))
+		}
+
+	case SVCB_KEY_ALPN:
+		ids, err := splitEscapedList(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if len(id) == 0 || len(id) > 255 {
+				return nil, ErrInvalidSvcParamValue
+			}
+			buf.WriteByte(byte(len(id)))
+			buf.Write(id)
+		}
+
+	case SVCB_KEY_NO_DEFAULT_ALPN:
+		return nil, ErrInvalidSvcParamValue
+
+	case SVCB_KEY_PORT:
+		port, err := strconv.ParseUint(s, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(byte(port >> 8))
+		buf.WriteByte(byte(port))
+
+	case SVCB_KEY_IPV4HINT, SVCB_KEY_IPV6HINT:
+		for _, field := range strings.Split(s, ",") {
+			dt := RDF_D_IPV4
+			if key == SVCB_KEY_IPV6HINT {
+				dt = RDF_D_IPV6
+			}
+			ip, err := fieldFromString(dt, field)
+			if err != nil {
+				return nil, err
+			}
+			if key == SVCB_KEY_IPV6HINT {
+				buf.Write(ip.(net.IP).To16())
+			} else {
+				buf.Write(ip.(net.IP))
+			}
+		}
+
+	case SVCB_KEY_ECH:
+		d, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(d)
+
+	default:
+		d, err := unescapeCharString(s)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(d)
+	}
+	return buf.Bytes(), nil
+}
+
+func svcParamValueToString(key SvcParamKey, value []byte) string {
+	var ss []string
+	switch key {
+	case SVCB_KEY_MANDATORY:
+		for i := 0; i+1 < len(value); i += 2 {
+			ss = append(ss, SvcParamKey(uint16(value[i])<<8|uint16(value[i+1])).String())
+		}
+
+	case SVCB_KEY_ALPN:
+		for i := 0; i < len(value); {
+			l := int(value[i])
+			if i+1+l > len(value) {
+				break
+			}
+			ss = append(ss, escapeCharString(value[i+1:i+1+l], true))
+			i += 1 + l
+		}
+
+	case SVCB_KEY_PORT:
+		if len(value) == 2 {
+			ss = append(ss, strconv.Itoa(int(value[0])<<8|int(value[1])))
+		}
+
+	case SVCB_KEY_IPV4HINT:
+		for i := 0; i+net.IPv4len <= len(value); i += net.IPv4len {
+			ss = append(ss, net.IP(value[i:i+net.IPv4len]).String())
+		}
+
+	case SVCB_KEY_IPV6HINT:
+		for i := 0; i+net.IPv6len <= len(value); i += net.IPv6len {
+			ss = append(ss, net.IP(value[i:i+net.IPv6len]).String())
+		}
+
+	case SVCB_KEY_ECH:
+		ss = append(ss, base64.StdEncoding.EncodeToString(value))
+
+	default:
+		ss = append(ss, escapeCharString(value, false))
+	}
+	return strings.Join(ss, ",")
+}
+
+func validateSvcParamValue(key SvcParamKey, value []byte) error {
+	switch key {
+	case SVCB_KEY_MANDATORY:
+		if len(value) == 0 || len(value)%2 != 0 {
+			return ErrInvalidSvcParamValue
+		}
+		last := -1
+		for i := 0; i < len(value); i += 2 {
+			k := int(value[i])<<8 | int(value[i+1])
+			if k == int(SVCB_KEY_MANDATORY) {
+				return errors.New("mandatory shouldn't contain itself")
+			} else if k <= last {
+				return ErrSvcParamKeyNotSorted
+			}
+			last = k
+		}
+
+	case SVCB_KEY_ALPN:
+		if len(value) == 0 {
+			return ErrInvalidSvcParamValue
+		}
+		for i := 0; i < len(value); {
+			l := int(value[i])
+			if l == 0 || i+1+l > len(value) {
+				return ErrInvalidSvcParamValue
+			}
+			i += 1 + l
+		}
+
+	case SVCB_KEY_NO_DEFAULT_ALPN:
+		if len(value) != 0 {
+			return ErrInvalidSvcParamValue
+		}
+
+	case SVCB_KEY_PORT:
+		if len(value) != 2 {
+			return ErrInvalidSvcParamValue
+		}
+
+	case SVCB_KEY_IPV4HINT:
+		if len(value) == 0 || len(value)%net.IPv4len != 0 {
+			return ErrInvalidSvcParamValue
+		}
+
+	case SVCB_KEY_IPV6HINT:
+		if len(value) == 0 || len(value)%net.IPv6len != 0 {
+			return ErrInvalidSvcParamValue
+		}
+
+	case SVCB_KEY_DOHPATH:
+		if len(value) == 0 || utf8.Valid(value) == false {
+			return ErrInvalidSvcParamValue
+		}
+	}
+	return nil
+}
+
+//params should be sorted by key and keys in mandatory should exist
+func checkSvcParams(params []SvcParam) error {
+	keys := make(map[SvcParamKey]struct{}, len(params))
+	for i, p := range params {
+		if i > 0 && p.Key <= params[i-1].Key {
+			if p.Key == params[i-1].Key {
+				return ErrDuplicateSvcParamKey
+			}
+			return ErrSvcParamKeyNotSorted
+		}
+		keys[p.Key] = struct{}{}
+	}
+
+	for _, p := range params {
+		if p.Key != SVCB_KEY_MANDATORY {
+			continue
+		}
+		for i := 0; i+1 < len(p.Value); i += 2 {
+			if _, ok := keys[SvcParamKey(uint16(p.Value[i])<<8|uint16(p.Value[i+1]))]; ok == false {
+				return ErrMissingMandatoryKey
+			}
+		}
+	}
+
+	if _, ok := keys[SVCB_KEY_NO_DEFAULT_ALPN]; ok {
+		if _, ok := keys[SVCB_KEY_ALPN]; ok == false {
+			return errors.New("no-default-alpn requires alpn")
+		}
+	}
+	return nil
+}
+
+type SVCB struct {
+	Priority uint16
+	Target   *Name
+	Params   []SvcParam
+}
+
+func (svcb *SVCB) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, svcb.Priority, r)
+	rendField(RDF_C_NAME_UNCOMPRESS, svcb.Target, r)
+	rendField(RDF_C_BINARY, svcb.paramsToWire(), r)
+}
+
+func (svcb *SVCB) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, svcb.Priority, buf)
+	fieldToWire(RDF_C_NAME_UNCOMPRESS, svcb.Target, buf)
+	fieldToWire(RDF_C_BINARY, svcb.paramsToWire(), buf)
+}
+
+func (svcb *SVCB) paramsToWire() []byte {
+	buf := util.NewOutputBuffer(64)
+	for _, p := range svcb.Params {
+		buf.WriteUint16(uint16(p.Key))
+		buf.WriteUint16(uint16(len(p.Value)))
+		buf.WriteData(p.Value)
+	}
+	return buf.Data()
+}
+
+func (svcb *SVCB) Compare(other Rdata) int {
+	return svcb.compare(other.(*SVCB))
+}
+
+func (svcb *SVCB) compare(other *SVCB) int {
+	order := fieldCompare(RDF_C_UINT16, svcb.Priority, other.Priority)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_NAME_UNCOMPRESS, svcb.Target, other.Target)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, svcb.paramsToWire(), other.paramsToWire())
+}
+
+func (svcb *SVCB) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, svcb.Priority))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_NAME, svcb.Target))
+	for _, p := range svcb.Params {
+		buf.WriteString(" ")
+		buf.WriteString(p.String())
+	}
+	return buf.String()
+}
+
+func (svcb *SVCB) Param(key SvcParamKey) (SvcParam, bool) {
+	for _, p := range svcb.Params {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return SvcParam{}, false
+}
+
+func SVCBFromWire(buf *util.InputBuffer, ll uint16) (*SVCB, error) {
+	priority, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ll, err := fieldFromWire(RDF_C_NAME_UNCOMPRESS, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	var params []SvcParam
+	for ll > 0 {
+		key, left, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+		if err != nil {
+			return nil, err
+		}
+
+		vlen, left, err := fieldFromWire(RDF_C_UINT16, buf, left)
+		if err != nil {
+			return nil, err
+		}
+
+		if vlen.(uint16) > left {
+			return nil, ErrDataIsTooShort
+		}
+
+		value, _, err := fieldFromWire(RDF_C_BINARY, buf, vlen.(uint16))
+		if err != nil {
+			return nil, err
+		}
+		ll = left - vlen.(uint16)
+
+		p := SvcParam{Key: SvcParamKey(key.(uint16)), Value: value.([]byte)}
+		if p.Key == SVCB_KEY_INVALID {
+			return nil, ErrUnknownSvcParamKey
+		}
+		if err := validateSvcParamValue(p.Key, p.Value); err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+
+	if err := checkSvcParams(params); err != nil {
+		return nil, err
+	}
+
+	return &SVCB{
+		Priority: priority.(uint16),
+		Target:   target.(*Name),
+		Params:   params,
+	}, nil
+}
+
+func SVCBFromString(s string) (*SVCB, error) {
+	fields, err := splitRdataFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) < 2 {
+		return nil, errors.New("short of fields for svcb")
+	}
+
+	priority, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if priority.(int) < 0 || priority.(int) > 0xffff {
+		return nil, ErrOutOfRange
+	}
+
+	target, err := fieldFromString(RDF_D_NAME, fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var params []SvcParam
+	for _, field := range fields[2:] {
+		p, err := SvcParamFromString(field)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+
+	//presentation format doesn't require order
+	sort.SliceStable(params, func(i, j int) bool { return params[i].Key < params[j].Key })
+	if err := checkSvcParams(params); err != nil {
+		return nil, err
+	}
+
+	return &SVCB{
+		Priority: uint16(priority.(int)),
+		Target:   target.(*Name),
+		Params:   params,
+	}, nil
+}
+
+//https has the same rdata format with svcb
+type HTTPS struct {
+	SVCB
+}
+
+func (h *HTTPS) Compare(other Rdata) int {
+	return h.SVCB.compare(&other.(*HTTPS).SVCB)
+}
+
+func HTTPSFromWire(buf *util.InputBuffer, ll uint16) (*HTTPS, error) {
+	svcb, err := SVCBFromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPS{*svcb}, nil
+}
+
+func HTTPSFromString(s string) (*HTTPS, error) {
+	svcb, err := SVCBFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPS{*svcb}, nil
+}
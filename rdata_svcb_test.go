@@ -0,0 +1,93 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func svcbRdataFromHex(t *testing.T, typ RRType, rdata string) (Rdata, []byte, error) {
+	wire, _ := util.HexStrToBytes(rdata)
+	l := len(wire)
+	wire = append([]byte{byte(l >> 8), byte(l)}, wire...)
+	rd, err := RdataFromWire(typ, util.NewInputBuffer(wire))
+	return rd, wire[2:], err
+}
+
+func TestSVCB(t *testing.T) {
+	//test vectors from rfc9460 appendix D
+	cases := []struct {
+		typ  RRType
+		str  string
+		wire string
+	}{
+		{RR_SVCB, "0 foo.example.com.", "0000" + "03666f6f076578616d706c6503636f6d00"},
+		{RR_SVCB, "1 .", "0001" + "00"},
+		{RR_SVCB, "16 foo.example.com. port=53", "0010" + "03666f6f076578616d706c6503636f6d00" + "000300020035"},
+		{RR_SVCB, "1 foo.example.com. key667=hello", "0001" + "03666f6f076578616d706c6503636f6d00" + "029b000568656c6c6f"},
+		{RR_HTTPS, "1 foo.example.com. ipv6hint=2001:db8::1,2001:db8::53:1", "0001" + "03666f6f076578616d706c6503636f6d00" +
+			"0006002020010db800000000000000000000000120010db8000000000000000000530001"},
+		{RR_HTTPS, "16 foo.example.org. mandatory=alpn,ipv4hint alpn=h2,h3-19 ipv4hint=192.0.2.1", "0010" + "03666f6f076578616d706c65036f726700" +
+			"0000000400010004" + "00010009026832056833" + "2d3139" + "00040004c0000201"},
+		{RR_HTTPS, "16 foo.example.org. alpn=f\\\\oo\\,bar,h2", "0010" + "03666f6f076578616d706c65036f726700" +
+			"0001000c08665c6f6f2c62617202" + "6832"},
+		{RR_HTTPS, "1 . alpn=h2 no-default-alpn ech=AEP+DQA/ dohpath=/dns-query{?dns}", "0001" + "00" +
+			"00010003026832" + "00020000" + "00050006" + "0043fe0d003f" + "00070010" + "2f646e732d71756572797b3f646e737d"},
+	}
+
+	for _, c := range cases {
+		rdata, wire, err := svcbRdataFromHex(t, c.typ, c.wire)
+		Assert(t, err == nil, "parse %s from wire failed:%v", c.str, err)
+		Equal(t, rdata.String(), c.str)
+
+		rdata2, err := RdataFromString(c.typ, c.str)
+		Assert(t, err == nil, "parse %s failed:%v", c.str, err)
+		Equal(t, rdata.Compare(rdata2), 0)
+
+		render := NewMsgRender()
+		rdata2.Rend(render)
+		WireMatch(t, wire, render.Data())
+	}
+
+	//keys in presentation format could be in any order
+	rdata, err := RdataFromString(RR_HTTPS, `1 . port="8443" alpn="h2,h3" key65000 mandatory=port`)
+	Assert(t, err == nil, "parse https failed:%v", err)
+	Equal(t, rdata.String(), "1 . mandatory=port alpn=h2,h3 port=8443 key65000")
+	port, ok := rdata.(*HTTPS).Param(SVCB_KEY_PORT)
+	Assert(t, ok, "port should exists")
+	Equal(t, port.Value, []byte{0x20, 0xfb})
+
+	for _, s := range []string{
+		"1 . port=53 port=54",
+		"1 . mandatory=port",
+		"1 . mandatory=mandatory,port port=53",
+		"1 . no-default-alpn",
+		"1 . no-default-alpn=h2 alpn=h2",
+		"1 . port",
+		"1 . port=65536",
+		"1 . alpn=h2,,h3",
+		"1 . ipv4hint=2001:db8::1",
+		"1 . ipv6hint=192.0.2.1",
+		"1 . key65535=abc",
+		"1 . unknown=abc",
+		"65536 .",
+		"1",
+	} {
+		_, err := RdataFromString(RR_SVCB, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+
+	for _, s := range []string{
+		//key out of order
+		"0001" + "00" + "000300020035" + "00010003026832",
+		//duplicate key
+		"0001" + "00" + "000300020035" + "000300020036",
+		//wrong port length
+		"0001" + "00" + "00030001" + "35",
+		//value overflow
+		"0001" + "00" + "00030004" + "0035",
+	} {
+		_, _, err := svcbRdataFromHex(t, RR_SVCB, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
@@ -1,6 +1,11 @@
 package g53
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/ben-han-cn/g53/util"
@@ -17,6 +22,58 @@ func parseMatchRender(t *testing.T, rawData string) {
 	WireMatch(t, wire, render.Data())
 }
 
+type rdataCase struct {
+	typ     RRType
+	str     string
+	display string
+	wire    string
+}
+
+type badRdataString struct {
+	typ RRType
+	str string
+}
+
+type badRdataWire struct {
+	typ  RRType
+	wire string
+}
+
+//rdata parsed from str is displayed as display and rendered as wire which
+//could be parsed back, bad strings and wires should fail to parse
+func checkRdataCases(t *testing.T, cases []rdataCase, badStrings []badRdataString, badWires []badRdataWire) {
+	rdataWire := func(hexWire string) []byte {
+		wire, _ := util.HexStrToBytes(hexWire)
+		return append([]byte{byte(len(wire) >> 8), byte(len(wire))}, wire...)
+	}
+
+	for _, c := range cases {
+		rdata, err := RdataFromString(c.typ, c.str)
+		Assert(t, err == nil, "parse %s failed:%v", c.str, err)
+		Equal(t, rdata.String(), c.display)
+
+		render := NewMsgRender()
+		rdata.Rend(render)
+		wire := rdataWire(c.wire)
+		WireMatch(t, wire[2:], render.Data())
+
+		rdata2, err := RdataFromWire(c.typ, util.NewInputBuffer(wire))
+		Assert(t, err == nil, "parse %s from wire failed:%v", c.str, err)
+		Equal(t, rdata.Compare(rdata2), 0)
+		Equal(t, rdata2.String(), c.display)
+	}
+
+	for _, c := range badStrings {
+		_, err := RdataFromString(c.typ, c.str)
+		Assert(t, err != nil, "%s should be invalid", c.str)
+	}
+
+	for _, c := range badWires {
+		_, err := RdataFromWire(c.typ, util.NewInputBuffer(rdataWire(c.wire)))
+		Assert(t, err != nil, "%s should be invalid", c.wire)
+	}
+}
+
 func TestRdataFromToWire(t *testing.T) {
 	rawDatas := []string{
 		//a
@@ -125,6 +182,15 @@ func TestTxtParse(t *testing.T) {
 	}
 }
 
+func TestTxtToWire(t *testing.T) {
+	txt, err := RdataFromString(RR_TXT, "\"v=spf1\" \"-all\"")
+	Assert(t, err == nil, "txt should be valid:%v", err)
+	buf := util.NewOutputBuffer(0)
+	txt.ToWire(buf)
+	wire, _ := util.HexStrToBytes("06763d73706631042d616c6c")
+	WireMatch(t, wire, buf.Data())
+}
+
 func TestRdataFromString(t *testing.T) {
 	cases := []struct {
 		rdataType     RRType
@@ -144,3 +210,217 @@ func TestRdataFromString(t *testing.T) {
 		}
 	}
 }
+
+func TestUnknownRdata(t *testing.T) {
+	//type 731 isn't supported
+	parseMatchRender(t, "04b08500000100010000000003616161066e69757a756f036f72670002db0001c00c02db000100000e10000f09494e54454c2d33383604554e4958")
+
+	rrset, err := RRsetFromString("a.example. 300 CLASS32 TYPE731 \\# 6 abcd ef 012345")
+	Assert(t, err == nil, "generic rdata should be accepted but get %v", err)
+	Equal(t, rrset.Class, RRClass(32))
+	Equal(t, rrset.Type, RRType(731))
+	Equal(t, rrset.Rdatas[0], Rdata(&UnknownRdata{[]byte{0xab, 0xcd, 0xef, 0x01, 0x23, 0x45}}))
+	Equal(t, rrset.String(), "a.example.\t300\tCLASS32\tTYPE731\t\\# 6 abcdef012345\n")
+
+	rrset, err = RRsetFromString("a.example. 300 IN TYPE731 \\# 0")
+	Assert(t, err == nil, "empty generic rdata should be accepted but get %v", err)
+	Equal(t, rrset.Rdatas[0].String(), "\\# 0")
+
+	//known type in generic format
+	rdata, err := RdataFromString(RR_A, "\\# 4 c0000201")
+	Assert(t, err == nil, "a in generic format should be accepted but get %v", err)
+	Equal(t, rdata.String(), "192.0.2.1")
+
+	//empty generic rdata is parsed by known type, which should allow it
+	rdata, err = RdataFromString(RR_NULL, "\\# 0")
+	Assert(t, err == nil, "empty null rdata should be accepted but get %v", err)
+	_, ok := rdata.(*Null)
+	Assert(t, ok, "null in generic format should be parsed as null")
+	rdata, err = RdataFromString(RR_APL, "\\# 0")
+	Assert(t, err == nil, "empty apl rdata should be accepted but get %v", err)
+	_, ok = rdata.(*APL)
+	Assert(t, ok, "apl in generic format should be parsed as apl")
+	for _, typ := range []RRType{RR_A, RR_TXT, RR_MX, RR_OPENPGPKEY} {
+		_, err = RdataFromString(typ, "\\# 0")
+		Assert(t, err != nil, "empty %s rdata should be rejected", typ.String())
+	}
+
+	rrset, err = RRsetFromStrings([]string{"a.example. 300 IN APL \\# 0", "a.example. 300 IN APL 1:192.0.2.0/24"})
+	Assert(t, err == nil, "mixed generic and normal format should be accepted but get %v", err)
+	Assert(t, rrset.Rdatas[0].Compare(rrset.Rdatas[1]) != 0, "empty apl should differ")
+
+	for _, s := range []string{"\\# 3 abcd", "\\# abcd", "\\# 2 abcx"} {
+		_, err := RdataFromString(RRType(731), s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+	_, err = RdataFromString(RRType(731), "abcd")
+	Assert(t, err != nil, "unknown type should use generic format")
+
+	rdata1, _ := RdataFromString(RRType(731), "\\# 2 abcd")
+	rdata2, _ := RdataFromString(RRType(731), "\\# 2 abce")
+	Assert(t, rdata1.Compare(rdata2) < 0, "unknown rdata should be compared bytewise")
+}
+
+func TestLegacyRdata(t *testing.T) {
+	checkRdataCases(t, []rdataCase{
+		{RR_HINFO, `"INTEL-386" UNIX`, `"INTEL-386" "UNIX"`, "09494e54454c2d33383604554e4958"},
+		{RR_HINFO, `"Generic PC" "Linux \"5\""`, `"Generic PC" "Linux \"5\""`, "0a47656e65726963205043094c696e757820223522"},
+		{RR_AFSDB, "1 afs.example.com.", "1 afs.example.com.", "000103616673076578616d706c6503636f6d00"},
+		{RR_KX, "10 kx.example.com.", "10 kx.example.com.", "000a026b78076578616d706c6503636f6d00"},
+		{RR_RT, "20 rt.example.com.", "20 rt.example.com.", "001402727407" + "6578616d706c6503636f6d00"},
+		{RR_PX, "10 example.com. px.example.com.", "10 example.com. px.example.com.", "000a076578616d706c6503636f6d0002707807" + "6578616d706c6503636f6d00"},
+		{RR_NULL, "\\# 3 abcdef", "\\# 3 abcdef", "abcdef"},
+		{RR_WKS, "192.0.2.1 tcp smtp 21 ftp 80", "192.0.2.1 6 21 25 80", "c0000201" + "06" + "0000044000000000000080"},
+	}, []badRdataString{
+		{RR_HINFO, `"INTEL-386"`},
+		{RR_HINFO, `"INTEL-386 UNIX`},
+		{RR_AFSDB, "65536 afs.example.com."},
+		{RR_KX, "10"},
+		{RR_PX, "10 example.com."},
+		{RR_NULL, "abcdef"},
+		{RR_WKS, "192.0.2.1 icmpv7 25"},
+		{RR_WKS, "192.0.2.1 6 gopherx"},
+		{RR_WKS, "2001:db8::1 6 25"},
+	}, nil)
+
+	//only minfo could compress names with example.com in front, so its
+	//wire can't be parsed without the message
+	name, _ := NameFromString("example.com.")
+	for _, c := range []rdataCase{
+		{RR_AFSDB, "1 afs.example.com.", "1 afs.example.com.", "000103616673076578616d706c6503636f6d00"},
+		{RR_MINFO, "admin.example.com. error.example.com.", "admin.example.com. error.example.com.", "0561646d696ec000056572726f72c000"},
+	} {
+		rdata, err := RdataFromString(c.typ, c.str)
+		Assert(t, err == nil, "parse %s failed:%v", c.str, err)
+		Equal(t, rdata.String(), c.display)
+		render := NewMsgRender()
+		render.WriteName(name, true)
+		rdata.Rend(render)
+		wire, _ := util.HexStrToBytes(c.wire)
+		WireMatch(t, wire, render.Data()[13:])
+	}
+
+	rrset, err := RRsetFromString("example.com. 3600 IN HINFO \"INTEL-386\" \"UNIX\"")
+	Assert(t, err == nil, "parse hinfo rrset failed:%v", err)
+	Equal(t, rrset.Rdatas[0].(*HInfo).CPU, "INTEL-386")
+}
+
+func TestApplicationRdata(t *testing.T) {
+	key := "AQNRU3mG7TVTO2BkR47usntb102uFJtugbo6BSGvgqt4AQ=="
+	keyWire := "010351537986ed35533b6064478eeeb27b5bd74dae149b6e81ba3a0521af82ab7801"
+	checkRdataCases(t, []rdataCase{
+		{RR_URI, `10 1 "ftp://ftp1.example.com/public"`, `10 1 "ftp://ftp1.example.com/public"`,
+			"000a0001" + "6674703a2f2f667470312e6578616d706c652e636f6d2f7075626c6963"},
+		{RR_CERT, "PGP 0 0 AQID", "PGP 0 0 AQID", "0003000000" + "010203"},
+		{RR_CERT, "1 12345 RSASHA256 AQ ID", "PKIX 12345 8 AQID", "00013039" + "08" + "010203"},
+		{RR_CERT, "65000 1 1 AQID", "65000 1 1 AQID", "fde8000101" + "010203"},
+		{RR_IPSECKEY, "10 1 2 192.0.2.38 " + key, "10 1 2 192.0.2.38 " + key, "0a0102" + "c0000226" + keyWire},
+		{RR_IPSECKEY, "10 0 2 . " + key, "10 0 2 . " + key, "0a0002" + keyWire},
+		{RR_IPSECKEY, "10 3 2 mygateway.example.com. " + key, "10 3 2 mygateway.example.com. " + key,
+			"0a0302" + "096d7967617465776179076578616d706c6503636f6d00" + keyWire},
+		{RR_IPSECKEY, "10 2 2 2001:0DB8:0:8002::2000:1 " + key, "10 2 2 2001:db8:0:8002::2000:1 " + key,
+			"0a0202" + "20010db8000080020000000020000001" + keyWire},
+		{RR_IPSECKEY, "10 1 0 192.0.2.38", "10 1 0 192.0.2.38", "0a0100" + "c0000226"},
+		{RR_APL, "1:192.168.32.0/21 !1:192.168.38.0/28", "1:192.168.32.0/21 !1:192.168.38.0/28",
+			"00011503c0a820" + "00011c83c0a826"},
+		{RR_APL, "1:224.0.0.0/4 2:FF00:0:0:0:0:0:0:0/8", "1:224.0.0.0/4 2:ff00::/8", "00010401e0" + "00020801ff"},
+		{RR_APL, "!1:0.0.0.0/0", "!1:0.0.0.0/0", "00010080"},
+	}, []badRdataString{
+		{RR_URI, `10 1 ftp://ftp1.example.com/public`},
+		{RR_URI, `10 1 ""`},
+		{RR_URI, `10 65536 "ftp://ftp1.example.com/public"`},
+		{RR_CERT, "XPKI 0 0 AQID"},
+		{RR_CERT, "PGP 0 RSA AQID"},
+		{RR_IPSECKEY, "10 4 2 192.0.2.38 " + key},
+		{RR_IPSECKEY, "10 1 2 2001:db8::1 " + key},
+		{RR_IPSECKEY, "10 0 2 192.0.2.38 " + key},
+		{RR_APL, "3:192.168.32.0/21"},
+		{RR_APL, "1:192.168.32.0/33"},
+		{RR_APL, "1:192.168.32.0"},
+		{RR_APL, "2:192.168.32.0/21"},
+	}, []badRdataWire{
+		{RR_URI, "000a0001"},
+		{RR_IPSECKEY, "0a0402" + keyWire},
+		{RR_IPSECKEY, "0a0102" + "c00002"},
+		{RR_APL, "00011503c0a800"},
+		{RR_APL, "00011505c0a8200101"},
+		{RR_APL, "00012103c0a820"},
+		{RR_APL, "00031503c0a820"},
+		{RR_APL, "00011503c0a8"},
+	})
+}
+
+func TestAddressRdata(t *testing.T) {
+	checkRdataCases(t, []rdataCase{
+		{RR_EUI48, "00-00-5e-00-53-2a", "00-00-5e-00-53-2a", "00005e00532a"},
+		{RR_EUI48, "00-00-5E-00-53-2A", "00-00-5e-00-53-2a", "00005e00532a"},
+		{RR_EUI64, "00-00-5e-ef-10-00-00-2a", "00-00-5e-ef-10-00-00-2a", "00005eef1000002a"},
+		{RR_NID, "10 0014:4fff:ff20:ee64", "10 0014:4fff:ff20:ee64", "000a" + "00144fffff20ee64"},
+		{RR_NID, "20 15:5FFF:fe20:0", "20 0015:5fff:fe20:0000", "0014" + "00155ffffe200000"},
+		{RR_L64, "10 2001:0DB8:1140:1000", "10 2001:0db8:1140:1000", "000a" + "20010db811401000"},
+		{RR_L32, "10 10.1.2.0", "10 10.1.2.0", "000a" + "0a010200"},
+		{RR_LP, "10 l64-subnet1.example.com.", "10 l64-subnet1.example.com.",
+			"000a" + "0b6c36342d7375626e657431076578616d706c6503636f6d00"},
+	}, []badRdataString{
+		{RR_EUI48, "00-00-5e-00-53"},
+		{RR_EUI48, "00:00:5e:00:53:2a"},
+		{RR_EUI48, "00-00-5e-00-53-2"},
+		{RR_EUI48, "00-00-5e-00-53-2g"},
+		{RR_EUI64, "00-00-5e-00-53-2a"},
+		{RR_NID, "10 0014:4fff:ff20"},
+		{RR_NID, "10 0014:4fff:ff20:ee640"},
+		{RR_NID, "10 0014:4fff::ee64"},
+		{RR_L64, "65536 2001:0db8:1140:1000"},
+		{RR_L32, "10 2001:db8::1"},
+		{RR_LP, "10"},
+	}, []badRdataWire{
+		{RR_EUI48, "00005e0053"},
+		{RR_EUI64, "00005e00532a"},
+		{RR_NID, "000a00144fffff20ee"},
+		{RR_L32, "000a0a01020001"},
+	})
+
+	mac, _ := net.ParseMAC("00:00:5e:00:53:2a")
+	eui48, err := NewEUI48(mac)
+	Assert(t, err == nil, "create eui48 failed:%v", err)
+	Equal(t, eui48.String(), "00-00-5e-00-53-2a")
+	Equal(t, eui48.HardwareAddr().String(), "00:00:5e:00:53:2a")
+	mac[0] = 0xff
+	Equal(t, eui48.String(), "00-00-5e-00-53-2a")
+	_, err = NewEUI64(mac)
+	Assert(t, err != nil, "eui64 should has 8 octets")
+
+	mac, _ = net.ParseMAC("00:00:5e:ef:10:00:00:2a")
+	eui64, err := NewEUI64(mac)
+	Assert(t, err == nil, "create eui64 failed:%v", err)
+	Equal(t, eui64.HardwareAddr().String(), "00:00:5e:ef:10:00:00:2a")
+}
+
+func TestIdentityRdata(t *testing.T) {
+	hit := "200100107B1A74DF365639CC39F1D578"
+	key := "AwEAAbdxyhNuSutc5EMzxTs9LBPCIkOFH8cIvM4p9+LrV4e19WzK00+CI6zBCQTdtWsuxKbWIy87UOoJTwkUs7lBu+Upr1gsNrut79ryra+bSRGQb1slImA8YVJyuIDsj7kwzG7jnERNqnWxZ48AWkskmdHaVDP4BcelrTI3rMXdXF5D"
+	keyData, _ := base64.StdEncoding.DecodeString(key)
+	keyWire := hex.EncodeToString(keyData)
+	hipHeader := "10" + "02" + fmt.Sprintf("%04x", len(keyData)) + strings.ToLower(hit) + keyWire
+	checkRdataCases(t, []rdataCase{
+		{RR_OPENPGPKEY, "mQENBFVHm5sBCADO AQAB", "mQENBFVHm5sBCADOAQAB", "99010d04554" + "79b9b0108" + "00ce010001"},
+		{RR_DHCID, "AAIBY2/AuCccgoJbsaxcQc9TUapptP69 lOjxfNuVAA2kjEA=", "AAIBY2/AuCccgoJbsaxcQc9TUapptP69lOjxfNuVAA2kjEA=",
+			"000201636fc0b8271c82825bb1ac5c41cf5351aa69b4febd94e8f17cdb95000da48c40"},
+		{RR_HIP, "2 " + hit + " " + key, "2 " + hit + " " + key, hipHeader},
+		{RR_HIP, "2 " + strings.ToLower(hit) + " " + key + " rvs1.example.com. rvs2.example.com.",
+			"2 " + hit + " " + key + " rvs1.example.com. rvs2.example.com.",
+			hipHeader + "0472767331076578616d706c6503636f6d00" + "0472767332076578616d706c6503636f6d00"},
+	}, []badRdataString{
+		{RR_OPENPGPKEY, ""},
+		{RR_OPENPGPKEY, "mQENBFVHm5sBCADO!"},
+		{RR_DHCID, "AAIB"},
+		{RR_HIP, "2 " + hit},
+		{RR_HIP, "256 " + hit + " " + key},
+		{RR_HIP, "2 xyz " + key},
+	}, []badRdataWire{
+		{RR_DHCID, "000201"},
+		{RR_HIP, "1002ffff" + strings.ToLower(hit)},
+		{RR_HIP, "0002" + fmt.Sprintf("%04x", len(keyData)) + keyWire},
+		{RR_HIP, hipHeader + "04727673"},
+	})
+}
@@ -0,0 +1,239 @@
+package g53
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+	"regexp"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//certificate usage, selector and matching type defined in rfc6698
+const (
+	TLSA_USAGE_PKIX_TA uint8 = 0
+	TLSA_USAGE_PKIX_EE uint8 = 1
+	TLSA_USAGE_DANE_TA uint8 = 2
+	TLSA_USAGE_DANE_EE uint8 = 3
+)
+
+const (
+	TLSA_SELECTOR_CERT uint8 = 0
+	TLSA_SELECTOR_SPKI uint8 = 1
+)
+
+const (
+	TLSA_MATCHING_FULL   uint8 = 0
+	TLSA_MATCHING_SHA256 uint8 = 1
+	TLSA_MATCHING_SHA512 uint8 = 2
+)
+
+var (
+	ErrUnknownTLSASelector     = errors.New("unknown tlsa selector")
+	ErrUnknownTLSAMatchingType = errors.New("unknown tlsa matching type")
+)
+
+type TLSA struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+	Data         []byte
+}
+
+func (tlsa *TLSA) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT8, tlsa.Usage, r)
+	rendField(RDF_C_UINT8, tlsa.Selector, r)
+	rendField(RDF_C_UINT8, tlsa.MatchingType, r)
+	rendField(RDF_C_BINARY, tlsa.Data, r)
+}
+
+func (tlsa *TLSA) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT8, tlsa.Usage, buf)
+	fieldToWire(RDF_C_UINT8, tlsa.Selector, buf)
+	fieldToWire(RDF_C_UINT8, tlsa.MatchingType, buf)
+	fieldToWire(RDF_C_BINARY, tlsa.Data, buf)
+}
+
+func (tlsa *TLSA) Compare(other Rdata) int {
+	return tlsa.compare(other.(*TLSA))
+}
+
+func (tlsa *TLSA) compare(other *TLSA) int {
+	order := fieldCompare(RDF_C_UINT8, tlsa.Usage, other.Usage)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, tlsa.Selector, other.Selector)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, tlsa.MatchingType, other.MatchingType)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, tlsa.Data, other.Data)
+}
+
+func (tlsa *TLSA) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, tlsa.Usage))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, tlsa.Selector))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, tlsa.MatchingType))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_HEX, tlsa.Data))
+	return buf.String()
+}
+
+//check whether the certificate matches the association data
+func (tlsa *TLSA) Match(cert *x509.Certificate) bool {
+	data, err := TLSAAssociationData(cert, tlsa.Selector, tlsa.MatchingType)
+	return err == nil && bytes.Equal(data, tlsa.Data)
+}
+
+func NewTLSA(usage, selector, matchingType uint8, cert *x509.Certificate) (*TLSA, error) {
+	data, err := TLSAAssociationData(cert, selector, matchingType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TLSA{
+		Usage:        usage,
+		Selector:     selector,
+		MatchingType: matchingType,
+		Data:         data,
+	}, nil
+}
+
+//association data is the full certificate or subject public key info
+//or their sha2 digest
+func TLSAAssociationData(cert *x509.Certificate, selector, matchingType uint8) ([]byte, error) {
+	var data []byte
+	switch selector {
+	case TLSA_SELECTOR_CERT:
+		data = cert.Raw
+	case TLSA_SELECTOR_SPKI:
+		data = cert.RawSubjectPublicKeyInfo
+	default:
+		return nil, ErrUnknownTLSASelector
+	}
+
+	switch matchingType {
+	case TLSA_MATCHING_FULL:
+		return append([]byte(nil), data...), nil
+	case TLSA_MATCHING_SHA256:
+		digest := sha256.Sum256(data)
+		return digest[:], nil
+	case TLSA_MATCHING_SHA512:
+		digest := sha512.Sum512(data)
+		return digest[:], nil
+	default:
+		return nil, ErrUnknownTLSAMatchingType
+	}
+}
+
+func TLSAFromWire(buf *util.InputBuffer, ll uint16) (*TLSA, error) {
+	usage, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	matchingType, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	return &TLSA{
+		Usage:        usage.(uint8),
+		Selector:     selector.(uint8),
+		MatchingType: matchingType.(uint8),
+		Data:         data.([]byte),
+	}, nil
+}
+
+var tlsaRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(.*?)\s*$`)
+var hexDataTemplate = regexp.MustCompile(`\s+`)
+
+func TLSAFromString(s string) (*TLSA, error) {
+	fields := tlsaRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 5 {
+		return nil, errors.New("short of fields for tlsa")
+	}
+
+	fields = fields[1:]
+	var ints [3]uint8
+	for i := 0; i < 3; i++ {
+		d, err := fieldFromString(RDF_D_INT, fields[i])
+		if err != nil {
+			return nil, err
+		} else if d.(int) < 0 || d.(int) > 255 {
+			return nil, ErrOutOfRange
+		}
+		ints[i] = uint8(d.(int))
+	}
+
+	data, err := fieldFromString(RDF_D_HEX, hexDataTemplate.ReplaceAllString(fields[3], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TLSA{
+		Usage:        ints[0],
+		Selector:     ints[1],
+		MatchingType: ints[2],
+		Data:         data.([]byte),
+	}, nil
+}
+
+//smimea has the same rdata format with tlsa
+type SMIMEA struct {
+	TLSA
+}
+
+func (smimea *SMIMEA) Compare(other Rdata) int {
+	return smimea.TLSA.compare(&other.(*SMIMEA).TLSA)
+}
+
+func NewSMIMEA(usage, selector, matchingType uint8, cert *x509.Certificate) (*SMIMEA, error) {
+	tlsa, err := NewTLSA(usage, selector, matchingType, cert)
+	if err != nil {
+		return nil, err
+	}
+	return &SMIMEA{*tlsa}, nil
+}
+
+func SMIMEAFromWire(buf *util.InputBuffer, ll uint16) (*SMIMEA, error) {
+	tlsa, err := TLSAFromWire(buf, ll)
+	if err != nil {
+		return nil, err
+	}
+	return &SMIMEA{*tlsa}, nil
+}
+
+func SMIMEAFromString(s string) (*SMIMEA, error) {
+	tlsa, err := TLSAFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &SMIMEA{*tlsa}, nil
+}
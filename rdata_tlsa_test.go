@@ -0,0 +1,75 @@
+package g53
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+func selfSignedCert(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Assert(t, err == nil, "generate key failed:%v", err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Assert(t, err == nil, "create cert failed:%v", err)
+	cert, err := x509.ParseCertificate(der)
+	Assert(t, err == nil, "parse cert failed:%v", err)
+	return cert
+}
+
+func TestTLSA(t *testing.T) {
+	//rfc6698 2.3
+	str := "0 0 1 d2abde240d7cd3ee6b4b28c54df034b97983a1d16e8a410e4561cb106618e971"
+	for _, typ := range []RRType{RR_TLSA, RR_SMIMEA} {
+		rdata, err := RdataFromString(typ, "0 0 1 d2abde240d7cd3ee6b4b28c54df034b9 7983a1d16e8a410e4561cb106618e971")
+		Assert(t, err == nil, "parse %v failed:%v", typ, err)
+		Equal(t, rdata.String(), str)
+
+		render := NewMsgRender()
+		render.WriteUint16(35)
+		rdata.Rend(render)
+		rdata2, err := RdataFromWire(typ, util.NewInputBuffer(render.Data()))
+		Assert(t, err == nil, "parse %v from wire failed:%v", typ, err)
+		Equal(t, rdata2, rdata)
+	}
+
+	cert := selfSignedCert(t)
+	tlsa, err := NewTLSA(TLSA_USAGE_DANE_EE, TLSA_SELECTOR_SPKI, TLSA_MATCHING_SHA256, cert)
+	Assert(t, err == nil, "create tlsa failed:%v", err)
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	Equal(t, tlsa.Data, digest[:])
+	Assert(t, tlsa.Match(cert), "cert should match")
+	Assert(t, tlsa.Match(selfSignedCert(t)) == false, "other cert shouldn't match")
+
+	tlsa, _ = NewTLSA(TLSA_USAGE_DANE_TA, TLSA_SELECTOR_CERT, TLSA_MATCHING_FULL, cert)
+	Equal(t, tlsa.Data, cert.Raw)
+	tlsa, _ = NewTLSA(TLSA_USAGE_DANE_TA, TLSA_SELECTOR_CERT, TLSA_MATCHING_SHA512, cert)
+	Equal(t, len(tlsa.Data), 64)
+
+	smimea, err := NewSMIMEA(TLSA_USAGE_DANE_EE, TLSA_SELECTOR_SPKI, TLSA_MATCHING_SHA256, cert)
+	Assert(t, err == nil, "create smimea failed:%v", err)
+	Assert(t, smimea.Match(cert), "cert should match")
+
+	_, err = NewTLSA(TLSA_USAGE_DANE_EE, 2, TLSA_MATCHING_SHA256, cert)
+	Equal(t, err, ErrUnknownTLSASelector)
+	_, err = NewTLSA(TLSA_USAGE_DANE_EE, TLSA_SELECTOR_SPKI, 3, cert)
+	Equal(t, err, ErrUnknownTLSAMatchingType)
+
+	for _, s := range []string{"3 1 1", "3 1 256 abcd", "3 1 1 abc", "3 1 1 xyzw"} {
+		_, err := RdataFromString(RR_TLSA, s)
+		Assert(t, err != nil, "%s should be invalid", s)
+	}
+}
@@ -0,0 +1,119 @@
+package g53
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+const genericRdataPrefix = "\\#"
+
+//rdata of type which isn't supported, raw data is kept and
+//displayed in generic format defined in rfc3597
+type UnknownRdata struct {
+	Data []byte
+}
+
+func (rd *UnknownRdata) Rend(r *MsgRender) {
+	rendField(RDF_C_BINARY, rd.Data, r)
+}
+
+func (rd *UnknownRdata) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_BINARY, rd.Data, buf)
+}
+
+func (rd *UnknownRdata) Compare(other Rdata) int {
+	return fieldCompare(RDF_C_BINARY, rd.Data, other.(*UnknownRdata).Data)
+}
+
+func (rd *UnknownRdata) String() string {
+	if len(rd.Data) == 0 {
+		return genericRdataPrefix + " 0"
+	} else {
+		return strings.Join([]string{
+			genericRdataPrefix,
+			strconv.Itoa(len(rd.Data)),
+			fieldToString(RDF_D_HEX, rd.Data)}, " ")
+	}
+}
+
+func UnknownRdataFromWire(buf *util.InputBuffer, ll uint16) (*UnknownRdata, error) {
+	f, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	} else if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	} else {
+		d, _ := f.([]uint8)
+		return &UnknownRdata{d}, nil
+	}
+}
+
+func UnknownRdataFromString(s string) (*UnknownRdata, error) {
+	d, err := genericRdataFromString(s)
+	if err != nil {
+		return nil, err
+	} else {
+		return &UnknownRdata{d}, nil
+	}
+}
+
+func isGenericRdata(s string) bool {
+	fields := strings.Fields(s)
+	return len(fields) > 0 && fields[0] == genericRdataPrefix
+}
+
+//\# <length> <hex data>, hex data could be split by space
+func genericRdataFromString(s string) ([]byte, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || fields[0] != genericRdataPrefix {
+		return nil, errors.New("generic rdata should start with \\# and length")
+	}
+
+	l, err := strconv.Atoi(fields[1])
+	if err != nil || l < 0 || l > 65535 {
+		return nil, fmt.Errorf("generic rdata length %s isn't valid", fields[1])
+	}
+
+	f, err := fieldFromString(RDF_D_HEX, strings.Join(fields[2:], ""))
+	if err != nil {
+		return nil, err
+	}
+
+	d, _ := f.([]uint8)
+	if len(d) != l {
+		return nil, fmt.Errorf("generic rdata length %d doesn't match data length %d", l, len(d))
+	}
+	return d, nil
+}
+
+//supported types whose rdata could be empty
+var emptyRdataTypes = map[RRType]bool{
+	RR_NULL: true,
+	RR_APL:  true,
+	RR_OPT:  true,
+}
+
+//rdata in generic format is converted to the type specific
+//rdata if the type is supported
+func rdataFromGenericString(t RRType, s string) (Rdata, error) {
+	d, err := genericRdataFromString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	rdata, err := rdataFromWire(t, util.NewInputBuffer(d), uint16(len(d)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d) == 0 && emptyRdataTypes[t] == false {
+		if _, ok := rdata.(*UnknownRdata); ok == false {
+			return nil, fmt.Errorf("%s rdata cann't be empty", t.String())
+		}
+	}
+	return rdata, nil
+}
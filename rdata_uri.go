@@ -0,0 +1,120 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//uri defined in rfc7553, target isn't a <character-string>, it takes
+//the rest of rdata
+type URI struct {
+	Priority uint16
+	Weight   uint16
+	Target   string
+}
+
+func (uri *URI) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT16, uri.Priority, r)
+	rendField(RDF_C_UINT16, uri.Weight, r)
+	rendField(RDF_C_BINARY, []byte(uri.Target), r)
+}
+
+func (uri *URI) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT16, uri.Priority, buf)
+	fieldToWire(RDF_C_UINT16, uri.Weight, buf)
+	fieldToWire(RDF_C_BINARY, []byte(uri.Target), buf)
+}
+
+func (uri *URI) Compare(other Rdata) int {
+	otherURI := other.(*URI)
+	order := fieldCompare(RDF_C_UINT16, uri.Priority, otherURI.Priority)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT16, uri.Weight, otherURI.Weight)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, []byte(uri.Target), []byte(otherURI.Target))
+}
+
+func (uri *URI) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, uri.Priority))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, uri.Weight))
+	buf.WriteString(" ")
+	buf.WriteString(quoteCharString([]byte(uri.Target)))
+	return buf.String()
+}
+
+func URIFromWire(buf *util.InputBuffer, ll uint16) (*URI, error) {
+	priority, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	weight, ll, err := fieldFromWire(RDF_C_UINT16, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll == 0 {
+		return nil, errors.New("uri target is empty")
+	}
+
+	target, _, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	return &URI{
+		Priority: priority.(uint16),
+		Weight:   weight.(uint16),
+		Target:   string(target.([]byte)),
+	}, nil
+}
+
+func URIFromString(s string) (*URI, error) {
+	fields, err := splitRdataFields(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) != 3 {
+		return nil, errors.New("fields count for uri isn't 3")
+	}
+
+	var ints [2]uint16
+	for i := 0; i < 2; i++ {
+		d, err := fieldFromString(RDF_D_INT, fields[i])
+		if err != nil {
+			return nil, err
+		} else if d.(int) < 0 || d.(int) > math.MaxUint16 {
+			return nil, ErrOutOfRange
+		}
+		ints[i] = uint16(d.(int))
+	}
+
+	if len(fields[2]) < 2 || fields[2][0] != '"' || fields[2][len(fields[2])-1] != '"' {
+		return nil, errors.New("uri target should be quoted")
+	}
+
+	target, err := unescapeCharString(fields[2][1 : len(fields[2])-1])
+	if err != nil {
+		return nil, err
+	} else if len(target) == 0 {
+		return nil, errors.New("uri target is empty")
+	}
+
+	return &URI{
+		Priority: ints[0],
+		Weight:   ints[1],
+		Target:   string(target),
+	}, nil
+}
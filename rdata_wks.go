@@ -0,0 +1,191 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var wksProtocols = map[string]uint8{
+	"tcp": 6,
+	"udp": 17,
+}
+
+var wksServices = map[string]uint16{
+	"ftp-data": 20,
+	"ftp":      21,
+	"ssh":      22,
+	"telnet":   23,
+	"smtp":     25,
+	"domain":   53,
+	"http":     80,
+	"pop3":     110,
+	"sunrpc":   111,
+	"nntp":     119,
+	"ntp":      123,
+	"imap":     143,
+	"snmp":     161,
+	"ldap":     389,
+	"https":    443,
+}
+
+//wks defined in rfc1035, ports are kept in ascending order
+type WKS struct {
+	Address  net.IP
+	Protocol uint8
+	Ports    []uint16
+}
+
+func (wks *WKS) Rend(r *MsgRender) {
+	rendField(RDF_C_IPV4, wks.Address, r)
+	rendField(RDF_C_UINT8, wks.Protocol, r)
+	rendField(RDF_C_BINARY, encodePortBitmap(wks.Ports), r)
+}
+
+func (wks *WKS) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_IPV4, wks.Address, buf)
+	fieldToWire(RDF_C_UINT8, wks.Protocol, buf)
+	fieldToWire(RDF_C_BINARY, encodePortBitmap(wks.Ports), buf)
+}
+
+func (wks *WKS) Compare(other Rdata) int {
+	otherWKS := other.(*WKS)
+	order := fieldCompare(RDF_C_IPV4, wks.Address, otherWKS.Address)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, wks.Protocol, otherWKS.Protocol)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, encodePortBitmap(wks.Ports), encodePortBitmap(otherWKS.Ports))
+}
+
+func (wks *WKS) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_IPV4, wks.Address))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, wks.Protocol))
+	for _, port := range wks.Ports {
+		buf.WriteString(" ")
+		buf.WriteString(fieldToString(RDF_D_INT, port))
+	}
+	return buf.String()
+}
+
+func encodePortBitmap(ports []uint16) []byte {
+	var max uint16
+	for _, port := range ports {
+		if port > max {
+			max = port
+		}
+	}
+
+	if len(ports) == 0 {
+		return []byte{}
+	}
+
+	bitmap := make([]byte, max/8+1)
+	for _, port := range ports {
+		bitmap[port/8] |= 0x80 >> (port % 8)
+	}
+	return bitmap
+}
+
+func decodePortBitmap(bitmap []byte) []uint16 {
+	var ports []uint16
+	for i, b := range bitmap {
+		for j := 0; j < 8; j++ {
+			if b&(0x80>>uint(j)) != 0 {
+				ports = append(ports, uint16(i*8+j))
+			}
+		}
+	}
+	return ports
+}
+
+func WKSFromWire(buf *util.InputBuffer, ll uint16) (*WKS, error) {
+	address, ll, err := fieldFromWire(RDF_C_IPV4, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	if len(bitmap.([]byte)) > 8192 {
+		return nil, errors.New("wks bitmap is too long")
+	}
+
+	return &WKS{
+		Address:  address.(net.IP),
+		Protocol: protocol.(uint8),
+		Ports:    decodePortBitmap(bitmap.([]byte)),
+	}, nil
+}
+
+//protocol and services could be number or well known name
+func WKSFromString(s string) (*WKS, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return nil, errors.New("short of fields for wks")
+	}
+
+	address, err := fieldFromString(RDF_D_IPV4, fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	protocol, ok := wksProtocols[strings.ToLower(fields[1])]
+	if ok == false {
+		p, err := strconv.ParseUint(fields[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("unknown wks protocol %s", fields[1])
+		}
+		protocol = uint8(p)
+	}
+
+	seen := make(map[uint16]struct{})
+	var ports []uint16
+	for _, field := range fields[2:] {
+		port, ok := wksServices[strings.ToLower(field)]
+		if ok == false {
+			p, err := strconv.ParseUint(field, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("unknown wks service %s", field)
+			}
+			port = uint16(p)
+		}
+
+		if _, ok := seen[port]; ok == false {
+			seen[port] = struct{}{}
+			ports = append(ports, port)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+
+	return &WKS{
+		Address:  address.(net.IP),
+		Protocol: protocol,
+		Ports:    ports,
+	}, nil
+}
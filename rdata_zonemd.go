@@ -0,0 +1,157 @@
+package g53
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"regexp"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//scheme and hash algorithm defined in rfc8976
+const (
+	ZONEMD_SCHEME_SIMPLE uint8 = 1
+)
+
+const (
+	ZONEMD_HASH_SHA384 uint8 = 1
+	ZONEMD_HASH_SHA512 uint8 = 2
+)
+
+//digest shorter than 12 octets isn't allowed
+const ZONEMD_MIN_DIGEST_LEN = 12
+
+var ErrZoneMDDigestTooShort = errors.New("zonemd digest is too short")
+
+type ZoneMD struct {
+	Serial uint32
+	Scheme uint8
+	Hash   uint8
+	Digest []byte
+}
+
+func (zonemd *ZoneMD) Rend(r *MsgRender) {
+	rendField(RDF_C_UINT32, zonemd.Serial, r)
+	rendField(RDF_C_UINT8, zonemd.Scheme, r)
+	rendField(RDF_C_UINT8, zonemd.Hash, r)
+	rendField(RDF_C_BINARY, zonemd.Digest, r)
+}
+
+func (zonemd *ZoneMD) ToWire(buf *util.OutputBuffer) {
+	fieldToWire(RDF_C_UINT32, zonemd.Serial, buf)
+	fieldToWire(RDF_C_UINT8, zonemd.Scheme, buf)
+	fieldToWire(RDF_C_UINT8, zonemd.Hash, buf)
+	fieldToWire(RDF_C_BINARY, zonemd.Digest, buf)
+}
+
+func (zonemd *ZoneMD) Compare(other Rdata) int {
+	otherZoneMD := other.(*ZoneMD)
+	order := fieldCompare(RDF_C_UINT32, zonemd.Serial, otherZoneMD.Serial)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, zonemd.Scheme, otherZoneMD.Scheme)
+	if order != 0 {
+		return order
+	}
+
+	order = fieldCompare(RDF_C_UINT8, zonemd.Hash, otherZoneMD.Hash)
+	if order != 0 {
+		return order
+	}
+
+	return fieldCompare(RDF_C_BINARY, zonemd.Digest, otherZoneMD.Digest)
+}
+
+func (zonemd *ZoneMD) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(fieldToString(RDF_D_INT, zonemd.Serial))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, zonemd.Scheme))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_INT, zonemd.Hash))
+	buf.WriteString(" ")
+	buf.WriteString(fieldToString(RDF_D_HEX, zonemd.Digest))
+	return buf.String()
+}
+
+func ZoneMDFromWire(buf *util.InputBuffer, ll uint16) (*ZoneMD, error) {
+	serial, ll, err := fieldFromWire(RDF_C_UINT32, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, ll, err := fieldFromWire(RDF_C_UINT8, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, ll, err := fieldFromWire(RDF_C_BINARY, buf, ll)
+	if err != nil {
+		return nil, err
+	}
+
+	if ll != 0 {
+		return nil, errors.New("extra data in rdata part")
+	}
+
+	if len(digest.([]byte)) < ZONEMD_MIN_DIGEST_LEN {
+		return nil, ErrZoneMDDigestTooShort
+	}
+
+	return &ZoneMD{
+		Serial: serial.(uint32),
+		Scheme: scheme.(uint8),
+		Hash:   hash.(uint8),
+		Digest: digest.([]byte),
+	}, nil
+}
+
+var zonemdRdataTemplate = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(.*?)\s*$`)
+
+func ZoneMDFromString(s string) (*ZoneMD, error) {
+	fields := zonemdRdataTemplate.FindStringSubmatch(s)
+	if len(fields) != 5 {
+		return nil, errors.New("short of fields for zonemd")
+	}
+
+	fields = fields[1:]
+	serial, err := fieldFromString(RDF_D_INT, fields[0])
+	if err != nil {
+		return nil, err
+	} else if serial.(int) < 0 || int64(serial.(int)) > math.MaxUint32 {
+		return nil, ErrOutOfRange
+	}
+
+	var ints [2]uint8
+	for i := 0; i < 2; i++ {
+		d, err := fieldFromString(RDF_D_INT, fields[i+1])
+		if err != nil {
+			return nil, err
+		} else if d.(int) < 0 || d.(int) > 255 {
+			return nil, ErrOutOfRange
+		}
+		ints[i] = uint8(d.(int))
+	}
+
+	digest, err := fieldFromString(RDF_D_HEX, hexDataTemplate.ReplaceAllString(fields[3], ""))
+	if err != nil {
+		return nil, err
+	} else if len(digest.([]byte)) < ZONEMD_MIN_DIGEST_LEN {
+		return nil, ErrZoneMDDigestTooShort
+	}
+
+	return &ZoneMD{
+		Serial: uint32(serial.(int)),
+		Scheme: ints[0],
+		Hash:   ints[1],
+		Digest: digest.([]byte),
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -243,7 +244,7 @@ func fieldToWire(ct RDFCodingType, data interface{}, buf *util.OutputBuffer) {
 	case RDF_C_TXT:
 		ds, _ := data.([]string)
 		for _, d := range ds {
-			fieldToWire(RDF_C_BYTE_BINARY, d, buf)
+			fieldToWire(RDF_C_BYTE_BINARY, []uint8(d), buf)
 		}
 
 	case RDF_C_BYTE_BINARY:
@@ -349,7 +350,7 @@ func fieldFromString(dt RDFDisplayType, s string) (interface{}, error) {
 		return txtStringParse(s)
 
 	case RDF_D_HEX:
-		d, err := util.HexStrToBytes(s)
+		d, err := hex.DecodeString(s)
 		if err != nil {
 			return nil, err
 		} else {
@@ -402,12 +403,10 @@ func fieldToString(dt RDFDisplayType, d interface{}) string {
 		return strings.Join(labels, " ")
 
 	case RDF_D_HEX:
+		//two digits per byte, otherwise leading zero is lost and
+		//the string cann't be parsed back
 		bs, _ := d.([]uint8)
-		s := ""
-		for _, b := range bs {
-			s += fmt.Sprintf("%x", b)
-		}
-		return s
+		return hex.EncodeToString(bs)
 
 	case RDF_D_B32:
 		bs, _ := d.([]uint8)
@@ -467,3 +466,139 @@ func txtStringParse(txt string) ([]string, error) {
 		return strs, nil
 	}
 }
+
+//split rdata text by white space, quoted string is kept
+//as one field including the quotes
+func splitRdataFields(s string) ([]string, error) {
+	var fields []string
+	inQuote := false
+	escaped := false
+	start := -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isSpace := c == ' ' || c == '\t' || c == '\n' || c == '\r'
+		if start == -1 {
+			if isSpace {
+				continue
+			}
+			start = i
+		}
+
+		if escaped {
+			escaped = false
+		} else if c == '\\' {
+			escaped = true
+		} else if c == '"' {
+			inQuote = !inQuote
+		} else if isSpace && inQuote == false {
+			fields = append(fields, s[start:i])
+			start = -1
+		}
+	}
+
+	if inQuote {
+		return nil, ErrQuoteInTxtIsNotInPair
+	}
+
+	if start != -1 {
+		fields = append(fields, s[start:])
+	}
+	return fields, nil
+}
+
+//escape non printable chars as \DDD, quote, semicolon, backslash and
+//comma (in value list) with backslash
+func escapeCharString(d []byte, escapeComma bool) string {
+	var buf bytes.Buffer
+	for _, c := range d {
+		switch {
+		case c < 0x21 || c > 0x7e:
+			fmt.Fprintf(&buf, "\\%03d", c)
+		case c == '"' || c == ';' || c == '\\' || (escapeComma && c == ','):
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+//quote the string, only quote, backslash and non printable chars are escaped
+func quoteCharString(d []byte) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, c := range d {
+		switch {
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&buf, "\\%03d", c)
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+//parse <character-string> which may be quoted
+func charStringFromString(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "\"") {
+		if len(s) < 2 || strings.HasSuffix(s, "\"") == false {
+			return nil, ErrQuoteInTxtIsNotInPair
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	d, err := unescapeCharString(s)
+	if err != nil {
+		return nil, err
+	} else if len(d) > 255 {
+		return nil, ErrStringIsTooLong
+	}
+	return d, nil
+}
+
+func unescapeCharString(s string) ([]byte, error) {
+	ss, err := unescapeList(s, false)
+	if err != nil {
+		return nil, err
+	}
+	return ss[0], nil
+}
+
+func splitEscapedList(s string) ([][]byte, error) {
+	return unescapeList(s, true)
+}
+
+func unescapeList(s string, splitByComma bool) ([][]byte, error) {
+	var result [][]byte
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' {
+			if i+1 >= len(s) {
+				return nil, errors.New("dangling escape char")
+			}
+			if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+				d, _ := strconv.Atoi(s[i+1 : i+4])
+				if d > 255 {
+					return nil, ErrOutOfRange
+				}
+				buf.WriteByte(byte(d))
+				i += 3
+			} else {
+				buf.WriteByte(s[i+1])
+				i += 1
+			}
+		} else if c == ',' && splitByComma {
+			result = append(result, append([]byte(nil), buf.Bytes()...))
+			buf.Reset()
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+	return append(result, buf.Bytes()), nil
+}
@@ -129,6 +129,7 @@ const (
 	RR_NSEC3      RRType = 50 /* RFC 5155 */
 	RR_NSEC3PARAM RRType = 51 /* RFC 5155 */
 	RR_TLSA       RRType = 52 /* RFC 6698 */
+	RR_SMIMEA     RRType = 53 /* RFC 8162 */
 
 	RR_HIP RRType = 55 /* RFC 5205 */
 
@@ -140,6 +141,17 @@ const (
 	RR_TALINK RRType = 58
 	/** draft-barwood-dnsop-ds-publis */
 	RR_CDS RRType = 59
+	/** RFC 7344 */
+	RR_CDNSKEY RRType = 60
+	/** RFC 7929 */
+	RR_OPENPGPKEY RRType = 61
+	/** RFC 7477 */
+	RR_CSYNC RRType = 62
+	/** RFC 8976 */
+	RR_ZONEMD RRType = 63
+
+	RR_SVCB  RRType = 64 /* RFC 9460 */
+	RR_HTTPS RRType = 65 /* RFC 9460 */
 
 	RR_SPF RRType = 99 /* RFC 4408 */
 
@@ -232,11 +244,18 @@ var typeNameMap = map[RRType]string{
 	RR_NSEC3:      "nsec3",
 	RR_NSEC3PARAM: "nsec3param",
 	RR_TLSA:       "tlsa",
+	RR_SMIMEA:     "smimea",
 	RR_HIP:        "hip",
 	RR_NINFO:      "ninfo",
 	RR_RKEY:       "pkey",
 	RR_TALINK:     "talink",
 	RR_CDS:        "cds",
+	RR_CDNSKEY:    "cdnskey",
+	RR_OPENPGPKEY: "openpgpkey",
+	RR_CSYNC:      "csync",
+	RR_ZONEMD:     "zonemd",
+	RR_SVCB:       "svcb",
+	RR_HTTPS:      "https",
 	RR_SPF:        "spf",
 	RR_UINFO:      "uinfo",
 	RR_UID:        "uid",
@@ -319,6 +338,12 @@ func ClassFromString(s string) (RRClass, error) {
 	case "ANY":
 		return CLASS_ANY, nil
 	default:
+		//generic class name in rfc3597
+		if strings.HasPrefix(s, "CLASS") {
+			if cls, err := strconv.ParseUint(s[5:], 10, 16); err == nil {
+				return RRClass(cls), nil
+			}
+		}
 		return RRClass(0), ErrUnknownRRClass
 	}
 }
@@ -344,7 +369,7 @@ func (cls RRClass) String() string {
 	case CLASS_ANY:
 		return "ANY"
 	default:
-		return "unknownclass"
+		return fmt.Sprintf("CLASS%d", cls)
 	}
 }
 
@@ -359,14 +384,36 @@ func TypeFromWire(buf *util.InputBuffer) (RRType, error) {
 
 func TypeFromString(s string) (RRType, error) {
 	s = strings.ToLower(s)
+	rdataTypeLock.RLock()
 	for t, ts := range typeNameMap {
 		if ts == s {
+			rdataTypeLock.RUnlock()
 			return t, nil
 		}
 	}
+	rdataTypeLock.RUnlock()
+
+	//generic type name in rfc3597
+	if isGenericTypeName(s) {
+		if t, err := strconv.ParseUint(s[4:], 10, 16); err == nil {
+			return RRType(t), nil
+		}
+	}
 	return RRType(0), ErrUnknownRRType
 }
 
+func isGenericTypeName(s string) bool {
+	if len(s) <= 4 || strings.EqualFold(s[:4], "type") == false {
+		return false
+	}
+	for _, c := range s[4:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (t RRType) Rend(render *MsgRender) {
 	render.WriteUint16(uint16(t))
 }
@@ -376,9 +423,11 @@ func (t RRType) ToWire(buf *util.OutputBuffer) {
 }
 
 func (t RRType) String() string {
+	rdataTypeLock.RLock()
 	s := typeNameMap[t]
+	rdataTypeLock.RUnlock()
 	if s == "" {
-		return fmt.Sprintf("unknowntype:%d", t)
+		return fmt.Sprintf("TYPE%d", t)
 	} else {
 		return strings.ToUpper(s)
 	}
@@ -509,7 +558,26 @@ func (rrset *RRset) FromWire(buf *util.InputBuffer) error {
 	return nil
 }
 
+//rrset is rendered as a whole, if it exceeds the length limit
+//of render, nothing is left in render and render is marked
+//as truncated
 func (rrset *RRset) Rend(r *MsgRender) {
+	if rrset.rendWithinLimit(r) == false {
+		r.SetTrancated()
+	}
+}
+
+func (rrset *RRset) rendWithinLimit(r *MsgRender) bool {
+	pos := r.Len()
+	rrset.rend(r)
+	if r.exceedLenLimit() {
+		r.rollback(pos)
+		return false
+	}
+	return true
+}
+
+func (rrset *RRset) rend(r *MsgRender) {
 	if len(rrset.Rdatas) == 0 {
 		rrset.Name.Rend(r)
 		rrset.Type.Rend(r)
@@ -648,6 +716,46 @@ func (rrset *RRset) SortRdata() {
 	sort.Sort(RdataSlice(rrset.Rdatas))
 }
 
+//sort rdatas by their canonical form which is required by dnssec
+func (rrset *RRset) SortRdataCanonical() {
+	type rdataWire struct {
+		rdata Rdata
+		wire  []byte
+	}
+
+	sorted := make([]rdataWire, len(rrset.Rdatas))
+	for i, rdata := range rrset.Rdatas {
+		sorted[i] = rdataWire{rdata, CanonicalRdataToWire(rdata)}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return bytes.Compare(sorted[i].wire, sorted[j].wire) < 0 })
+	for i, rw := range sorted {
+		rrset.Rdatas[i] = rw.rdata
+	}
+}
+
+//write rrs in canonical form and order with duplicate rr removed
+func (rrset *RRset) CanonicalToWire(buf *util.OutputBuffer) {
+	canonicalRRsetToWire(&rrset.Name, rrset.Type, rrset.Class, rrset.Ttl, rrset.Rdatas, buf)
+}
+
+//rrsets sorted by owner name in canonical order, then class and type
+type RRsetSlice []*RRset
+
+func (rrsets RRsetSlice) Len() int      { return len(rrsets) }
+func (rrsets RRsetSlice) Swap(i, j int) { rrsets[i], rrsets[j] = rrsets[j], rrsets[i] }
+func (rrsets RRsetSlice) Less(i, j int) bool {
+	if order := rrsets[i].Name.Compare(&rrsets[j].Name, false).Order; order != 0 {
+		return order < 0
+	} else if rrsets[i].Class != rrsets[j].Class {
+		return rrsets[i].Class < rrsets[j].Class
+	}
+	return rrsets[i].Type < rrsets[j].Type
+}
+
+func SortRRsets(rrsets []*RRset) {
+	sort.Sort(RRsetSlice(rrsets))
+}
+
 func (rrset *RRset) Clone() *RRset {
 	rdataCount := len(rrset.Rdatas)
 	rdatas := make([]Rdata, rdataCount, rdataCount)
@@ -0,0 +1,695 @@
+package g53
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoRRSig         = errors.New("no valid rrsig covers the rrset")
+	ErrNSECProofFailed = errors.New("nsec records don't prove the denial")
+)
+
+//security status defined in rfc4035 section 4.3
+type SecurityStatus int
+
+const (
+	SECURITY_INDETERMINATE SecurityStatus = iota
+	SECURITY_SECURE
+	SECURITY_INSECURE
+	SECURITY_BOGUS
+)
+
+func (s SecurityStatus) String() string {
+	switch s {
+	case SECURITY_SECURE:
+		return "secure"
+	case SECURITY_INSECURE:
+		return "insecure"
+	case SECURITY_BOGUS:
+		return "bogus"
+	default:
+		return "indeterminate"
+	}
+}
+
+type ValidationResult struct {
+	Status SecurityStatus
+	Reason string
+}
+
+//fetcher sends the query to the authoritative servers of the name, ds
+//query should be answered by the parent zone
+type Fetcher interface {
+	Fetch(name *Name, typ RRType) (*Message, error)
+}
+
+//trust anchor is either ds or dnskey of the zone
+type TrustAnchor struct {
+	Zone    *Name
+	DS      []*DS
+	DNSKeys []*DNSKey
+}
+
+//result which isn't proved by any rrset, like bogus caused by missing
+//dnskey, is cached for this time
+const validatorBadCacheTTL RRTTL = 60
+
+//validated dnskeys of a zone, or the reason why the zone isn't secure.
+//expire is bound by the ttl of the rrsets which prove it, result caused
+//by fetch failure is transient and isn't cached
+type zoneSecurity struct {
+	zone      *Name
+	status    SecurityStatus
+	keys      []*DNSKey
+	reason    string
+	expire    time.Time
+	transient bool
+}
+
+func (z *zoneSecurity) limitTTL(now time.Time, ttl RRTTL) {
+	expire := now.Add(time.Duration(ttl) * time.Second)
+	if z.expire.IsZero() || expire.Before(z.expire) {
+		z.expire = expire
+	}
+}
+
+//name which isn't zone cut refers to the zone it belongs to, which is
+//cached no longer than the denial of its ds
+func (z *zoneSecurity) notZoneCut(now time.Time, denial []*RRset) *zoneSecurity {
+	parent := *z
+	for _, rrset := range denial {
+		parent.limitTTL(now, rrset.Ttl)
+	}
+	return &parent
+}
+
+//validator walks the chain of trust from trust anchors down to the signer
+//of the response, the result of each zone cut is cached. lock only protects
+//the cache and anchors, fetch isn't done with lock held
+type Validator struct {
+	fetcher Fetcher
+	now     func() time.Time
+
+	lock    sync.Mutex
+	anchors []*TrustAnchor
+	zones   map[string]*zoneSecurity
+}
+
+func NewValidator(fetcher Fetcher, anchors []*TrustAnchor) *Validator {
+	return &Validator{
+		fetcher: fetcher,
+		anchors: anchors,
+		now:     time.Now,
+		zones:   make(map[string]*zoneSecurity),
+	}
+}
+
+//validate the response of the question, cname chain in answer section is
+//followed and the rrsets which answer the question should all be secure.
+//cname synthesized from dname isn't signed, the dname is verified instead
+//denial of existence is checked for nxdomain and nodata response
+func (v *Validator) Validate(resp *Message) *ValidationResult {
+	if resp.Question == nil {
+		return &ValidationResult{SECURITY_INDETERMINATE, "response has no question"}
+	}
+
+	sname := &resp.Question.Name
+	qtype := resp.Question.Type
+	answers := resp.GetSection(AnswerSection)
+	result := &ValidationResult{Status: SECURITY_SECURE}
+	for i := 0; i <= len(answers); i++ {
+		if dname := findDName(answers, sname); dname != nil {
+			mergeResult(result, v.validateAnswer(resp, dname, &dname.Name))
+			prefix, _ := sname.Subtract(&dname.Name)
+			target, err := prefix.Concat(dname.Rdatas[0].(*DName).Target)
+			if err != nil {
+				return &ValidationResult{SECURITY_BOGUS, "dname substitution of " + sname.String(false) + ": " + err.Error()}
+			}
+
+			if cname := findRRset(answers, sname, RR_CNAME); cname != nil && cname.Rdatas[0].(*CName).Name.Equals(target) == false {
+				return &ValidationResult{SECURITY_BOGUS, "cname of " + sname.String(false) + " doesn't match dname " + dname.Name.String(false)}
+			} else if qtype == RR_CNAME {
+				return result
+			}
+			sname = target
+			continue
+		}
+
+		rrset := findRRset(answers, sname, qtype)
+		if rrset == nil && qtype != RR_CNAME {
+			rrset = findRRset(answers, sname, RR_CNAME)
+		}
+
+		if rrset == nil {
+			mergeResult(result, v.validateDenial(resp, sname, qtype))
+			return result
+		}
+
+		mergeResult(result, v.validateAnswer(resp, rrset, sname))
+		if rrset.Type != RR_CNAME || qtype == RR_CNAME {
+			return result
+		}
+		sname = rrset.Rdatas[0].(*CName).Name
+	}
+	return &ValidationResult{SECURITY_BOGUS, "cname loop in answer"}
+}
+
+//replace the trust anchors, for example with the keys of ManagedTrustAnchor
+//after it's updated, cached results are dropped
+func (v *Validator) SetTrustAnchors(anchors []*TrustAnchor) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.anchors = anchors
+	v.zones = make(map[string]*zoneSecurity)
+}
+
+func (v *Validator) cachedZone(key string, now time.Time) *zoneSecurity {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	zone, ok := v.zones[key]
+	if ok == false {
+		return nil
+	} else if now.Before(zone.expire) == false {
+		delete(v.zones, key)
+		return nil
+	}
+	return zone
+}
+
+func (v *Validator) cacheZone(key string, zone *zoneSecurity, now time.Time) {
+	if zone.transient {
+		return
+	}
+
+	if zone.expire.IsZero() {
+		zone.limitTTL(now, validatorBadCacheTTL)
+	}
+	v.lock.Lock()
+	v.zones[key] = zone
+	v.lock.Unlock()
+}
+
+//bogus overrides the others, and insecure overrides secure
+func mergeResult(result, other *ValidationResult) {
+	if other.Status == SECURITY_SECURE || result.Status == SECURITY_BOGUS {
+		return
+	}
+
+	if other.Status == SECURITY_BOGUS || result.Status == SECURITY_SECURE ||
+		(result.Status == SECURITY_INSECURE && other.Status == SECURITY_INDETERMINATE) {
+		*result = *other
+	}
+}
+
+func findRRset(section Section, name *Name, typ RRType) *RRset {
+	for _, rrset := range section {
+		if rrset.Type == typ && rrset.Name.Equals(name) && len(rrset.Rdatas) != 0 {
+			return rrset
+		}
+	}
+	return nil
+}
+
+//dname whose owner is an ancestor of name
+func findDName(section Section, name *Name) *RRset {
+	for _, rrset := range section {
+		if rrset.Type == RR_DNAME && len(rrset.Rdatas) != 0 &&
+			rrset.Name.Equals(name) == false && isInZone(name, &rrset.Name) {
+			return rrset
+		}
+	}
+	return nil
+}
+
+func rrsigsFor(section Section, name *Name, covered RRType) []*RRSig {
+	var sigs []*RRSig
+	for _, rrset := range section {
+		if rrset.Type != RR_RRSIG || rrset.Name.Equals(name) == false {
+			continue
+		}
+
+		for _, rdata := range rrset.Rdatas {
+			if sig, ok := rdata.(*RRSig); ok && sig.Covered == covered {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	return sigs
+}
+
+func (v *Validator) validateAnswer(resp *Message, rrset *RRset, sname *Name) *ValidationResult {
+	sigs := rrsigsFor(resp.GetSection(AnswerSection), &rrset.Name, rrset.Type)
+	zone, result := v.signerSecurity(sigs, sname)
+	if result != nil {
+		return result
+	}
+
+	sig, err := v.verifyRRset(rrset, sigs, zone)
+	if err != nil {
+		return &ValidationResult{SECURITY_BOGUS, fmt.Sprintf("%s %s: %s", rrset.Name.String(false), rrset.Type.String(), err.Error())}
+	}
+
+	//answer expanded from wildcard needs proof that sname doesn't exist
+	if uint(sig.Labels) < sname.LabelCount()-1 {
+		nsecs, nsec3s, err := v.verifiedDenial(resp, zone)
+		if err != nil {
+			return &ValidationResult{SECURITY_BOGUS, err.Error()}
+		}
+
+		if len(nsec3s) != 0 {
+			_, err = ProveWildcardAnswer(sname, sig.Labels, nsec3s)
+		} else if nsecCovering(nsecs, sname) == nil {
+			err = ErrNSECProofFailed
+		}
+		if err == ErrNSEC3TooManyIters {
+			return nsec3TooManyIters(sname)
+		} else if err != nil {
+			return &ValidationResult{SECURITY_BOGUS, "wildcard answer for " + sname.String(false) + ": " + err.Error()}
+		}
+	}
+	return &ValidationResult{Status: SECURITY_SECURE}
+}
+
+func (v *Validator) validateDenial(resp *Message, sname *Name, qtype RRType) *ValidationResult {
+	auth := resp.GetSection(AuthSection)
+	var sigs []*RRSig
+	for _, rrset := range auth {
+		if rrset.Type == RR_SOA || rrset.Type == RR_NSEC || rrset.Type == RR_NSEC3 {
+			sigs = append(sigs, rrsigsFor(auth, &rrset.Name, rrset.Type)...)
+		}
+	}
+
+	zone, result := v.signerSecurity(sigs, sname)
+	if result != nil {
+		return result
+	}
+
+	nsecs, nsec3s, err := v.verifiedDenial(resp, zone)
+	if err != nil {
+		return &ValidationResult{SECURITY_BOGUS, err.Error()}
+	}
+
+	var proof *NSEC3Proof
+	if resp.Header.Rcode == R_NXDOMAIN {
+		if len(nsec3s) != 0 {
+			_, err = ProveNXDomain(sname, nsec3s)
+		} else {
+			err = nsecProveNXDomain(sname, nsecs)
+		}
+	} else if len(nsec3s) != 0 {
+		proof, err = ProveNoData(sname, qtype, nsec3s)
+	} else {
+		err = nsecProveNoData(sname, qtype, nsecs)
+	}
+
+	if err == ErrNSEC3TooManyIters {
+		return nsec3TooManyIters(sname)
+	} else if err != nil {
+		return &ValidationResult{SECURITY_BOGUS, "denial of " + sname.String(false) + ": " + err.Error()}
+	} else if proof != nil && proof.Type == NSEC3_PROOF_OPTOUT {
+		return &ValidationResult{SECURITY_INSECURE, "ds of " + sname.String(false) + " is covered by opt-out nsec3"}
+	}
+	return &ValidationResult{Status: SECURITY_SECURE}
+}
+
+//nsec3 signature is verified, but the proof isn't checked if it needs
+//too many iterations, rfc9276 section 3.2
+func nsec3TooManyIters(name *Name) *ValidationResult {
+	return &ValidationResult{SECURITY_INSECURE, "denial of " + name.String(false) + ": " + ErrNSEC3TooManyIters.Error()}
+}
+
+//find the zone which signs the response, without rrsig the response is
+//only acceptable if name is in an insecure zone
+func (v *Validator) signerSecurity(sigs []*RRSig, name *Name) (*zoneSecurity, *ValidationResult) {
+	if len(sigs) == 0 {
+		zone := v.zoneSecurity(name)
+		if zone.status == SECURITY_SECURE {
+			return nil, &ValidationResult{SECURITY_BOGUS, "missing rrsig for " + name.String(false)}
+		}
+		return nil, &ValidationResult{zone.status, zone.reason}
+	}
+
+	signer := sigs[0].Signer
+	if isInZone(name, signer) == false {
+		return nil, &ValidationResult{SECURITY_BOGUS, "signer " + signer.String(false) + " isn't parent of " + name.String(false)}
+	}
+
+	zone := v.zoneSecurity(signer)
+	if zone.status != SECURITY_SECURE {
+		return nil, &ValidationResult{zone.status, zone.reason}
+	} else if zone.zone.Equals(signer) == false {
+		return nil, &ValidationResult{SECURITY_BOGUS, "signer " + signer.String(false) + " isn't a zone"}
+	}
+	return zone, nil
+}
+
+//soa, nsec and nsec3 in authority section should be signed by the zone
+func (v *Validator) verifiedDenial(resp *Message, zone *zoneSecurity) ([]*RRset, []*RRset, error) {
+	auth := resp.GetSection(AuthSection)
+	var nsecs, nsec3s []*RRset
+	for _, rrset := range auth {
+		if rrset.Type != RR_SOA && rrset.Type != RR_NSEC && rrset.Type != RR_NSEC3 {
+			continue
+		}
+
+		if _, err := v.verifyRRset(rrset, rrsigsFor(auth, &rrset.Name, rrset.Type), zone); err != nil {
+			return nil, nil, fmt.Errorf("%s %s: %s", rrset.Name.String(false), rrset.Type.String(), err.Error())
+		}
+
+		if rrset.Type == RR_NSEC {
+			nsecs = append(nsecs, rrset)
+		} else if rrset.Type == RR_NSEC3 {
+			nsec3s = append(nsec3s, rrset)
+		}
+	}
+
+	if len(nsecs) == 0 && len(nsec3s) == 0 {
+		return nil, nil, errors.New("missing nsec or nsec3 in authority section")
+	}
+	return nsecs, nsec3s, nil
+}
+
+//return the rrsig which is verified by one of the zone keys
+func (v *Validator) verifyRRset(rrset *RRset, sigs []*RRSig, zone *zoneSecurity) (*RRSig, error) {
+	return verifyRRsetWithKeys(rrset, sigs, zone.zone, zone.keys, v.now())
+}
+
+func verifyRRsetWithKeys(rrset *RRset, sigs []*RRSig, signer *Name, keys []*DNSKey, now time.Time) (*RRSig, error) {
+	err := ErrNoRRSig
+	for _, sig := range sigs {
+		if sig.Signer.Equals(signer) == false {
+			continue
+		}
+
+		for _, key := range keys {
+			if key.Algorithm != sig.Algorithm || key.KeyTag() != sig.Tag {
+				continue
+			}
+
+			if err = VerifyRRSigAt(rrset, sig, key, now); err == nil {
+				return sig, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+//walk from the closest trust anchor down to name, return the security
+//of the deepest zone cut found
+func (v *Validator) zoneSecurity(name *Name) *zoneSecurity {
+	var anchor *TrustAnchor
+	v.lock.Lock()
+	for _, a := range v.anchors {
+		if isInZone(name, a.Zone) && (anchor == nil || a.Zone.LabelCount() > anchor.Zone.LabelCount()) {
+			anchor = a
+		}
+	}
+	v.lock.Unlock()
+	if anchor == nil {
+		return &zoneSecurity{
+			zone:   name,
+			status: SECURITY_INDETERMINATE,
+			reason: "no trust anchor for " + name.String(false),
+		}
+	}
+
+	now := v.now()
+	current := v.cachedZone(nameKey(anchor.Zone), now)
+	if current == nil {
+		current = v.zoneKeys(anchor.Zone, anchor.DS, anchor.DNSKeys)
+		v.cacheZone(nameKey(anchor.Zone), current, now)
+	}
+
+	count := name.LabelCount() - anchor.Zone.LabelCount()
+	for i := int(count) - 1; i >= 0 && current.status == SECURITY_SECURE; i-- {
+		child, _ := name.StripLeft(uint(i))
+		key := nameKey(child)
+		if cached := v.cachedZone(key, now); cached != nil {
+			current = cached
+			continue
+		}
+
+		current = v.delegation(current, child)
+		v.cacheZone(key, current, now)
+	}
+	return current
+}
+
+//fetch ds of child from parent zone, return parent if child isn't a zone
+//cut. ds absence proved by nsec or nsec3 makes the child insecure
+func (v *Validator) delegation(parent *zoneSecurity, child *Name) *zoneSecurity {
+	bogus := func(reason string) *zoneSecurity {
+		return &zoneSecurity{zone: child, status: SECURITY_BOGUS, reason: reason}
+	}
+
+	resp, err := v.fetcher.Fetch(child, RR_DS)
+	if err != nil {
+		return &zoneSecurity{
+			zone:      child,
+			status:    SECURITY_INDETERMINATE,
+			reason:    "fetch ds of " + child.String(false) + " failed: " + err.Error(),
+			transient: true,
+		}
+	}
+
+	if dsRRset := findRRset(resp.GetSection(AnswerSection), child, RR_DS); dsRRset != nil {
+		sigs := rrsigsFor(resp.GetSection(AnswerSection), child, RR_DS)
+		if _, err := v.verifyRRset(dsRRset, sigs, parent); err != nil {
+			return bogus("ds of " + child.String(false) + ": " + err.Error())
+		}
+
+		var dses []*DS
+		for _, rdata := range dsRRset.Rdatas {
+			dses = append(dses, rdata.(*DS))
+		}
+		zone := v.zoneKeys(child, dses, nil)
+		if zone.transient == false {
+			zone.limitTTL(v.now(), dsRRset.Ttl)
+		}
+		return zone
+	}
+
+	nsecs, nsec3s, err := v.verifiedDenial(resp, parent)
+	if err != nil {
+		return bogus("denial of ds of " + child.String(false) + ": " + err.Error())
+	}
+
+	now := v.now()
+	denial := append(append([]*RRset(nil), nsecs...), nsec3s...)
+	insecure := &zoneSecurity{
+		zone:   child,
+		status: SECURITY_INSECURE,
+		reason: "no ds for delegation " + child.String(false),
+	}
+	for _, rrset := range denial {
+		insecure.limitTTL(now, rrset.Ttl)
+	}
+	if len(nsec3s) != 0 {
+		if _, err := ProveInsecureDelegation(child, nsec3s); err == nil {
+			return insecure
+		} else if err == ErrNSEC3TooManyIters {
+			insecure.reason = "denial of ds of " + child.String(false) + ": " + err.Error()
+			return insecure
+		} else if _, err := ProveNoData(child, RR_DS, nsec3s); err == nil {
+			return parent.notZoneCut(now, denial)
+		} else if _, err := ProveNXDomain(child, nsec3s); err == nil {
+			return parent.notZoneCut(now, denial)
+		}
+		return bogus("denial of ds of " + child.String(false) + ": " + ErrNSEC3ProofFailed.Error())
+	}
+
+	if nsec := nsecMatching(nsecs, child); nsec != nil {
+		if hasType(nsec.Types, RR_DS) || hasType(nsec.Types, RR_SOA) {
+			return bogus("denial of ds of " + child.String(false) + ": " + ErrNSECProofFailed.Error())
+		} else if hasType(nsec.Types, RR_NS) {
+			return insecure
+		}
+		return parent.notZoneCut(now, denial)
+	} else if nsecCovering(nsecs, child) != nil {
+		return parent.notZoneCut(now, denial)
+	}
+	return bogus("denial of ds of " + child.String(false) + ": " + ErrNSECProofFailed.Error())
+}
+
+//get the dnskeys of the zone, one of them should match the ds or the
+//trusted dnskeys and sign the dnskey rrset, rfc4035 section 5.2. zone
+//whose ds all have unsupported algorithm or digest type is insecure
+func (v *Validator) zoneKeys(zone *Name, dses []*DS, trusted []*DNSKey) *zoneSecurity {
+	bogus := func(reason string) *zoneSecurity {
+		return &zoneSecurity{zone: zone, status: SECURITY_BOGUS, reason: reason}
+	}
+
+	var supported []*DS
+	for _, ds := range dses {
+		if _, err := algorithmHash(ds.Algorithm); err != nil {
+			continue
+		}
+
+		switch ds.DigestType {
+		case DS_DIGEST_SHA1, DS_DIGEST_SHA256, DS_DIGEST_SHA384:
+			supported = append(supported, ds)
+		}
+	}
+	if len(supported) == 0 && len(trusted) == 0 {
+		return &zoneSecurity{
+			zone:   zone,
+			status: SECURITY_INSECURE,
+			reason: "no supported ds for " + zone.String(false),
+		}
+	}
+
+	resp, err := v.fetcher.Fetch(zone, RR_DNSKEY)
+	if err != nil {
+		return &zoneSecurity{
+			zone:      zone,
+			status:    SECURITY_INDETERMINATE,
+			reason:    "fetch dnskey of " + zone.String(false) + " failed: " + err.Error(),
+			transient: true,
+		}
+	}
+
+	answers := resp.GetSection(AnswerSection)
+	rrset := findRRset(answers, zone, RR_DNSKEY)
+	if rrset == nil {
+		return bogus("missing dnskey for " + zone.String(false))
+	}
+
+	//revoked key is only used to sign dnskey rrset by itself, rfc5011
+	var keys, entries []*DNSKey
+	for _, rdata := range rrset.Rdatas {
+		key := rdata.(*DNSKey)
+		if key.IsZoneKey() == false || key.IsRevoked() {
+			continue
+		}
+		keys = append(keys, key)
+
+		for _, ds := range supported {
+			if ds.Match(zone, key) {
+				entries = append(entries, key)
+				break
+			}
+		}
+		for _, t := range trusted {
+			if t.Compare(key) == 0 {
+				entries = append(entries, key)
+				break
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return bogus("no dnskey of " + zone.String(false) + " matches ds or trust anchor")
+	}
+
+	if _, err := verifyRRsetWithKeys(rrset, rrsigsFor(answers, zone, RR_DNSKEY), zone, entries, v.now()); err != nil {
+		return bogus("dnskey of " + zone.String(false) + ": " + err.Error())
+	}
+
+	secure := &zoneSecurity{
+		zone:   zone,
+		status: SECURITY_SECURE,
+		keys:   keys,
+	}
+	secure.limitTTL(v.now(), rrset.Ttl)
+	return secure
+}
+
+func nsecMatching(nsecs []*RRset, name *Name) *NSEC {
+	for _, rrset := range nsecs {
+		if rrset.Name.Equals(name) {
+			return rrset.Rdatas[0].(*NSEC)
+		}
+	}
+	return nil
+}
+
+//nsec at delegation point comes from parent zone, it only proves the
+//absence of ds, rfc6840 section 4.1
+func isDelegationNSEC(nsec *NSEC) bool {
+	return hasType(nsec.Types, RR_NS) && hasType(nsec.Types, RR_SOA) == false
+}
+
+//the last nsec in the zone points back to the apex. nsec at delegation
+//or dname doesn't cover the names under it, since they don't belong to
+//the zone, rfc4035 section 5.4
+func nsecCovering(nsecs []*RRset, name *Name) *RRset {
+	for _, rrset := range nsecs {
+		nsec := rrset.Rdatas[0].(*NSEC)
+		next := nsec.NextDomain
+		if rrset.Name.Compare(name, false).Order >= 0 {
+			continue
+		}
+
+		if name.Compare(&rrset.Name, false).Relation == SUBDOMAIN &&
+			(isDelegationNSEC(nsec) || hasType(nsec.Types, RR_DNAME)) {
+			continue
+		}
+
+		if name.Compare(next, false).Order < 0 || rrset.Name.Compare(next, false).Order >= 0 {
+			return rrset
+		}
+	}
+	return nil
+}
+
+//closest encloser is the longest common ancestor of name with the owner
+//or next name of the covering nsec
+func nsecClosestEncloser(covering *RRset, name *Name) *Name {
+	common := covering.Name.Compare(name, false).CommonLabelCount
+	next := covering.Rdatas[0].(*NSEC).NextDomain
+	if c := next.Compare(name, false).CommonLabelCount; c > common {
+		common = c
+	}
+
+	encloser, _ := name.StripLeft(name.LabelCount() - uint(common))
+	return encloser
+}
+
+//name error needs nsec covering name and nsec covering the wildcard at
+//closest encloser, rfc4035 section 5.4
+func nsecProveNXDomain(name *Name, nsecs []*RRset) error {
+	covering := nsecCovering(nsecs, name)
+	if covering == nil {
+		return ErrNSECProofFailed
+	}
+
+	if nsecCovering(nsecs, wildcardOf(nsecClosestEncloser(covering, name))) == nil {
+		return ErrNSECProofFailed
+	}
+	return nil
+}
+
+//nodata is proved by nsec matching name without the type, nsec covering
+//an empty non-terminal name, or nsec matching the wildcard which would
+//expand to name without the type. ds nodata should come from parent zone
+//and the other types from child zone
+func nsecProveNoData(name *Name, typ RRType, nsecs []*RRset) error {
+	if nsec := nsecMatching(nsecs, name); nsec != nil {
+		if hasType(nsec.Types, typ) || hasType(nsec.Types, RR_CNAME) {
+			return ErrNSECProofFailed
+		} else if typ == RR_DS && hasType(nsec.Types, RR_SOA) {
+			return ErrNSECProofFailed
+		} else if typ != RR_DS && isDelegationNSEC(nsec) {
+			return ErrNSECProofFailed
+		}
+		return nil
+	}
+
+	covering := nsecCovering(nsecs, name)
+	if covering == nil {
+		return ErrNSECProofFailed
+	}
+
+	next := covering.Rdatas[0].(*NSEC).NextDomain
+	if next.Compare(name, false).Relation == SUBDOMAIN {
+		return nil
+	}
+
+	nsec := nsecMatching(nsecs, wildcardOf(nsecClosestEncloser(covering, name)))
+	if nsec == nil || hasType(nsec.Types, typ) || hasType(nsec.Types, RR_CNAME) || isDelegationNSEC(nsec) {
+		return ErrNSECProofFailed
+	}
+	return nil
+}
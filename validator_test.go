@@ -0,0 +1,448 @@
+package g53
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeZone struct {
+	origin   *Name
+	zskFlags uint16
+	ksk      *SigningKey
+	rrsets   []*RRset
+	sigs     []*RRset
+	denial   []*RRset
+}
+
+//build zone from records, signed zone has generated keys, denial chain
+//and rrsig for all the authoritative rrsets
+func newFakeZone(t *testing.T, origin string, records ...string) *fakeZone {
+	zone := &fakeZone{origin: NameFromStringUnsafe(origin)}
+	for _, record := range records {
+		zone.addRecord(t, record)
+	}
+	return zone
+}
+
+func (z *fakeZone) addRecord(t *testing.T, record string) {
+	rr := buildRRset(t, record)
+	for _, rrset := range z.rrsets {
+		if rrset.IsSameRRset(rr) {
+			rrset.Rdatas = append(rrset.Rdatas, rr.Rdatas...)
+			return
+		}
+	}
+	z.rrsets = append(z.rrsets, rr)
+}
+
+func (z *fakeZone) sign(t *testing.T, nsec3 *NSEC3Config) {
+	var keys []*SigningKey
+	zskFlags := z.zskFlags
+	if zskFlags == 0 {
+		zskFlags = DNSKEY_FLAG_ZONE
+	}
+	for _, flags := range []uint16{DNSKEY_FLAG_ZONE | DNSKEY_FLAG_SEP, zskFlags} {
+		privKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		key, err := NewSigningKey(z.origin, flags, ALGORITHM_ECDSAP256SHA256, privKey)
+		Assert(t, err == nil, "create key failed:%v", err)
+		keys = append(keys, key)
+	}
+	z.ksk = keys[0]
+	z.rrsets = append(z.rrsets, &RRset{
+		Name:   z.origin.Clone(),
+		Type:   RR_DNSKEY,
+		Class:  CLASS_IN,
+		Ttl:    3600,
+		Rdatas: []Rdata{keys[0].DNSKey(), keys[1].DNSKey()},
+	})
+
+	owners := make(map[string]*OwnerTypes)
+	var names []string
+	for _, rrset := range z.rrsets {
+		key := rrset.Name.String(false)
+		if _, ok := owners[key]; ok == false {
+			owners[key] = &OwnerTypes{Name: &rrset.Name}
+			names = append(names, key)
+		}
+		owners[key].Types = append(owners[key].Types, rrset.Type)
+	}
+	var ownerTypes []OwnerTypes
+	for _, name := range names {
+		ownerTypes = append(ownerTypes, *owners[name])
+	}
+
+	var chain *DenialChain
+	var err error
+	if nsec3 != nil {
+		chain, err = NewNSEC3Chain(z.origin, CLASS_IN, 300, *nsec3, ownerTypes)
+	} else {
+		chain, err = NewNSECChain(z.origin, CLASS_IN, 300, ownerTypes)
+	}
+	Assert(t, err == nil, "build denial chain failed:%v", err)
+	z.denial = chain.RRsets()
+
+	now := time.Now()
+	z.sigs, err = SignZone(z.origin, append(append([]*RRset(nil), z.rrsets...), z.denial...), keys, ZoneSignConfig{
+		Inception:  now.Add(-time.Hour),
+		Expiration: now.Add(24 * time.Hour),
+	})
+	Assert(t, err == nil, "sign zone failed:%v", err)
+}
+
+func (z *fakeZone) ds(t *testing.T) string {
+	ds, err := NewDS(z.origin, z.ksk.DNSKey(), DS_DIGEST_SHA256)
+	Assert(t, err == nil, "generate ds failed:%v", err)
+	return z.origin.String(false) + " 3600 IN DS " + ds.String()
+}
+
+func (z *fakeZone) find(name *Name, typ RRType) (*RRset, *RRset) {
+	for _, rrset := range append(append([]*RRset(nil), z.rrsets...), z.denial...) {
+		if rrset.Type == typ && rrset.Name.Equals(name) {
+			for _, sig := range z.sigs {
+				if sig.Name.Equals(name) && sig.Rdatas[0].(*RRSig).Covered == typ {
+					return rrset, sig
+				}
+			}
+			return rrset, nil
+		}
+	}
+	return nil, nil
+}
+
+func (z *fakeZone) exists(name *Name) bool {
+	for _, rrset := range z.rrsets {
+		if isInZone(&rrset.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeFetcher struct {
+	zones   []*fakeZone
+	err     error
+	fetched int
+}
+
+//ds is answered by parent zone and all the denial records are returned
+//with negative answer
+func (f *fakeFetcher) Fetch(name *Name, typ RRType) (*Message, error) {
+	f.fetched += 1
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var zone *fakeZone
+	for _, z := range f.zones {
+		if isInZone(name, z.origin) && (typ != RR_DS || name.Equals(z.origin) == false) &&
+			(zone == nil || z.origin.LabelCount() > zone.origin.LabelCount()) {
+			zone = z
+		}
+	}
+
+	resp := NewRequestBuilder(name, typ).SetHeaderFlag(FLAG_QR, true).Done()
+	f.answer(resp, zone, name, typ)
+	return resp, nil
+}
+
+func (f *fakeFetcher) answer(resp *Message, zone *fakeZone, name *Name, typ RRType) {
+	add := func(st SectionType, rrsets ...*RRset) {
+		for _, rrset := range rrsets {
+			if rrset != nil {
+				NewMsgBuilder(resp).AddRRset(st, rrset.Clone())
+			}
+		}
+	}
+	addAuth := func(rrset, sig *RRset) {
+		add(AuthSection, rrset, sig)
+	}
+	addDenial := func() {
+		addAuth(zone.find(zone.origin, RR_SOA))
+		for _, rrset := range zone.denial {
+			addAuth(zone.find(&rrset.Name, rrset.Type))
+		}
+	}
+
+	if rrset, sig := zone.find(name, typ); rrset != nil {
+		add(AnswerSection, rrset, sig)
+		return
+	}
+
+	follow := func(target *Name) {
+		next, _ := f.Fetch(target, typ)
+		add(AnswerSection, next.GetSection(AnswerSection)...)
+		add(AuthSection, next.GetSection(AuthSection)...)
+		resp.Header.Rcode = next.Header.Rcode
+	}
+
+	if rrset, sig := zone.find(name, RR_CNAME); rrset != nil {
+		add(AnswerSection, rrset, sig)
+		follow(rrset.Rdatas[0].(*CName).Name)
+		return
+	}
+
+	//cname synthesized from dname is unsigned
+	for i := uint(1); i <= name.LabelCount()-zone.origin.LabelCount(); i++ {
+		owner, _ := name.StripLeft(i)
+		if rrset, sig := zone.find(owner, RR_DNAME); rrset != nil {
+			add(AnswerSection, rrset, sig)
+			prefix, _ := name.Subtract(owner)
+			target, _ := prefix.Concat(rrset.Rdatas[0].(*DName).Target)
+			add(AnswerSection, &RRset{
+				Name:   name.Clone(),
+				Type:   RR_CNAME,
+				Class:  CLASS_IN,
+				Ttl:    rrset.Ttl,
+				Rdatas: []Rdata{&CName{Name: target}},
+			})
+			if typ != RR_CNAME {
+				follow(target)
+			}
+			return
+		}
+	}
+
+	if zone.exists(name) {
+		addDenial()
+		return
+	}
+
+	for i := uint(1); i < name.LabelCount()-zone.origin.LabelCount()+1; i++ {
+		encloser, _ := name.StripLeft(i)
+		if zone.exists(encloser) == false {
+			continue
+		}
+
+		if rrset, sig := zone.find(wildcardOf(encloser), typ); rrset != nil {
+			rrset, sig = rrset.Clone(), sig.Clone()
+			rrset.Name = name.Clone()
+			sig.Name = name.Clone()
+			add(AnswerSection, rrset, sig)
+			for _, rrset := range zone.denial {
+				addAuth(zone.find(&rrset.Name, rrset.Type))
+			}
+		} else if zone.exists(wildcardOf(encloser)) {
+			addDenial()
+		} else {
+			resp.Header.Rcode = R_NXDOMAIN
+			addDenial()
+		}
+		return
+	}
+}
+
+func buildHierarchy(t *testing.T) (*fakeFetcher, *TrustAnchor) {
+	soa := func(origin string) string {
+		return origin + " 3600 IN SOA ns.example.net. admin.example.net. 1 3600 900 86400 300"
+	}
+
+	example := newFakeZone(t, "example.com.",
+		soa("example.com."),
+		"example.com. 3600 IN NS ns.example.com.",
+		"ns.example.com. 3600 IN A 192.0.2.1",
+		"www.example.com. 3600 IN A 192.0.2.2",
+		"alias.example.com. 3600 IN CNAME www.example.com.",
+		"*.wild.example.com. 3600 IN A 192.0.2.3",
+		"a.b.example.com. 3600 IN A 192.0.2.4",
+	)
+	example.sign(t, nil)
+
+	bogus := newFakeZone(t, "bogus.com.",
+		soa("bogus.com."),
+		"www.bogus.com. 3600 IN A 192.0.2.5",
+	)
+	bogus.sign(t, nil)
+	wrong := newFakeZone(t, "bogus.com.", soa("bogus.com."))
+	wrong.sign(t, nil)
+
+	unsigned := newFakeZone(t, "unsigned.com.",
+		soa("unsigned.com."),
+		"www.unsigned.com. 3600 IN A 192.0.2.6",
+	)
+
+	//nsec3 with too many iterations makes denial insecure
+	iter := newFakeZone(t, "iter.com.",
+		soa("iter.com."),
+		"www.iter.com. 3600 IN A 192.0.2.7",
+	)
+	iter.sign(t, &NSEC3Config{Iterations: NSEC3_MAX_ITERATIONS + 1})
+
+	//zsk is revoked, nothing signed by it is trusted
+	revoked := newFakeZone(t, "revoked.com.",
+		soa("revoked.com."),
+		"www.revoked.com. 3600 IN A 192.0.2.8",
+	)
+	revoked.zskFlags = DNSKEY_FLAG_ZONE | DNSKEY_FLAG_REVOKE
+	revoked.sign(t, nil)
+
+	dname := newFakeZone(t, "dname.com.",
+		soa("dname.com."),
+		"dname.com. 3600 IN DNAME example.com.",
+	)
+	dname.sign(t, nil)
+
+	com := newFakeZone(t, "com.",
+		soa("com."),
+		"example.com. 3600 IN NS ns.example.com.",
+		example.ds(t),
+		"bogus.com. 3600 IN NS ns.bogus.com.",
+		wrong.ds(t),
+		"unsigned.com. 3600 IN NS ns.unsigned.com.",
+		"iter.com. 3600 IN NS ns.iter.com.",
+		iter.ds(t),
+		"revoked.com. 3600 IN NS ns.revoked.com.",
+		revoked.ds(t),
+		"dname.com. 3600 IN NS ns.dname.com.",
+		dname.ds(t),
+	)
+	com.sign(t, &NSEC3Config{Iterations: 0, Salt: []byte{0xab, 0xcd}, OptOut: true})
+
+	root := newFakeZone(t, ".",
+		soa("."),
+		"com. 3600 IN NS a.gtld-servers.net.",
+		com.ds(t),
+	)
+	root.sign(t, nil)
+
+	ds, _ := NewDS(root.origin, root.ksk.DNSKey(), DS_DIGEST_SHA256)
+	return &fakeFetcher{zones: []*fakeZone{root, com, example, bogus, unsigned, iter, revoked, dname}}, &TrustAnchor{
+		Zone: root.origin,
+		DS:   []*DS{ds},
+	}
+}
+
+func TestValidator(t *testing.T) {
+	root := NameFromStringUnsafe(".")
+	fetcher, anchor := buildHierarchy(t)
+	validator := NewValidator(fetcher, []*TrustAnchor{anchor})
+
+	cases := []struct {
+		name   string
+		typ    RRType
+		rcode  Rcode
+		status SecurityStatus
+	}{
+		{"www.example.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"alias.example.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"foo.wild.example.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"www.example.com.", RR_MX, R_NOERROR, SECURITY_SECURE},
+		{"b.example.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"foo.wild.example.com.", RR_MX, R_NOERROR, SECURITY_SECURE},
+		{"nope.example.com.", RR_A, R_NXDOMAIN, SECURITY_SECURE},
+		{"nope.com.", RR_A, R_NXDOMAIN, SECURITY_SECURE},
+		{"example.com.", RR_DS, R_NOERROR, SECURITY_SECURE},
+		{"com.", RR_DNSKEY, R_NOERROR, SECURITY_SECURE},
+		{"www.unsigned.com.", RR_A, R_NOERROR, SECURITY_INSECURE},
+		{"nope.unsigned.com.", RR_A, R_NXDOMAIN, SECURITY_INSECURE},
+		{"unsigned.com.", RR_DS, R_NOERROR, SECURITY_INSECURE},
+		{"www.bogus.com.", RR_A, R_NOERROR, SECURITY_BOGUS},
+		{"www.iter.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"nope.iter.com.", RR_A, R_NXDOMAIN, SECURITY_INSECURE},
+		{"www.iter.com.", RR_MX, R_NOERROR, SECURITY_INSECURE},
+		{"www.revoked.com.", RR_A, R_NOERROR, SECURITY_BOGUS},
+		{"www.dname.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"alias.dname.com.", RR_A, R_NOERROR, SECURITY_SECURE},
+		{"www.dname.com.", RR_CNAME, R_NOERROR, SECURITY_SECURE},
+		{"nope.dname.com.", RR_A, R_NXDOMAIN, SECURITY_SECURE},
+	}
+	for _, c := range cases {
+		resp, _ := fetcher.Fetch(NameFromStringUnsafe(c.name), c.typ)
+		Equal(t, resp.Header.Rcode, c.rcode)
+		result := validator.Validate(resp)
+		Assert(t, result.Status == c.status, "%s %s should be %s but get %s:%s",
+			c.name, c.typ.String(), c.status.String(), result.Status.String(), result.Reason)
+	}
+
+	//tampered answer
+	resp, _ := fetcher.Fetch(NameFromStringUnsafe("www.example.com."), RR_A)
+	resp.GetSection(AnswerSection)[0].Rdatas[0] = buildRRset(t, "www.example.com. 3600 IN A 192.0.2.100").Rdatas[0]
+	result := validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_BOGUS)
+
+	//stripped rrsig
+	resp, _ = fetcher.Fetch(NameFromStringUnsafe("www.example.com."), RR_A)
+	resp = NewMsgBuilder(resp).FilterRRset(AnswerSection, func(rrset *RRset) bool {
+		return rrset.Type != RR_RRSIG
+	}).Done()
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_BOGUS)
+
+	//synthesized cname doesn't match dname
+	resp, _ = fetcher.Fetch(NameFromStringUnsafe("www.dname.com."), RR_A)
+	resp.GetSection(AnswerSection)[1].Rdatas[0] = &CName{Name: NameFromStringUnsafe("www.bogus.com.")}
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_BOGUS)
+
+	//nxdomain without denial proof
+	resp, _ = fetcher.Fetch(NameFromStringUnsafe("nope.example.com."), RR_A)
+	resp = NewMsgBuilder(resp).FilterRRset(AuthSection, func(rrset *RRset) bool {
+		return rrset.Type == RR_SOA || (rrset.Type == RR_RRSIG && rrset.Rdatas[0].(*RRSig).Covered == RR_SOA)
+	}).Done()
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_BOGUS)
+
+	//nodata response for existing data
+	resp, _ = fetcher.Fetch(NameFromStringUnsafe("www.example.com."), RR_MX)
+	resp.Question.Type = RR_A
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_BOGUS)
+
+	//no trust anchor for the zone
+	validator = NewValidator(fetcher, []*TrustAnchor{&TrustAnchor{Zone: NameFromStringUnsafe("org.")}})
+	resp, _ = fetcher.Fetch(NameFromStringUnsafe("www.example.com."), RR_A)
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_INDETERMINATE)
+
+	//dnskey as trust anchor and fetch failure
+	dnskeys, _ := fetcher.zones[0].find(root, RR_DNSKEY)
+	validator = NewValidator(fetcher, []*TrustAnchor{&TrustAnchor{Zone: root, DNSKeys: []*DNSKey{dnskeys.Rdatas[0].(*DNSKey)}}})
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_SECURE)
+	validator = NewValidator(fetcher, []*TrustAnchor{anchor})
+	fetcher.err = errors.New("timeout")
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_INDETERMINATE)
+
+	//fetch failure isn't cached
+	fetcher.err = nil
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_SECURE)
+
+	//cached result is used until the ttl of dnskey and ds expires
+	fetched := fetcher.fetched
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_SECURE)
+	Equal(t, fetcher.fetched, fetched)
+	validator.now = func() time.Time { return time.Now().Add(3601 * time.Second) }
+	result = validator.Validate(resp)
+	Equal(t, result.Status, SECURITY_SECURE)
+	Assert(t, fetcher.fetched > fetched, "expired cache should be refetched")
+}
+
+func TestNSECProofAtZoneCut(t *testing.T) {
+	nsecs := []*RRset{
+		buildRRset(t, "example.com. 3600 IN NSEC d.example.com. NS SOA RRSIG NSEC DNSKEY"),
+		buildRRset(t, "d.example.com. 3600 IN NSEC sub.example.com. DNAME RRSIG NSEC"),
+		buildRRset(t, "sub.example.com. 3600 IN NSEC z.example.com. NS RRSIG NSEC"),
+		buildRRset(t, "z.example.com. 3600 IN NSEC example.com. A RRSIG NSEC"),
+	}
+
+	//names under delegation or dname aren't covered by parent nsec
+	Equal(t, nsecProveNXDomain(NameFromStringUnsafe("www.sub.example.com."), nsecs), ErrNSECProofFailed)
+	Equal(t, nsecProveNXDomain(NameFromStringUnsafe("x.d.example.com."), nsecs), ErrNSECProofFailed)
+	Equal(t, nsecProveNoData(NameFromStringUnsafe("www.sub.example.com."), RR_A, nsecs), ErrNSECProofFailed)
+	Equal(t, nsecProveNXDomain(NameFromStringUnsafe("e.example.com."), nsecs), nil)
+
+	//parent nsec only proves the absence of ds
+	Equal(t, nsecProveNoData(NameFromStringUnsafe("sub.example.com."), RR_A, nsecs), ErrNSECProofFailed)
+	Equal(t, nsecProveNoData(NameFromStringUnsafe("sub.example.com."), RR_DS, nsecs), nil)
+
+	//child apex nsec doesn't prove the absence of ds
+	apex := []*RRset{buildRRset(t, "sub.example.com. 3600 IN NSEC www.sub.example.com. NS SOA RRSIG NSEC DNSKEY")}
+	Equal(t, nsecProveNoData(NameFromStringUnsafe("sub.example.com."), RR_DS, apex), ErrNSECProofFailed)
+	Equal(t, nsecProveNoData(NameFromStringUnsafe("sub.example.com."), RR_A, apex), nil)
+}
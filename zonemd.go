@@ -0,0 +1,205 @@
+package g53
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"sort"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+var (
+	ErrZoneMDNotFound       = errors.New("no zonemd at zone apex")
+	ErrZoneSOANotFound      = errors.New("no soa at zone apex")
+	ErrUnsupportedZoneMD    = errors.New("zonemd scheme or hash algorithm isn't supported")
+	ErrZoneMDSerialMismatch = errors.New("zonemd serial doesn't match soa serial")
+	ErrZoneMDDigestMismatch = errors.New("zonemd digest doesn't match zone data")
+)
+
+type zoneRRset struct {
+	name   *Name
+	typ    RRType
+	cls    RRClass
+	ttl    RRTTL
+	rdatas []Rdata
+}
+
+//compute zone digest with the simple scheme in rfc8976, rrsets out of the
+//zone, the apex zonemd and the rrsig covering it are excluded, rrsets with
+//same name type and class are merged and duplicate rr is removed
+func ZoneDigest(origin *Name, rrsets []*RRset, hashAlg uint8) ([]byte, error) {
+	h, err := newZoneMDHash(hashAlg)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := util.NewOutputBuffer(1024)
+	for _, rrset := range collectZoneRRsets(origin, rrsets) {
+		buf.Clear()
+		canonicalRRsetToWire(rrset.name, rrset.typ, rrset.cls, rrset.ttl, rrset.rdatas, buf)
+		h.Write(buf.Data())
+	}
+	return h.Sum(nil), nil
+}
+
+//create zonemd with the serial of the apex soa
+func NewZoneMD(origin *Name, rrsets []*RRset, hashAlg uint8) (*ZoneMD, error) {
+	soa := findApexRdatas(origin, rrsets, RR_SOA)
+	if len(soa) == 0 {
+		return nil, ErrZoneSOANotFound
+	}
+
+	digest, err := ZoneDigest(origin, rrsets, hashAlg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZoneMD{
+		Serial: soa[0].(*SOA).Serial,
+		Scheme: ZONEMD_SCHEME_SIMPLE,
+		Hash:   hashAlg,
+		Digest: digest,
+	}, nil
+}
+
+//verify the zone against the apex zonemd as rfc8976 section 4, the zone
+//is valid if any supported zonemd matches. zonemd with same scheme and
+//hash algorithm is ignored
+func VerifyZoneMD(origin *Name, rrsets []*RRset) error {
+	soa := findApexRdatas(origin, rrsets, RR_SOA)
+	if len(soa) == 0 {
+		return ErrZoneSOANotFound
+	}
+	serial := soa[0].(*SOA).Serial
+
+	zonemds := findApexRdatas(origin, rrsets, RR_ZONEMD)
+	if len(zonemds) == 0 {
+		return ErrZoneMDNotFound
+	}
+
+	err := ErrUnsupportedZoneMD
+	digests := make(map[uint8][]byte)
+	for _, rdata := range zonemds {
+		zonemd := rdata.(*ZoneMD)
+		if zonemd.Scheme != ZONEMD_SCHEME_SIMPLE || isDuplicateZoneMD(zonemd, zonemds) {
+			continue
+		}
+
+		if _, e := newZoneMDHash(zonemd.Hash); e != nil {
+			continue
+		}
+
+		if zonemd.Serial != serial {
+			if err == ErrUnsupportedZoneMD {
+				err = ErrZoneMDSerialMismatch
+			}
+			continue
+		}
+
+		digest, ok := digests[zonemd.Hash]
+		if ok == false {
+			digest, _ = ZoneDigest(origin, rrsets, zonemd.Hash)
+			digests[zonemd.Hash] = digest
+		}
+
+		if bytes.Equal(digest, zonemd.Digest) {
+			return nil
+		}
+		err = ErrZoneMDDigestMismatch
+	}
+	return err
+}
+
+func newZoneMDHash(hashAlg uint8) (hash.Hash, error) {
+	switch hashAlg {
+	case ZONEMD_HASH_SHA384:
+		return sha512.New384(), nil
+	case ZONEMD_HASH_SHA512:
+		return sha512.New(), nil
+	default:
+		return nil, ErrUnsupportedZoneMD
+	}
+}
+
+func isDuplicateZoneMD(zonemd *ZoneMD, zonemds []Rdata) bool {
+	for _, rdata := range zonemds {
+		other := rdata.(*ZoneMD)
+		if other != zonemd && other.Scheme == zonemd.Scheme && other.Hash == zonemd.Hash {
+			return true
+		}
+	}
+	return false
+}
+
+func findApexRdatas(origin *Name, rrsets []*RRset, typ RRType) []Rdata {
+	var rdatas []Rdata
+	for _, rrset := range rrsets {
+		if rrset.Type == typ && rrset.Name.Equals(origin) {
+			rdatas = append(rdatas, rrset.Rdatas...)
+		}
+	}
+	return rdatas
+}
+
+func isInZone(name, origin *Name) bool {
+	relation := name.Compare(origin, false).Relation
+	return relation == EQUAL || relation == SUBDOMAIN
+}
+
+func collectZoneRRsets(origin *Name, rrsets []*RRset) []*zoneRRset {
+	merged := make(map[string]*zoneRRset)
+	for _, rrset := range rrsets {
+		if isInZone(&rrset.Name, origin) == false {
+			continue
+		}
+
+		rdatas := rrset.Rdatas
+		if rrset.Name.Equals(origin) {
+			if rrset.Type == RR_ZONEMD {
+				continue
+			} else if rrset.Type == RR_RRSIG {
+				rdatas = nil
+				for _, rdata := range rrset.Rdatas {
+					if rdata.(*RRSig).Covered != RR_ZONEMD {
+						rdatas = append(rdatas, rdata)
+					}
+				}
+			}
+		}
+
+		if len(rdatas) == 0 {
+			continue
+		}
+
+		name := downcasedName(&rrset.Name)
+		key := string(name.raw) + rrset.Type.String() + rrset.Class.String()
+		if zrrset, ok := merged[key]; ok {
+			zrrset.rdatas = append(zrrset.rdatas, rdatas...)
+		} else {
+			merged[key] = &zoneRRset{
+				name:   name,
+				typ:    rrset.Type,
+				cls:    rrset.Class,
+				ttl:    rrset.Ttl,
+				rdatas: append([]Rdata(nil), rdatas...),
+			}
+		}
+	}
+
+	sorted := make([]*zoneRRset, 0, len(merged))
+	for _, zrrset := range merged {
+		sorted = append(sorted, zrrset)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		r1, r2 := sorted[i], sorted[j]
+		if order := r1.name.Compare(r2.name, false).Order; order != 0 {
+			return order < 0
+		} else if r1.cls != r2.cls {
+			return r1.cls < r2.cls
+		}
+		return r1.typ < r2.typ
+	})
+	return sorted
+}
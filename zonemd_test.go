@@ -0,0 +1,91 @@
+package g53
+
+import (
+	"testing"
+
+	"github.com/ben-han-cn/g53/util"
+)
+
+//simple example zone in rfc8976 appendix a.1
+func buildZoneMDExampleZone(t *testing.T) []*RRset {
+	var rrsets []*RRset
+	for _, rrs := range [][]string{
+		{"example. 86400 IN SOA ns1.example. admin.example. 2018031900 1800 900 604800 86400"},
+		{"example. 86400 IN NS ns1.example.", "example. 86400 IN NS ns2.example."},
+		{"example. 86400 IN ZONEMD 2018031900 1 1 c68090d90a7aed716bc459f9340e3d7c1370d4d24b7e2fc3a1ddc0b9a87153b9a9713b3c9ae5cc27777f98b8e730044c"},
+		{"ns1.example. 3600 IN A 203.0.113.63"},
+		{"ns2.example. 3600 IN AAAA 2001:db8::63"},
+	} {
+		rrset, err := RRsetFromStrings(rrs)
+		Assert(t, err == nil, "parse %v failed:%v", rrs, err)
+		rrsets = append(rrsets, rrset)
+	}
+	return rrsets
+}
+
+func TestZoneMDRdata(t *testing.T) {
+	digest := "c68090d90a7aed716bc459f9340e3d7c1370d4d24b7e2fc3a1ddc0b9a87153b9a9713b3c9ae5cc27777f98b8e730044c"
+	rdata, err := RdataFromString(RR_ZONEMD, "2018031900 1 1 "+digest[:48]+" "+digest[48:])
+	Assert(t, err == nil, "parse zonemd failed:%v", err)
+	Equal(t, rdata.String(), "2018031900 1 1 "+digest)
+
+	render := NewMsgRender()
+	rdata.Rend(render)
+	wire, _ := util.HexStrToBytes("7848b91c" + "01" + "01" + digest)
+	WireMatch(t, wire, render.Data())
+
+	rdata2, err := RdataFromWire(RR_ZONEMD, util.NewInputBuffer(append([]byte{0, byte(len(wire))}, wire...)))
+	Assert(t, err == nil, "parse zonemd from wire failed:%v", err)
+	Equal(t, rdata.Compare(rdata2), 0)
+
+	_, err = RdataFromString(RR_ZONEMD, "2018031900 1 1 c68090d90a7aed716bc459")
+	Equal(t, err, ErrZoneMDDigestTooShort)
+	_, err = RdataFromString(RR_ZONEMD, "2018031900 256 1 "+digest)
+	Assert(t, err != nil, "scheme is out of range")
+	wire, _ = util.HexStrToBytes("7848b91c0101c68090d90a7aed716bc459")
+	_, err = RdataFromWire(RR_ZONEMD, util.NewInputBuffer(append([]byte{0, byte(len(wire))}, wire...)))
+	Equal(t, err, ErrZoneMDDigestTooShort)
+}
+
+func TestZoneDigest(t *testing.T) {
+	origin := NameFromStringUnsafe("example.")
+	rrsets := buildZoneMDExampleZone(t)
+	Equal(t, VerifyZoneMD(origin, rrsets), nil)
+
+	zonemd, err := NewZoneMD(origin, rrsets, ZONEMD_HASH_SHA384)
+	Assert(t, err == nil, "create zonemd failed:%v", err)
+	Equal(t, zonemd.Compare(rrsets[2].Rdatas[0]), 0)
+
+	//owner and embedded name case, rr order and duplicate rr don't matter
+	mixed, _ := RRsetFromStrings([]string{"EXAMPLE. 86400 IN NS NS2.example.", "example. 86400 IN NS ns1.EXAMPLE."})
+	dup, _ := RRsetFromString("example. 86400 IN NS ns1.example.")
+	outOfZone, _ := RRsetFromString("example.org. 3600 IN A 192.0.2.1")
+	Equal(t, VerifyZoneMD(origin, []*RRset{rrsets[4], outOfZone, rrsets[3], rrsets[2], dup, mixed, rrsets[0]}), nil)
+
+	//rrsig of apex zonemd is excluded but others are not
+	sigOfZoneMD, _ := RRsetFromString("example. 86400 IN RRSIG ZONEMD 13 1 86400 20181231000000 20180319000000 12345 example. AQID")
+	Equal(t, VerifyZoneMD(origin, append(rrsets, sigOfZoneMD)), nil)
+	sigOfSOA, _ := RRsetFromString("example. 86400 IN RRSIG SOA 13 1 86400 20181231000000 20180319000000 12345 example. AQID")
+	Equal(t, VerifyZoneMD(origin, append(rrsets, sigOfSOA)), ErrZoneMDDigestMismatch)
+
+	changed, _ := RRsetFromString("ns1.example. 3600 IN A 203.0.113.64")
+	Equal(t, VerifyZoneMD(origin, []*RRset{rrsets[0], rrsets[1], rrsets[2], changed, rrsets[4]}), ErrZoneMDDigestMismatch)
+	ttlChanged, _ := RRsetFromString("ns1.example. 300 IN A 203.0.113.63")
+	Equal(t, VerifyZoneMD(origin, []*RRset{rrsets[0], rrsets[1], rrsets[2], ttlChanged, rrsets[4]}), ErrZoneMDDigestMismatch)
+
+	soa, _ := RRsetFromString("example. 86400 IN SOA ns1.example. admin.example. 2018031901 1800 900 604800 86400")
+	Equal(t, VerifyZoneMD(origin, []*RRset{soa, rrsets[1], rrsets[2], rrsets[3], rrsets[4]}), ErrZoneMDSerialMismatch)
+	Equal(t, VerifyZoneMD(origin, rrsets[:2]), ErrZoneMDNotFound)
+	Equal(t, VerifyZoneMD(origin, rrsets[1:]), ErrZoneSOANotFound)
+
+	unsupported, _ := RRsetFromString("example. 86400 IN ZONEMD 2018031900 1 240 c68090d90a7aed716bc459f9340e3d7c")
+	Equal(t, VerifyZoneMD(origin, []*RRset{rrsets[0], rrsets[1], unsupported, rrsets[3], rrsets[4]}), ErrUnsupportedZoneMD)
+	_, err = ZoneDigest(origin, rrsets, 240)
+	Equal(t, err, ErrUnsupportedZoneMD)
+
+	zonemd, err = NewZoneMD(origin, rrsets, ZONEMD_HASH_SHA512)
+	Assert(t, err == nil, "create zonemd failed:%v", err)
+	Equal(t, len(zonemd.Digest), 64)
+	published, _ := RRsetFromString("example. 86400 IN ZONEMD " + zonemd.String())
+	Equal(t, VerifyZoneMD(origin, []*RRset{rrsets[0], rrsets[1], published, rrsets[3], rrsets[4]}), nil)
+}